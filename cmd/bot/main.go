@@ -2,38 +2,88 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"first.fm/internal/bot"
+	"first.fm/internal/config"
 	"first.fm/internal/logger"
 	"first.fm/internal/persistence/sqlc"
+	"first.fm/internal/server"
 )
 
 func main() {
-	token := os.Getenv("DISCORD_TOKEN")
-	lastfmKey := os.Getenv("LASTFM_API_KEY")
-
-	if token == "" || lastfmKey == "" {
-		panic("DISCORD_TOKEN and LASTFM_API_KEY must be set")
+	cfg, err := config.Load("")
+	if err != nil {
+		logger.Fatalf("invalid configuration:\n%v", err)
 	}
+	cfg.Log.Apply(logger.Default())
 
-	q, db, err := sqlc.Start(context.Background(), "database.db")
+	q, db, err := sqlc.Start(context.Background(), cfg.Database.Path)
 	if err != nil {
 		logger.Fatalf("%v", err)
 	}
-	defer db.Close()
 
-	bot, err := bot.New(token, lastfmKey, q)
+	bot, err := bot.New(cfg, db, q)
 	if err != nil {
+		closeDB(q, db)
 		logger.Fatalf("%v", err)
 	}
+	if err := bot.Registry.Use(modulesFor(cfg)...); err != nil {
+		bot.Close()
+		closeDB(q, db)
+		logger.Fatalf("failed to register commands: %v", err)
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer cancel()
+	stopHTTP := serveHTTP(cfg.HTTP, bot, db)
+	err = bot.Run(ctx)
+	cancel()
+	stopHTTP()
+
+	bot.Close()
+	closeDB(q, db)
 
-	if err = bot.Run(ctx); err != nil {
+	if err != nil {
 		logger.Fatalf("%v", err)
 	}
+	logger.Info("shut down cleanly")
+}
+
+// closeDB closes the prepared statements and the database.
+func closeDB(q *sqlc.Queries, db *sql.DB) {
+	if err := q.Close(); err != nil {
+		logger.Warnw("failed to close queries", logger.F{"err": err.Error()})
+	}
+	if err := db.Close(); err != nil {
+		logger.Warnw("failed to close database", logger.F{"err": err.Error()})
+	}
+}
+
+// serveHTTP starts the health and metrics server when it is configured. The
+// returned function stops it; it keeps serving while the bot drains.
+func serveHTTP(cfg config.HTTP, b *bot.Bot, db *sql.DB) func() {
+	if cfg.Addr == "" {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	srv := server.New(cfg.Addr, map[string]server.Check{
+		"gateway":  b.GatewayReady,
+		"database": db.PingContext,
+	})
+	go func() {
+		defer close(done)
+		if err := srv.Run(ctx); err != nil {
+			logger.Errorw("http server failed", logger.F{"err": err.Error()})
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
 }
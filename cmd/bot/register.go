@@ -1,8 +1,48 @@
 package main
 
 import (
-	_ "first.fm/internal/commands/fm"
-	_ "first.fm/internal/commands/profile"
-	_ "first.fm/internal/commands/register"
-	_ "first.fm/internal/commands/stats"
+	"slices"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/commands/command"
+	"first.fm/internal/commands/crashes"
+	"first.fm/internal/commands/fm"
+	"first.fm/internal/commands/leaderboard"
+	"first.fm/internal/commands/lookup"
+	"first.fm/internal/commands/plays"
+	"first.fm/internal/commands/prefix"
+	"first.fm/internal/commands/presence"
+	"first.fm/internal/commands/profile"
+	"first.fm/internal/commands/recent"
+	"first.fm/internal/commands/register"
+	"first.fm/internal/commands/stats"
+	"first.fm/internal/commands/top"
+	"first.fm/internal/commands/unregister"
+	"first.fm/internal/config"
 )
+
+// modules are the commands the bot serves.
+var modules = []bot.Module{
+	command.Module,
+	crashes.Module,
+	fm.Module,
+	leaderboard.Module,
+	lookup.Module,
+	plays.Module,
+	presence.Module,
+	profile.Module,
+	recent.Module,
+	register.Module,
+	stats.Module,
+	top.Module,
+	unregister.Module,
+}
+
+// modulesFor returns the modules served with cfg, adding /prefix when servers
+// may run commands by message.
+func modulesFor(cfg config.Config) []bot.Module {
+	if !cfg.Commands.MessagePrefixes {
+		return modules
+	}
+	return append(slices.Clone(modules), prefix.Module)
+}
@@ -3,6 +3,7 @@ module first.fm
 go 1.25.0
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/disgoorg/disgo v0.19.0-rc.6.0.20250924005456-3274c76733fc
 	github.com/disgoorg/snowflake/v2 v2.0.3
 	github.com/mattn/go-sqlite3 v1.14.32
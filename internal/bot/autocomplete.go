@@ -0,0 +1,241 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"first.fm/internal/lastfm"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+)
+
+// autocompleteDeadline is how long a handler gets before an empty result is
+// sent. Discord drops autocomplete responses after 3 seconds.
+const autocompleteDeadline = 2500 * time.Millisecond
+
+// maxAutocompleteChoices is the maximum number of choices Discord accepts.
+const maxAutocompleteChoices = 25
+
+type AutocompleteContext struct {
+	*events.AutocompleteInteractionCreate
+	*Bot
+	Ctx context.Context
+	// Path is the routed command path, e.g. "/top/artists".
+	Path string
+}
+
+// AutocompleteHandler returns the choices for the focused option. Returning
+// more than 25 choices is fine, extra ones are dropped.
+type AutocompleteHandler func(*AutocompleteContext) ([]discord.AutocompleteChoice, error)
+
+// Focused returns the name and current value of the focused option.
+func (ctx *AutocompleteContext) Focused() (name, value string) {
+	focused := ctx.Data.Focused()
+	value, _ = ctx.Data.OptString(focused.Name)
+	return focused.Name, strings.TrimSpace(value)
+}
+
+// WithAutocomplete provides suggestions for the given option of a command
+// registered with Registry.Register. The option is marked as autocompleted in
+// the registration metadata.
+func WithAutocomplete(option string, handler AutocompleteHandler) CommandOption {
+	return func(c *commandConfig) {
+		if c.autocomplete == nil {
+			c.autocomplete = map[string]AutocompleteHandler{}
+		}
+		c.autocomplete[option] = handler
+	}
+}
+
+func AutocompleteDispatcher(bot *Bot) func(*events.AutocompleteInteractionCreate) {
+	return func(event *events.AutocompleteInteractionCreate) {
+		if !bot.inflight.begin() {
+			_ = event.AutocompleteResult(nil)
+			return
+		}
+
+		path := event.Data.CommandPath()
+		focused := event.Data.Focused().Name
+
+		handler, ok := bot.Registry.autocompleter(path, focused)
+		if !ok {
+			bot.inflight.end()
+			_ = event.AutocompleteResult(nil)
+			return
+		}
+
+		reqCtx, req, cancelReq := bot.newRequest(event.AutocompleteInteraction, path)
+		defer cancelReq()
+
+		if !bot.commandEnabled(reqCtx, event.GuildID(), event.Data.CommandName) {
+			bot.inflight.end()
+			_ = event.AutocompleteResult(nil)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(reqCtx, autocompleteDeadline)
+		defer cancel()
+
+		type result struct {
+			choices []discord.AutocompleteChoice
+			err     error
+		}
+		// the handler keeps running after a timeout, so it is the one that
+		// leaves the in-flight count for shutdown to wait on
+		done := make(chan result, 1)
+		go func() {
+			defer bot.inflight.end()
+			defer func() {
+				if r := recover(); r != nil {
+					done <- result{err: bot.reportPanic(r, path, event.AutocompleteInteraction, req)}
+				}
+			}()
+			choices, err := handler(&AutocompleteContext{
+				AutocompleteInteractionCreate: event,
+				Bot:                           bot,
+				Ctx:                           ctx,
+				Path:                          path,
+			})
+			done <- result{choices, err}
+		}()
+
+		var choices []discord.AutocompleteChoice
+		select {
+		case res := <-done:
+			if res.err != nil {
+				req.Log.Warnw("autocomplete failed", logger.F{"option": focused, "err": res.err.Error()})
+			}
+			choices = res.choices
+		case <-ctx.Done():
+			req.Log.Warnw("autocomplete timed out", logger.F{"option": focused})
+		}
+
+		if len(choices) > maxAutocompleteChoices {
+			choices = choices[:maxAutocompleteChoices]
+		}
+		if err := event.AutocompleteResult(choices); err != nil {
+			req.Log.Warnw("failed to send autocomplete result", logger.F{"err": err.Error()})
+		}
+	}
+}
+
+// ArtistAutocomplete suggests from the user's top artists while the option is
+// empty and from Last.fm search otherwise.
+func ArtistAutocomplete(ctx *AutocompleteContext) ([]discord.AutocompleteChoice, error) {
+	return searchAutocomplete(ctx, lastfm.SearchKindArtist)
+}
+
+// AlbumAutocomplete suggests from the user's top albums while the option is
+// empty and from Last.fm search otherwise.
+func AlbumAutocomplete(ctx *AutocompleteContext) ([]discord.AutocompleteChoice, error) {
+	return searchAutocomplete(ctx, lastfm.SearchKindAlbum)
+}
+
+// TrackAutocomplete suggests from the user's top tracks while the option is
+// empty and from Last.fm search otherwise.
+func TrackAutocomplete(ctx *AutocompleteContext) ([]discord.AutocompleteChoice, error) {
+	return searchAutocomplete(ctx, lastfm.SearchKindTrack)
+}
+
+func searchAutocomplete(ctx *AutocompleteContext, kind lastfm.SearchKind) ([]discord.AutocompleteChoice, error) {
+	_, query := ctx.Focused()
+
+	var (
+		hits []lastfm.SearchHit
+		err  error
+	)
+	if query == "" {
+		hits, err = ctx.topHits(kind)
+	} else {
+		var res *lastfm.SearchResults
+		res, err = ctx.LastFM.Search.All(ctx.Ctx, lastfm.SearchParams{
+			Query: query,
+			Kinds: []lastfm.SearchKind{kind},
+			Limit: maxAutocompleteChoices,
+		})
+		if res != nil {
+			hits = res.Hits
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	choices := make([]discord.AutocompleteChoice, 0, len(hits))
+	for _, h := range hits {
+		// a truncated value would submit a name that doesn't exist
+		value, err := h.Value()
+		if err != nil {
+			continue
+		}
+		choices = append(choices, discord.AutocompleteChoiceString{Name: h.Label(), Value: value})
+	}
+	return choices, nil
+}
+
+// topHits returns the invoking user's top entries of the given kind, or none
+// when they aren't registered.
+func (ctx *AutocompleteContext) topHits(kind lastfm.SearchKind) ([]lastfm.SearchHit, error) {
+	user, err := ctx.Queries.GetUserByID(ctx.Ctx, ctx.User().ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []lastfm.SearchHit
+	switch kind {
+	case lastfm.SearchKindArtist:
+		top, err := ctx.LastFM.User.TopArtists(ctx.Ctx, lastfm.UserTopArtistsParams{User: user.LastfmUsername, Limit: maxAutocompleteChoices})
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range top.Artists {
+			hits = append(hits, lastfm.SearchHit{Kind: kind, Name: a.Name, URL: a.URL, MBID: a.MBID})
+		}
+	case lastfm.SearchKindAlbum:
+		top, err := ctx.LastFM.User.TopAlbums(ctx.Ctx, lastfm.UserTopAlbumsParams{User: user.LastfmUsername, Limit: maxAutocompleteChoices})
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range top.Albums {
+			hits = append(hits, lastfm.SearchHit{Kind: kind, Name: a.Title, Artist: a.Artist.Name, URL: a.URL, MBID: a.MBID})
+		}
+	case lastfm.SearchKindTrack:
+		top, err := ctx.LastFM.User.TopTracks(ctx.Ctx, lastfm.UserTopTracksParams{User: user.LastfmUsername, Limit: maxAutocompleteChoices})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range top.Tracks {
+			hits = append(hits, lastfm.SearchHit{Kind: kind, Name: t.Title, Artist: t.Artist.Name, URL: t.URL, MBID: t.MBID})
+		}
+	}
+	return hits, nil
+}
+
+// markAutocomplete sets the autocomplete flag on the named options.
+func markAutocomplete(options []discord.ApplicationCommandOption, names map[string]AutocompleteHandler) []discord.ApplicationCommandOption {
+	marked := make([]discord.ApplicationCommandOption, len(options))
+	for i, option := range options {
+		if _, ok := names[option.OptionName()]; ok {
+			switch o := option.(type) {
+			case discord.ApplicationCommandOptionString:
+				o.Autocomplete = true
+				option = o
+			case discord.ApplicationCommandOptionInt:
+				o.Autocomplete = true
+				option = o
+			case discord.ApplicationCommandOptionFloat:
+				o.Autocomplete = true
+				option = o
+			}
+		}
+		marked[i] = option
+	}
+	return marked
+}
@@ -2,11 +2,12 @@ package bot
 
 import (
 	"context"
+	"database/sql"
 	"log/slog"
-	"os"
-	"os/signal"
-	"syscall"
+	"sync/atomic"
+	"time"
 
+	"first.fm/internal/config"
 	"first.fm/internal/lastfm/api"
 	"first.fm/internal/logger"
 	"first.fm/internal/persistence/sqlc"
@@ -14,6 +15,7 @@ import (
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/gateway"
 	"github.com/disgoorg/snowflake/v2"
+	"golang.org/x/time/rate"
 )
 
 type Bot struct {
@@ -21,56 +23,157 @@ type Bot struct {
 	LastFM  *api.Client
 	Logger  *logger.Logger
 	Queries *sqlc.Queries
+	// DB is the database Queries runs on, for transactions.
+	DB *sql.DB
+	// Registry holds the commands the bot serves.
+	Registry *Registry
+	// Crashes keeps the most recent handler panics for diagnostics.
+	Crashes *CrashLog
+	// Owners may use owner-only commands.
+	Owners []snowflake.ID
+
+	// Presence configures the status shown under the bot's name.
+	Presence PresenceConfig
+	// Sharding decides which gateway shards the bot connects.
+	Sharding ShardConfig
+	// Sync configures how commands are registered with Discord.
+	Sync SyncConfig
+	// MessagePrefixes lets guilds run the commands by message with the
+	// prefix they set.
+	MessagePrefixes bool
+	// DrainTimeout is how long Run waits for in-flight interactions on
+	// shutdown before cancelling them.
+	DrainTimeout time.Duration
+
+	// ctx is the context request contexts derive from. It outlives the
+	// context passed to Run until draining is over.
+	ctx      context.Context
+	inflight inflight
+	members  memberIndex
+	prefixes prefixes
+	// status is the custom status last set on every shard.
+	status atomic.Pointer[string]
 }
 
-func New(token, key string, q *sqlc.Queries) (*Bot, error) {
+// New returns a bot configured by cfg, which must be valid, storing its data
+// in db through q.
+func New(cfg config.Config, db *sql.DB, q *sqlc.Queries) (*Bot, error) {
 	log := logger.New()
+	cfg.Log.Apply(log)
+	shards := ShardConfig{Count: cfg.Discord.Shards, IDs: cfg.Discord.ShardIDs}
+	intents := []gateway.Intents{
+		gateway.IntentGuildMembers,
+		gateway.IntentGuilds,
+	}
+	if cfg.Commands.MessagePrefixes {
+		intents = append(intents, gateway.IntentGuildMessages, gateway.IntentMessageContent)
+	}
 	client, err := disgo.New(
-		token,
+		cfg.Discord.Token,
 		bot.WithLogger(slog.New(logger.NewSlogHandler(log))),
-		bot.WithGatewayConfigOpts(
+		bot.WithShardManagerConfigOpts(shards.options(
 			gateway.WithCompress(true),
 			gateway.WithAutoReconnect(true),
-			gateway.WithIntents(
-				gateway.IntentGuildMembers,
-				gateway.IntentGuilds,
-			),
-		),
-		bot.WithEventListenerFunc(onReady),
+			gateway.WithIntents(intents...),
+		)...),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	lastfmClient := api.NewClient(key)
-	return &Bot{
-		Client:  client,
-		LastFM:  lastfmClient,
-		Logger:  log,
-		Queries: q,
-	}, nil
+	a := api.NewWithTimeout(cfg.LastFM.APIKey, int(cfg.LastFM.Timeout/time.Second))
+	a.SetRateLimit(rate.Limit(cfg.LastFM.RequestsPerSecond), cfg.LastFM.Burst)
+	lastfmClient := api.NewClientFromAPI(a)
+	resizeCaches(cfg.Cache, lastfmClient)
+	registry := NewRegistry()
+	registry.ResizeCaches(cfg.Cache.Cooldowns, cfg.Cache.Paginators)
+
+	b := &Bot{
+		Client:   client,
+		LastFM:   lastfmClient,
+		Logger:   log,
+		DB:       db,
+		Queries:  q,
+		Registry: registry,
+		Crashes:  NewCrashLog(50),
+		Owners:   cfg.Discord.Owners,
+		Presence: PresenceConfig{
+			Default:   cfg.Discord.Presence,
+			Templates: cfg.Presence.Templates,
+			Interval:  cfg.Presence.Interval,
+			Sample:    cfg.Presence.Sample,
+		},
+		Sharding: shards,
+
+		Sync: SyncConfig{
+			Mode:     RegistrationMode(cfg.Commands.Mode),
+			Guilds:   cfg.Commands.Guilds,
+			DevGuild: cfg.Commands.DevGuild,
+			DryRun:   cfg.Commands.DryRun,
+		},
+		MessagePrefixes: cfg.Commands.MessagePrefixes,
+		DrainTimeout:    cfg.Discord.DrainTimeout,
+	}
+	b.registerMetrics()
+	return b, nil
 }
 
+// resizeCaches applies the configured sizes of the Last.fm caches.
+func resizeCaches(cfg config.Cache, client *api.Client) {
+	client.User.InfoCache.Resize(cfg.LastFMUsers)
+	client.Search.ResultCache.Resize(cfg.Searches)
+	client.Chart.ArtistsCache.Resize(cfg.Charts)
+	client.Chart.TagsCache.Resize(cfg.Charts)
+	client.Chart.TracksCache.Resize(cfg.Charts)
+}
+
+// Run connects the configured shards and handles interactions until ctx is
+// done, keeping the guild member index up to date in the background. It then
+// stops accepting interactions, waits up to DrainTimeout for the running ones
+// and closes the shards. Commands are synced by the process running shard 0.
 func (b *Bot) Run(ctx context.Context) error {
-	b.Client.AddEventListeners(bot.NewListenerFunc(Dispatcher(b)))
+	var cancel context.CancelFunc
+	b.ctx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
 
-	if err := b.Client.OpenGateway(ctx); err != nil {
-		return err
+	b.Client.AddEventListeners(
+		bot.NewListenerFunc(b.onReady),
+		bot.NewListenerFunc(Dispatcher(b)),
+		bot.NewListenerFunc(AutocompleteDispatcher(b)),
+		bot.NewListenerFunc(ComponentDispatcher(b)),
+		bot.NewListenerFunc(ModalDispatcher(b)),
+	)
+	b.Client.AddEventListeners(b.memberListeners()...)
+	b.Client.AddEventListeners(b.cleanupListeners()...)
+	if b.MessagePrefixes {
+		if err := b.loadPrefixes(ctx); err != nil {
+			return err
+		}
+		b.Client.AddEventListeners(bot.NewListenerFunc(b.onGuildMessage))
 	}
-	defer b.Client.Close(ctx)
+	go b.indexMembers(b.ctx)
+	go b.purgeLimits(b.ctx)
 
-	if _, err := b.Client.Rest.SetGuildCommands(b.Client.ApplicationID, snowflake.GetEnv("GUILD_ID"), Commands()); err != nil {
+	if err := b.Client.OpenShardManager(ctx); err != nil {
 		return err
 	}
-	logger.Info("registered discord commands")
-
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		b.Client.Close(closeCtx)
+	}()
 
-	select {
-	case <-ctx.Done():
-	case <-stop:
+	b.Registry.checkLocalizations()
+	if b.Sharding.runs(0) {
+		if err := b.SyncCommands(); err != nil {
+			return err
+		}
+		logger.Infow("synced discord commands", logger.F{"mode": b.Sync.Mode, "dry_run": b.Sync.DryRun})
 	}
 
+	go b.rotatePresence(ctx)
+
+	<-ctx.Done()
+	b.shutdown(cancel)
 	return nil
 }
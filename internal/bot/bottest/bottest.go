@@ -0,0 +1,201 @@
+// Package bottest runs commands without Discord. A Harness dispatches fake
+// interactions through the same dispatchers the gateway uses, so limits,
+// middleware and error replies run as they do in production, and records the
+// responses as component trees.
+//
+// A test registers the modules under test, links the invoking user and stubs
+// the Last.fm methods it calls:
+//
+//	func TestFM(t *testing.T) {
+//		h := bottest.New(t, fm.Module)
+//		h.Link(h.User, "rj")
+//		h.LastFM.Handle(api.UserGetInfoMethod, `<user><name>rj</name></user>`)
+//		h.LastFM.Handle(api.UserGetRecentTracksMethod, recentTracks)
+//
+//		res := h.Slash("fm").Run()
+//		bottest.Golden(t, "fm", res.String())
+//	}
+package bottest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/lastfm/api"
+	"first.fm/internal/logger"
+	"first.fm/internal/persistence/sqlc"
+	disgobot "github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"golang.org/x/time/rate"
+)
+
+// ApplicationID is the application ID of the fake client.
+const ApplicationID snowflake.ID = 1000
+
+// The user, channel and guild interactions come from by default. Cooldowns,
+// concurrency slots and paginators live on the Registry and every harness
+// has its own, so harnesses share these IDs and golden files don't depend on
+// which tests ran before.
+const (
+	UserID    snowflake.ID = 2001
+	ChannelID snowflake.ID = 2002
+	GuildID   snowflake.ID = 2003
+)
+
+// ids hands out the snowflakes of commands, messages and in-memory databases,
+// which must be unique within the test binary since databases are shared by
+// name.
+var ids atomic.Uint64
+
+func nextID() snowflake.ID {
+	return snowflake.ID(10000 + ids.Add(1))
+}
+
+type Harness struct {
+	TB      testing.TB
+	Bot     *bot.Bot
+	LastFM  *FakeLastFM
+	Queries *sqlc.Queries
+
+	// User, Channel and Guild are where interactions come from unless the
+	// builder says otherwise.
+	User    snowflake.ID
+	Channel snowflake.ID
+	Guild   snowflake.ID
+
+	rest *fakeRest
+}
+
+// New returns a harness serving modules, backed by an in-memory database and
+// a fake Last.fm API. Everything is closed when the test finishes.
+func New(tb testing.TB, modules ...bot.Module) *Harness {
+	tb.Helper()
+
+	db, queries := openDB(tb)
+
+	lastFM := NewFakeLastFM()
+	a := api.New("bottest")
+	a.Client = lastFM
+	a.SetRetries(0)
+	a.SetRateLimit(rate.Inf, 1)
+	client := api.NewClientFromAPI(a)
+	tb.Cleanup(client.Close)
+
+	rest := newFakeRest()
+	b := &bot.Bot{
+		Client: &disgobot.Client{
+			ApplicationID: ApplicationID,
+			Rest:          rest,
+		},
+		LastFM:   client,
+		Logger:   logger.New(),
+		DB:       db,
+		Queries:  queries,
+		Registry: bot.NewRegistry(),
+		Crashes:  bot.NewCrashLog(50),
+	}
+	tb.Cleanup(b.Registry.Close)
+	if err := b.Registry.Use(modules...); err != nil {
+		tb.Fatalf("bottest: %v", err)
+	}
+
+	return &Harness{
+		TB:      tb,
+		Bot:     b,
+		LastFM:  lastFM,
+		Queries: queries,
+		User:    UserID,
+		Channel: ChannelID,
+		Guild:   GuildID,
+		rest:    rest,
+	}
+}
+
+// openDB creates a private in-memory database with the bot's schema. A
+// second connection keeps it alive while the pool recycles its connections.
+func openDB(tb testing.TB) (*sql.DB, *sqlc.Queries) {
+	tb.Helper()
+
+	dsn := fmt.Sprintf("file:bottest-%d?mode=memory&cache=shared", nextID())
+	keepAlive, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		tb.Fatalf("bottest: open database: %v", err)
+	}
+	if err := keepAlive.Ping(); err != nil {
+		tb.Fatalf("bottest: open database: %v", err)
+	}
+
+	queries, db, err := sqlc.Start(context.Background(), dsn)
+	if err != nil {
+		keepAlive.Close()
+		tb.Fatalf("bottest: %v", err)
+	}
+	tb.Cleanup(func() {
+		queries.Close()
+		db.Close()
+		keepAlive.Close()
+	})
+	return db, queries
+}
+
+// Link registers username as the Last.fm account of user.
+func (h *Harness) Link(user snowflake.ID, username string) {
+	h.TB.Helper()
+	err := h.Queries.UpsertUser(context.Background(), sqlc.UpsertUserParams{
+		UserID:         user,
+		LastfmUsername: username,
+	})
+	if err != nil {
+		h.TB.Fatalf("bottest: link %s: %v", username, err)
+	}
+}
+
+// Member adds a member with the given username to the harness guild and to
+// the member index, like a member the gateway delivered.
+func (h *Harness) Member(user snowflake.ID, username string) {
+	h.UnindexedMember(user, username)
+	err := h.Queries.AddGuildMember(context.Background(), sqlc.AddGuildMemberParams{
+		GuildID:  h.Guild,
+		UserID:   user,
+		Username: strings.ToLower(username),
+	})
+	if err != nil {
+		h.TB.Fatalf("bottest: index member %s: %v", username, err)
+	}
+}
+
+// UnindexedMember adds a member with the given username to the harness
+// guild without indexing it, so it is only found by searching Discord.
+func (h *Harness) UnindexedMember(user snowflake.ID, username string) {
+	h.rest.addMember(discord.Member{
+		User:    discord.User{ID: user, Username: username},
+		GuildID: h.Guild,
+	})
+}
+
+// MemberSearches returns how many times Discord was searched for members.
+func (h *Harness) MemberSearches() int {
+	return h.rest.memberSearches()
+}
+
+// DeleteAccount makes Discord report user as unknown, like a deleted account.
+func (h *Harness) DeleteAccount(user snowflake.ID) {
+	h.rest.deleteUser(user)
+}
+
+// Owner makes user a bot owner.
+func (h *Harness) Owner(user snowflake.ID) {
+	h.Bot.Owners = append(h.Bot.Owners, user)
+}
+
+// newToken returns a unique interaction token.
+func newToken() string {
+	return fmt.Sprintf("token-%d-%d", nextID(), time.Now().UnixNano())
+}
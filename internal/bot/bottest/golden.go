@@ -0,0 +1,35 @@
+package bottest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that makes Golden rewrite golden
+// files instead of comparing against them, e.g. UPDATE_GOLDEN=1 go test ./...
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// Golden compares got with testdata/<name>.golden of the package under test.
+func Golden(tb testing.TB, name, got string) {
+	tb.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatalf("bottest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			tb.Fatalf("bottest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("bottest: %v (run with %s=1 to create it)", err, UpdateEnv)
+	}
+	if got != string(want) {
+		tb.Errorf("bottest: %s differs from golden file\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
@@ -0,0 +1,295 @@
+package bottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"first.fm/internal/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Interaction builds a fake interaction. It is sent by Run.
+type Interaction struct {
+	h *Harness
+
+	typ     discord.InteractionType
+	user    snowflake.ID
+	channel snowflake.ID
+	guild   snowflake.ID
+	locale  discord.Locale
+	perms   discord.Permissions
+	data    map[string]any
+	// leaf is the innermost (sub)command, which options are added to.
+	leaf     map[string]any
+	resolved map[string]map[string]any
+}
+
+func (h *Harness) interaction(typ discord.InteractionType, data map[string]any) *Interaction {
+	return &Interaction{
+		h:        h,
+		typ:      typ,
+		user:     h.User,
+		channel:  h.Channel,
+		guild:    h.Guild,
+		locale:   discord.LocaleEnglishUS,
+		data:     data,
+		leaf:     data,
+		resolved: map[string]map[string]any{},
+	}
+}
+
+// Slash invokes a slash command. Subcommands are separated by spaces, e.g.
+// "top artists".
+func (h *Harness) Slash(path string) *Interaction {
+	parts := strings.Fields(path)
+	i := h.interaction(discord.InteractionTypeApplicationCommand, map[string]any{
+		"id":   nextID(),
+		"name": parts[0],
+		"type": discord.ApplicationCommandTypeSlash,
+	})
+
+	parent := i.data
+	for n, name := range parts[1:] {
+		typ := discord.ApplicationCommandOptionTypeSubCommandGroup
+		if n == len(parts)-2 {
+			typ = discord.ApplicationCommandOptionTypeSubCommand
+		}
+		sub := map[string]any{"name": name, "type": typ}
+		parent["options"] = []map[string]any{sub}
+		parent = sub
+	}
+	i.leaf = parent
+	return i
+}
+
+// Autocomplete asks for the choices of an option of a slash command, which
+// is set with Focus. Subcommands are separated by spaces, as with Slash.
+func (h *Harness) Autocomplete(path string) *Interaction {
+	i := h.Slash(path)
+	i.typ = discord.InteractionTypeAutocomplete
+	return i
+}
+
+// UserCommand invokes a user context menu command on target.
+func (h *Harness) UserCommand(name string, target snowflake.ID) *Interaction {
+	i := h.interaction(discord.InteractionTypeApplicationCommand, map[string]any{
+		"id":        nextID(),
+		"name":      name,
+		"type":      discord.ApplicationCommandTypeUser,
+		"target_id": target,
+	})
+	i.resolve(target)
+	return i
+}
+
+// MessageCommand invokes a message context menu command on a message with
+// the given content.
+func (h *Harness) MessageCommand(name, content string) *Interaction {
+	message := nextID()
+	i := h.interaction(discord.InteractionTypeApplicationCommand, map[string]any{
+		"id":        nextID(),
+		"name":      name,
+		"type":      discord.ApplicationCommandTypeMessage,
+		"target_id": message,
+	})
+	i.resolved["messages"] = map[string]any{
+		message.String(): i.message(message, content),
+	}
+	return i
+}
+
+// Click presses the button with the raw custom ID, e.g. the CustomID of a
+// node from an earlier response.
+func (h *Harness) Click(customID string) *Interaction {
+	return h.interaction(discord.InteractionTypeComponent, map[string]any{
+		"custom_id":      customID,
+		"component_type": discord.ComponentTypeButton,
+	})
+}
+
+// As sends the interaction as user.
+func (i *Interaction) As(user snowflake.ID) *Interaction {
+	i.user = user
+	return i
+}
+
+// InChannel sends the interaction from channel.
+func (i *Interaction) InChannel(channel snowflake.ID) *Interaction {
+	i.channel = channel
+	return i
+}
+
+// InDM sends the interaction from a direct message instead of the guild.
+func (i *Interaction) InDM() *Interaction {
+	i.guild = 0
+	return i
+}
+
+// Locale sets the locale of the invoking user.
+func (i *Interaction) Locale(locale discord.Locale) *Interaction {
+	i.locale = locale
+	return i
+}
+
+// Permissions sets the permissions of the invoking member.
+func (i *Interaction) Permissions(perms discord.Permissions) *Interaction {
+	i.perms = perms
+	return i
+}
+
+// Option sets an option of the invoked (sub)command. Strings, ints, floats
+// and bools map to the matching option types and snowflakes to user options.
+func (i *Interaction) Option(name string, value any) *Interaction {
+	option := map[string]any{"name": name, "value": value}
+	switch v := value.(type) {
+	case string:
+		option["type"] = discord.ApplicationCommandOptionTypeString
+	case int:
+		option["type"] = discord.ApplicationCommandOptionTypeInt
+	case float64:
+		option["type"] = discord.ApplicationCommandOptionTypeFloat
+	case bool:
+		option["type"] = discord.ApplicationCommandOptionTypeBool
+	case snowflake.ID:
+		option["type"] = discord.ApplicationCommandOptionTypeUser
+		i.resolve(v)
+	default:
+		panic(fmt.Sprintf("bottest: unsupported option type %T", value))
+	}
+
+	options, _ := i.leaf["options"].([]map[string]any)
+	i.leaf["options"] = append(options, option)
+	return i
+}
+
+// Focus sets the option being typed in, for Autocomplete.
+func (i *Interaction) Focus(name, value string) *Interaction {
+	options, _ := i.leaf["options"].([]map[string]any)
+	i.leaf["options"] = append(options, map[string]any{
+		"name":    name,
+		"value":   value,
+		"type":    discord.ApplicationCommandOptionTypeString,
+		"focused": true,
+	})
+	return i
+}
+
+func (i *Interaction) resolve(user snowflake.ID) {
+	if i.resolved["users"] == nil {
+		i.resolved["users"] = map[string]any{}
+	}
+	i.resolved["users"][user.String()] = userJSON(user)
+}
+
+func userJSON(id snowflake.ID) map[string]any {
+	return map[string]any{
+		"id":            id,
+		"username":      fmt.Sprintf("user%d", id),
+		"discriminator": "0",
+	}
+}
+
+func (i *Interaction) message(id snowflake.ID, content string) map[string]any {
+	return map[string]any{
+		"id":         id,
+		"channel_id": i.channel,
+		"author":     userJSON(ApplicationID),
+		"content":    content,
+		"timestamp":  time.Now().Format(time.RFC3339),
+		"type":       discord.MessageTypeDefault,
+	}
+}
+
+// raw returns the interaction as Discord would send it.
+func (i *Interaction) raw(token string) []byte {
+	data := i.data
+	if len(i.resolved) > 0 {
+		data["resolved"] = i.resolved
+	}
+
+	channelType := discord.ChannelTypeDM
+	if i.guild != 0 {
+		channelType = discord.ChannelTypeGuildText
+	}
+	raw := map[string]any{
+		"id":             snowflake.New(time.Now()),
+		"application_id": ApplicationID,
+		"type":           i.typ,
+		"token":          token,
+		"version":        1,
+		"locale":         i.locale,
+		"channel":        map[string]any{"id": i.channel, "type": channelType},
+		"channel_id":     i.channel,
+		"data":           data,
+	}
+	if i.guild != 0 {
+		raw["guild_id"] = i.guild
+		raw["guild_locale"] = discord.LocaleEnglishUS
+		raw["member"] = map[string]any{
+			"user":        userJSON(i.user),
+			"roles":       []string{},
+			"joined_at":   time.Now().Format(time.RFC3339),
+			"permissions": i.perms,
+		}
+	} else {
+		raw["user"] = userJSON(i.user)
+	}
+	if i.typ == discord.InteractionTypeComponent {
+		raw["message"] = i.message(nextID(), "")
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		panic(fmt.Sprintf("bottest: marshal interaction: %v", err))
+	}
+	return b
+}
+
+// Run dispatches the interaction and returns the responses sent until its
+// handler returned.
+func (i *Interaction) Run() *Result {
+	i.h.TB.Helper()
+
+	token := newToken()
+	result := i.h.rest.track(token)
+	generic := events.NewGenericEvent(i.h.Bot.Client, 0, 0)
+	respond := i.h.rest.respond(token)
+
+	switch i.typ {
+	case discord.InteractionTypeApplicationCommand:
+		var interaction discord.ApplicationCommandInteraction
+		if err := json.Unmarshal(i.raw(token), &interaction); err != nil {
+			i.h.TB.Fatalf("bottest: build interaction: %v", err)
+		}
+		bot.Dispatcher(i.h.Bot)(&events.ApplicationCommandInteractionCreate{
+			GenericEvent:                  generic,
+			ApplicationCommandInteraction: interaction,
+			Respond:                       respond,
+		})
+	case discord.InteractionTypeAutocomplete:
+		var interaction discord.AutocompleteInteraction
+		if err := json.Unmarshal(i.raw(token), &interaction); err != nil {
+			i.h.TB.Fatalf("bottest: build interaction: %v", err)
+		}
+		bot.AutocompleteDispatcher(i.h.Bot)(&events.AutocompleteInteractionCreate{
+			GenericEvent:            generic,
+			AutocompleteInteraction: interaction,
+			Respond:                 respond,
+		})
+	case discord.InteractionTypeComponent:
+		var interaction discord.ComponentInteraction
+		if err := json.Unmarshal(i.raw(token), &interaction); err != nil {
+			i.h.TB.Fatalf("bottest: build interaction: %v", err)
+		}
+		bot.ComponentDispatcher(i.h.Bot)(&events.ComponentInteractionCreate{
+			GenericEvent:         generic,
+			ComponentInteraction: interaction,
+			Respond:              respond,
+		})
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package bottest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"first.fm/internal/lastfm/api"
+)
+
+// LastFMHandlerFunc returns the XML inside the <lfm> element of a response.
+type LastFMHandlerFunc func(params url.Values) string
+
+// FakeLastFM is an api.HTTPClient answering Last.fm requests from canned
+// responses, routed by API method. Methods without a response fail with
+// Last.fm's "invalid parameters" error, which is what unknown users get.
+type FakeLastFM struct {
+	mu       sync.Mutex
+	handlers map[api.APIMethod]LastFMHandlerFunc
+	calls    []url.Values
+}
+
+func NewFakeLastFM() *FakeLastFM {
+	return &FakeLastFM{handlers: map[api.APIMethod]LastFMHandlerFunc{}}
+}
+
+// Handle answers every request for method with body.
+func (f *FakeLastFM) Handle(method api.APIMethod, body string) {
+	f.HandleFunc(method, func(url.Values) string { return body })
+}
+
+// HandleFunc answers the requests for method with the result of fn.
+func (f *FakeLastFM) HandleFunc(method api.APIMethod, fn LastFMHandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[method] = fn
+}
+
+// Calls returns the parameters of every request made so far.
+func (f *FakeLastFM) Calls() []url.Values {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]url.Values(nil), f.calls...)
+}
+
+// Do implements api.HTTPClient.
+func (f *FakeLastFM) Do(req *http.Request) (*http.Response, error) {
+	params := req.URL.Query()
+	if req.Body != nil {
+		raw, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		form, err := url.ParseQuery(string(raw))
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range form {
+			params[k] = v
+		}
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, params)
+	handler, ok := f.handlers[api.APIMethod(params.Get("method"))]
+	f.mu.Unlock()
+
+	body := fmt.Sprintf(`<lfm status="failed"><error code="%d">no fake response for %s</error></lfm>`, api.ErrInvalidParameters, params.Get("method"))
+	if ok {
+		body = `<lfm status="ok">` + handler(params) + `</lfm>`
+	}
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,324 @@
+package bottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"first.fm/internal/bot"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// ResponseKind is how a response reached Discord.
+type ResponseKind string
+
+const (
+	// Initial interaction responses.
+	KindCreate       ResponseKind = "create"
+	KindDefer        ResponseKind = "defer"
+	KindUpdate       ResponseKind = "update"
+	KindDeferUpdate  ResponseKind = "defer_update"
+	KindModal        ResponseKind = "modal"
+	KindAutocomplete ResponseKind = "autocomplete"
+
+	// Follow-up requests using the interaction token.
+	KindEdit           ResponseKind = "edit"
+	KindDelete         ResponseKind = "delete"
+	KindFollowup       ResponseKind = "followup"
+	KindEditFollowup   ResponseKind = "edit_followup"
+	KindDeleteFollowup ResponseKind = "delete_followup"
+)
+
+var responseKinds = map[discord.InteractionResponseType]ResponseKind{
+	discord.InteractionResponseTypeCreateMessage:         KindCreate,
+	discord.InteractionResponseTypeDeferredCreateMessage: KindDefer,
+	discord.InteractionResponseTypeUpdateMessage:         KindUpdate,
+	discord.InteractionResponseTypeDeferredUpdateMessage: KindDeferUpdate,
+	discord.InteractionResponseTypeModal:                 KindModal,
+	discord.InteractionResponseTypeAutocompleteResult:    KindAutocomplete,
+}
+
+// Response is a message, modal or autocomplete result sent for an
+// interaction.
+type Response struct {
+	Kind      ResponseKind
+	Ephemeral bool
+	// Content is the message content, or the title of a modal.
+	Content    string
+	Components []*Node
+	// Data is the payload as it was sent.
+	Data any
+}
+
+// Node is a component of a response. Embeds and autocomplete choices are
+// nodes too, so a whole response can be inspected as one tree.
+type Node struct {
+	// Type is the snake case component type, e.g. "text_display".
+	Type string
+	// Text is the content of text displays, the label of buttons and options
+	// and the placeholder of inputs.
+	Text     string
+	URL      string
+	CustomID string
+	Disabled bool
+	Children []*Node
+}
+
+var componentTypes = map[discord.ComponentType]string{
+	discord.ComponentTypeActionRow:             "action_row",
+	discord.ComponentTypeButton:                "button",
+	discord.ComponentTypeStringSelectMenu:      "string_select",
+	discord.ComponentTypeTextInput:             "text_input",
+	discord.ComponentTypeUserSelectMenu:        "user_select",
+	discord.ComponentTypeRoleSelectMenu:        "role_select",
+	discord.ComponentTypeMentionableSelectMenu: "mentionable_select",
+	discord.ComponentTypeChannelSelectMenu:     "channel_select",
+	discord.ComponentTypeSection:               "section",
+	discord.ComponentTypeTextDisplay:           "text_display",
+	discord.ComponentTypeThumbnail:             "thumbnail",
+	discord.ComponentTypeMediaGallery:          "media_gallery",
+	discord.ComponentTypeFile:                  "file",
+	discord.ComponentTypeSeparator:             "separator",
+	discord.ComponentTypeContainer:             "container",
+	discord.ComponentTypeLabel:                 "label",
+}
+
+// payload is the subset of message, modal and autocomplete payloads the
+// tree is built from.
+type payload struct {
+	Content    *string              `json:"content"`
+	Title      string               `json:"title"`
+	Flags      discord.MessageFlags `json:"flags"`
+	Components []map[string]any     `json:"components"`
+	Embeds     []discord.Embed      `json:"embeds"`
+	Choices    []struct {
+		Name string `json:"name"`
+	} `json:"choices"`
+}
+
+func newResponse(kind ResponseKind, data any) Response {
+	r := Response{Kind: kind, Data: data}
+	if data == nil {
+		return r
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		panic(fmt.Sprintf("bottest: marshal %s response: %v", kind, err))
+	}
+	var p payload
+	if err := json.Unmarshal(raw, &p); err != nil {
+		panic(fmt.Sprintf("bottest: unmarshal %s response: %v", kind, err))
+	}
+
+	r.Ephemeral = p.Flags.Has(discord.MessageFlagEphemeral)
+	if p.Content != nil {
+		r.Content = *p.Content
+	}
+	if p.Title != "" {
+		r.Content = p.Title
+	}
+	for _, c := range p.Components {
+		r.Components = append(r.Components, newNode(c))
+	}
+	for _, e := range p.Embeds {
+		r.Components = append(r.Components, embedNode(e))
+	}
+	for _, c := range p.Choices {
+		r.Components = append(r.Components, &Node{Type: "choice", Text: c.Name})
+	}
+	return r
+}
+
+func newNode(c map[string]any) *Node {
+	typ, _ := c["type"].(float64)
+	n := &Node{Type: componentTypes[discord.ComponentType(typ)]}
+	if n.Type == "" {
+		n.Type = fmt.Sprintf("component_%d", int(typ))
+	}
+
+	for _, key := range []string{"content", "label", "placeholder"} {
+		if s, ok := c[key].(string); ok && n.Text == "" {
+			n.Text = s
+		}
+	}
+	n.URL, _ = c["url"].(string)
+	if media, ok := c["media"].(map[string]any); ok {
+		n.URL, _ = media["url"].(string)
+	}
+	if file, ok := c["file"].(map[string]any); ok {
+		n.URL, _ = file["url"].(string)
+	}
+	n.CustomID, _ = c["custom_id"].(string)
+	n.Disabled, _ = c["disabled"].(bool)
+
+	for _, key := range []string{"components", "items", "options"} {
+		children, _ := c[key].([]any)
+		for _, child := range children {
+			m, ok := child.(map[string]any)
+			if !ok {
+				continue
+			}
+			switch key {
+			case "items":
+				media, _ := m["media"].(map[string]any)
+				url, _ := media["url"].(string)
+				description, _ := m["description"].(string)
+				n.Children = append(n.Children, &Node{Type: "media", Text: description, URL: url})
+			case "options":
+				n.Children = append(n.Children, &Node{Type: "option", Text: fmt.Sprint(m["label"])})
+			default:
+				n.Children = append(n.Children, newNode(m))
+			}
+		}
+	}
+	if child, ok := c["component"].(map[string]any); ok {
+		n.Children = append(n.Children, newNode(child))
+	}
+	if accessory, ok := c["accessory"].(map[string]any); ok {
+		n.Children = append(n.Children, newNode(accessory))
+	}
+	return n
+}
+
+func embedNode(e discord.Embed) *Node {
+	n := &Node{Type: "embed", Text: e.Title, URL: e.URL}
+	if e.Description != "" {
+		n.Children = append(n.Children, &Node{Type: "description", Text: e.Description})
+	}
+	for _, f := range e.Fields {
+		n.Children = append(n.Children, &Node{Type: "field", Text: f.Name + ": " + f.Value})
+	}
+	if e.Thumbnail != nil {
+		n.Children = append(n.Children, &Node{Type: "thumbnail", URL: e.Thumbnail.URL})
+	}
+	return n
+}
+
+// Find returns the nodes of the given type, depth first.
+func (r Response) Find(typ string) []*Node {
+	var found []*Node
+	var walk func([]*Node)
+	walk = func(nodes []*Node) {
+		for _, n := range nodes {
+			if n.Type == typ {
+				found = append(found, n)
+			}
+			walk(n.Children)
+		}
+	}
+	walk(r.Components)
+	return found
+}
+
+// Text returns the content and every text display of the response, one per
+// line.
+func (r Response) Text() string {
+	var lines []string
+	if r.Content != "" {
+		lines = append(lines, r.Content)
+	}
+	for _, n := range r.Find("text_display") {
+		lines = append(lines, n.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String renders the response as an indented tree. Custom IDs are reduced to
+// their namespace, action and state since owner and expiry change between
+// runs, and paginator IDs, which are derived from the interaction ID, are
+// replaced with "id". This keeps the output stable for golden files.
+func (r Response) String() string {
+	var b strings.Builder
+	b.WriteString(string(r.Kind))
+	if r.Ephemeral {
+		b.WriteString(" (ephemeral)")
+	}
+	b.WriteByte('\n')
+	if r.Content != "" {
+		writeLine(&b, 1, "content "+quote(r.Content))
+	}
+	for _, n := range r.Components {
+		writeNode(&b, 1, n)
+	}
+	return b.String()
+}
+
+func writeNode(b *strings.Builder, depth int, n *Node) {
+	line := n.Type
+	if n.Text != "" {
+		line += " " + quote(n.Text)
+	}
+	if n.URL != "" {
+		line += " " + n.URL
+	}
+	if n.CustomID != "" {
+		line += " [" + stableCustomID(n.CustomID) + "]"
+	}
+	if n.Disabled {
+		line += " (disabled)"
+	}
+	writeLine(b, depth, line)
+	for _, child := range n.Children {
+		writeNode(b, depth+1, child)
+	}
+}
+
+func writeLine(b *strings.Builder, depth int, line string) {
+	b.WriteString(strings.Repeat("  ", depth))
+	b.WriteString(line)
+	b.WriteByte('\n')
+}
+
+func quote(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+func stableCustomID(raw string) string {
+	id, err := bot.ParseCustomID(raw)
+	if err != nil {
+		return raw
+	}
+	if id.Namespace == "paginator" && len(id.State) > 0 {
+		id.State[0] = "id"
+	}
+	return strings.Join(append([]string{id.Namespace, id.Action}, id.State...), ":")
+}
+
+// Result holds the responses sent while handling one interaction, in order.
+type Result struct {
+	Responses []Response
+}
+
+// Last returns the last response, or a zero Response when nothing was sent.
+func (r *Result) Last() Response {
+	if len(r.Responses) == 0 {
+		return Response{}
+	}
+	return r.Responses[len(r.Responses)-1]
+}
+
+// Final returns what the user ends up seeing: the last response carrying a
+// message, modal or choices, skipping deferrals and deletions.
+func (r *Result) Final() Response {
+	for i := len(r.Responses) - 1; i >= 0; i-- {
+		switch r.Responses[i].Kind {
+		case KindDefer, KindDeferUpdate, KindDelete, KindDeleteFollowup:
+			continue
+		}
+		return r.Responses[i]
+	}
+	return Response{}
+}
+
+// String renders every response, for golden files.
+func (r *Result) String() string {
+	var b strings.Builder
+	for i, res := range r.Responses {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(res.String())
+	}
+	return b.String()
+}
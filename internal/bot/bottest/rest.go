@@ -0,0 +1,153 @@
+package bottest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// fakeRest records the interaction requests handlers make through the REST
+// client. The embedded interface is nil, so any other request panics and
+// shows up as a crash of the command under test.
+type fakeRest struct {
+	rest.Rest
+
+	mu       sync.Mutex
+	results  map[string]*Result
+	members  map[snowflake.ID][]discord.Member
+	searches int
+	deleted  map[snowflake.ID]bool
+}
+
+func newFakeRest() *fakeRest {
+	return &fakeRest{
+		results: map[string]*Result{},
+		members: map[snowflake.ID][]discord.Member{},
+		deleted: map[snowflake.ID]bool{},
+	}
+}
+
+func (r *fakeRest) addMember(member discord.Member) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.members[member.GuildID] = append(r.members[member.GuildID], member)
+}
+
+func (r *fakeRest) memberSearches() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.searches
+}
+
+func (r *fakeRest) deleteUser(id snowflake.ID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted[id] = true
+}
+
+// track starts recording the responses to the interaction with token.
+func (r *fakeRest) track(token string) *Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := &Result{}
+	r.results[token] = res
+	return res
+}
+
+func (r *fakeRest) record(token string, kind ResponseKind, data any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res, ok := r.results[token]
+	if !ok {
+		return fmt.Errorf("bottest: unknown interaction token %q", token)
+	}
+	res.Responses = append(res.Responses, newResponse(kind, data))
+	return nil
+}
+
+// respond records the initial response of an interaction.
+func (r *fakeRest) respond(token string) func(discord.InteractionResponseType, discord.InteractionResponseData, ...rest.RequestOpt) error {
+	return func(typ discord.InteractionResponseType, data discord.InteractionResponseData, _ ...rest.RequestOpt) error {
+		kind, ok := responseKinds[typ]
+		if !ok {
+			kind = ResponseKind(fmt.Sprintf("response_%d", typ))
+		}
+		return r.record(token, kind, data)
+	}
+}
+
+func (r *fakeRest) message(token string, kind ResponseKind, data any) (*discord.Message, error) {
+	if err := r.record(token, kind, data); err != nil {
+		return nil, err
+	}
+	return &discord.Message{ID: snowflake.New(time.Now()), CreatedAt: time.Now()}, nil
+}
+
+// SearchMembers matches the start of usernames and nicknames, like Discord.
+func (r *fakeRest) SearchMembers(guildID snowflake.ID, query string, limit int, _ ...rest.RequestOpt) ([]discord.Member, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.searches++
+	query = strings.ToLower(query)
+	var found []discord.Member
+	for _, m := range r.members[guildID] {
+		nick := ""
+		if m.Nick != nil {
+			nick = *m.Nick
+		}
+		if strings.HasPrefix(strings.ToLower(m.User.Username), query) || strings.HasPrefix(strings.ToLower(nick), query) {
+			found = append(found, m)
+		}
+		if len(found) == limit {
+			break
+		}
+	}
+	return found, nil
+}
+
+// GetUser returns the member with the ID when there is one and a user named
+// after the ID otherwise. Deleted users are unknown, as on Discord.
+func (r *fakeRest) GetUser(id snowflake.ID, _ ...rest.RequestOpt) (*discord.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.deleted[id] {
+		return nil, &rest.Error{Code: 10013, Message: "Unknown User"}
+	}
+	for _, members := range r.members {
+		for _, m := range members {
+			if m.User.ID == id {
+				return &m.User, nil
+			}
+		}
+	}
+	return &discord.User{ID: id, Username: "user" + id.String()}, nil
+}
+
+func (r *fakeRest) GetInteractionResponse(_ snowflake.ID, _ string, _ ...rest.RequestOpt) (*discord.Message, error) {
+	return &discord.Message{ID: snowflake.New(time.Now()), CreatedAt: time.Now()}, nil
+}
+
+func (r *fakeRest) UpdateInteractionResponse(_ snowflake.ID, token string, update discord.MessageUpdate, _ ...rest.RequestOpt) (*discord.Message, error) {
+	return r.message(token, KindEdit, update)
+}
+
+func (r *fakeRest) DeleteInteractionResponse(_ snowflake.ID, token string, _ ...rest.RequestOpt) error {
+	return r.record(token, KindDelete, nil)
+}
+
+func (r *fakeRest) CreateFollowupMessage(_ snowflake.ID, token string, create discord.MessageCreate, _ ...rest.RequestOpt) (*discord.Message, error) {
+	return r.message(token, KindFollowup, create)
+}
+
+func (r *fakeRest) UpdateFollowupMessage(_ snowflake.ID, token string, _ snowflake.ID, update discord.MessageUpdate, _ ...rest.RequestOpt) (*discord.Message, error) {
+	return r.message(token, KindEditFollowup, update)
+}
+
+func (r *fakeRest) DeleteFollowupMessage(_ snowflake.ID, token string, _ snowflake.ID, _ ...rest.RequestOpt) error {
+	return r.record(token, KindDeleteFollowup, nil)
+}
@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// unknownUserCode is the Discord error code for users that don't exist.
+const unknownUserCode rest.JSONErrorCode = 10013
+
+// deletedUsernamePrefix starts the username Discord gives deleted accounts.
+const deletedUsernamePrefix = "deleted_user_"
+
+// DeleteUserData removes everything stored about a user in one transaction:
+// the linked Last.fm account, the settings, the guild memberships and the
+// cached Last.fm profile. It reports whether the user was registered.
+func (b *Bot) DeleteUserData(ctx context.Context, userID snowflake.ID) (bool, error) {
+	tx, err := b.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	q := b.Queries.WithTx(tx)
+
+	user, err := q.GetUserByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := q.DeletePresenceOptIn(ctx, userID); err != nil {
+		return false, err
+	}
+	if err := q.DeleteUserGuildMembers(ctx, userID); err != nil {
+		return false, err
+	}
+	if err := q.DeletePlaycount(ctx, user.LastfmUsername); err != nil {
+		return false, err
+	}
+	if err := q.DeleteUser(ctx, userID); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	b.LastFM.User.InfoCache.Delete(user.LastfmUsername)
+
+	logger.Infow("deleted user data", logger.F{"user": userID})
+	return true, nil
+}
+
+// AccountDeleted reports whether a Discord account was deleted. Errors other
+// than Discord not knowing the user are returned as is.
+func (b *Bot) AccountDeleted(userID snowflake.ID) (bool, error) {
+	user, err := b.Client.Rest.GetUser(userID)
+	var restErr *rest.Error
+	if errors.As(err, &restErr) && restErr.Code == unknownUserCode {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return isDeletedUser(*user), nil
+}
+
+// isDeletedUser reports whether user is the placeholder Discord shows for a
+// deleted account.
+func isDeletedUser(user discord.User) bool {
+	return strings.HasPrefix(user.Username, deletedUsernamePrefix)
+}
+
+// cleanupListeners returns the listeners removing data that is no longer
+// needed: the settings and prefix of guilds that removed the bot and the data
+// of deleted accounts, which leave every guild when deleted.
+func (b *Bot) cleanupListeners() []bot.EventListener {
+	return []bot.EventListener{
+		bot.NewListenerFunc(func(e *events.GuildLeave) {
+			if err := b.Queries.DeleteGuildDisabledCommands(b.ctx, e.GuildID); err != nil {
+				logger.Warnw("failed to delete disabled commands", logger.F{"guild": e.GuildID, "err": err.Error()})
+			}
+			if b.MessagePrefix(e.GuildID) == "" {
+				return
+			}
+			if err := b.SetMessagePrefix(b.ctx, e.GuildID, ""); err != nil {
+				logger.Warnw("failed to delete guild prefix", logger.F{"guild": e.GuildID, "err": err.Error()})
+			}
+		}),
+		bot.NewListenerFunc(func(e *events.GuildMemberLeave) {
+			if !isDeletedUser(e.User) {
+				return
+			}
+			if _, err := b.DeleteUserData(b.ctx, e.User.ID); err != nil {
+				logger.Warnw("failed to delete data of deleted account", logger.F{"user": e.User.ID, "err": err.Error()})
+			}
+		}),
+	}
+}
@@ -1,116 +1,98 @@
 package bot
 
 import (
-	"context"
-	"strings"
-	"sync"
 	"time"
 
-	"first.fm/internal/emojis"
-	"first.fm/internal/lastfm"
-	"first.fm/internal/logger"
+	"first.fm/internal/i18n"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	disgohandler "github.com/disgoorg/disgo/handler"
-	"github.com/disgoorg/snowflake/v2"
 )
 
 type CommandContext struct {
 	*disgohandler.CommandEvent
 	*Bot
+	*request
+	// Path is the routed command path, e.g. "/top/artists".
+	Path string
+
+	deferred bool
 }
 
 type CommandHandler func(*CommandContext) error
 
-var (
-	allCommands []discord.ApplicationCommandCreate
-	registry    = map[string]CommandHandler{}
-	initOnce    sync.Once
-)
+// CommandOption configures a command at registration.
+type CommandOption func(*commandConfig)
 
-func Register(meta discord.ApplicationCommandCreate, handler CommandHandler) {
-	initOnce.Do(func() {
-		allCommands = []discord.ApplicationCommandCreate{}
-		registry = map[string]CommandHandler{}
-	})
+type commandConfig struct {
+	autocomplete map[string]AutocompleteHandler
+	components   map[string]ComponentHandler
+	modals       map[string]ModalHandler
+	// limits are cooldowns and concurrency caps, which run before middleware.
+	limits     []Middleware
+	middleware []Middleware
+}
 
-	logger.Infow("registered command", logger.F{"name": meta.CommandName()})
-	allCommands = append(allCommands, meta)
-	registry[meta.CommandName()] = handler
+func newCommandConfig(opts []CommandOption) commandConfig {
+	var cfg commandConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
 }
 
-func Commands() []discord.ApplicationCommandCreate {
-	return allCommands
+// wrap applies the configured limits and middleware to handler.
+func (c commandConfig) wrap(handler CommandHandler) CommandHandler {
+	return Chain(Chain(handler, c.middleware...), c.limits...)
 }
 
 func Dispatcher(bot *Bot) func(*events.ApplicationCommandInteractionCreate) {
 	return func(event *events.ApplicationCommandInteractionCreate) {
-		data := event.SlashCommandInteractionData()
-		handler, ok := registry[data.CommandName()]
+		if !bot.inflight.begin() {
+			replyRestarting(event)
+			return
+		}
+		defer bot.inflight.end()
+
+		path := commandPath(event.Data)
+		handler, ok := bot.Registry.handler(path)
 		if !ok {
 			_ = event.CreateMessage(discord.NewMessageCreateBuilder().
-				SetContent("unknown command").
+				SetContent(i18n.T(i18n.Negotiate(event.Locale(), event.GuildLocale()), "errors.unknown_command")).
 				SetEphemeral(true).
 				Build())
 			return
 		}
 
-		start := time.Now()
-		bgCtx := context.Background()
+		reqCtx, req, cancel := bot.newRequest(event.ApplicationCommandInteraction, path)
+		defer cancel()
+
+		if !bot.commandEnabled(reqCtx, event.GuildID(), event.Data.CommandName()) {
+			replyFailure(event, i18n.Localize(req.Lang, ErrCommandDisabled))
+			return
+		}
+
 		ctx := &CommandContext{
-			Bot: bot,
+			Bot:     bot,
+			request: req,
+			Path:    path,
 			CommandEvent: &disgohandler.CommandEvent{
 				ApplicationCommandInteractionCreate: event,
-				Ctx:                                 bgCtx,
+				Ctx:                                 reqCtx,
 			},
 		}
 
-		if err := handler(ctx); err != nil {
-			logger.Warnw("command failed", logger.F{"name": data.CommandName(), "err": err.Error()})
-			_ = ctx.CreateMessage(discord.NewMessageCreateBuilder().
-				SetContentf("%s %v", emojis.EmojiCross, err).
-				SetEphemeral(true).
-				Build())
-		}
-
-		logger.Infow("executed command", logger.F{"name": data.CommandName(), "time": time.Since(start)})
-	}
-}
-
-// now ima explain why this fucking function fetches the user everytime
-// so first of all, it is cached so dont fucking worry ok.
-// also this helps to cache the user for future requests do you get me
-// so stfu ik this fucking function fetches the entire user instead of only returning
-// a fucking username. ~elisiei
-// edit: however, i should do an alternative function to get only the username anyways :kekw:. ~elisiei
-func (ctx *CommandContext) GetLastFMUser(optionName string) (*lastfm.UserInfo, error) {
-	if optionName == "" {
-		optionName = "user"
-	}
-
-	rawUser, defined := ctx.SlashCommandInteractionData().OptString(optionName)
-	if defined && rawUser != "" {
-		if id, err := snowflake.Parse(normalizeUserMention(rawUser)); err == nil {
-			if dbUser, err := ctx.Queries.GetUserByID(ctx.Ctx, id); err == nil {
-				rawUser = dbUser.LastfmUsername
-			}
-		}
-
-		return ctx.LastFM.User.Info(rawUser)
-	}
-
-	user, err := ctx.Queries.GetUserByID(ctx.Ctx, ctx.User().ID)
-	if err != nil {
-		return nil, err
+		start := time.Now()
+		err := Chain(handler, bot.Registry.defaultMiddleware()...)(ctx)
+		observeCommand(path, err, time.Since(start))
 	}
-	return ctx.LastFM.User.Info(user.LastfmUsername)
 }
 
-func normalizeUserMention(input string) string {
-	input = strings.TrimSpace(input)
-	if strings.HasPrefix(input, "<@") && strings.HasSuffix(input, ">") {
-		trimmed := strings.TrimSuffix(strings.TrimPrefix(input, "<@"), ">")
-		return strings.TrimPrefix(trimmed, "!")
+// commandPath returns the registry key of an interaction: "/name" for plain
+// commands and "/name/group/sub" for subcommands.
+func commandPath(data discord.ApplicationCommandInteractionData) string {
+	if slash, ok := data.(discord.SlashCommandInteractionData); ok {
+		return slash.CommandPath()
 	}
-	return input
+	return "/" + data.CommandName()
 }
@@ -0,0 +1,324 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"first.fm/internal/emojis"
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// DefaultComponentTTL is how long components built with CommandContext.CustomID
+// stay usable. It matches the lifetime of the interaction token, after which
+// the message can't be edited anyway.
+const DefaultComponentTTL = 15 * time.Minute
+
+// maxCustomIDLength is the maximum length Discord accepts for custom IDs.
+const maxCustomIDLength = 100
+
+var (
+	ErrCustomIDTooLong = errors.New("custom id is longer than 100 characters")
+	ErrInvalidCustomID = errors.New("invalid custom id")
+)
+
+// CustomID is the custom ID of a component or modal routed by the bot. It is
+// encoded as "namespace:action:owner:expires:state..." where namespace is the
+// name of the command that owns the component, owner and expires are base 36
+// (0 when unset) and every state value is query escaped.
+type CustomID struct {
+	Namespace string
+	Action    string
+	// Owner is the only user allowed to use the component. 0 allows anyone.
+	Owner snowflake.ID
+	// Expires is when the component stops working. Zero never expires.
+	Expires time.Time
+	State   []string
+}
+
+// NewCustomID returns a custom ID without owner or expiry. Namespace and
+// action can't contain ":", which Encode rejects.
+func NewCustomID(namespace, action string, state ...string) CustomID {
+	return CustomID{Namespace: namespace, Action: action, State: state}
+}
+
+// WithOwner restricts the component to the given user.
+func (c CustomID) WithOwner(id snowflake.ID) CustomID {
+	c.Owner = id
+	return c
+}
+
+// WithTTL makes the component expire after d.
+func (c CustomID) WithTTL(d time.Duration) CustomID {
+	c.Expires = time.Now().Add(d)
+	return c
+}
+
+// WithState replaces the state carried by the custom ID.
+func (c CustomID) WithState(state ...string) CustomID {
+	c.State = state
+	return c
+}
+
+// Expired reports whether the component has expired.
+func (c CustomID) Expired() bool {
+	return !c.Expires.IsZero() && time.Now().After(c.Expires)
+}
+
+// route returns the registry key of the custom ID.
+func (c CustomID) route() string {
+	return c.Namespace + ":" + c.Action
+}
+
+// Encode returns the custom ID as sent to Discord. It fails when the
+// namespace or action contains the ":" separator, which would route the
+// component to another handler.
+func (c CustomID) Encode() (string, error) {
+	if strings.Contains(c.Namespace, ":") || strings.Contains(c.Action, ":") {
+		return "", fmt.Errorf("%w: %q in %s", ErrInvalidCustomID, ":", c.route())
+	}
+
+	var expires int64
+	if !c.Expires.IsZero() {
+		expires = c.Expires.Unix()
+	}
+
+	parts := []string{
+		c.Namespace,
+		c.Action,
+		strconv.FormatUint(uint64(c.Owner), 36),
+		strconv.FormatInt(expires, 36),
+	}
+	for _, s := range c.State {
+		parts = append(parts, url.QueryEscape(s))
+	}
+
+	id := strings.Join(parts, ":")
+	if len(id) > maxCustomIDLength {
+		return "", fmt.Errorf("%w: %s", ErrCustomIDTooLong, id)
+	}
+	return id, nil
+}
+
+// ParseCustomID decodes a custom ID produced by CustomID.Encode.
+func ParseCustomID(raw string) (CustomID, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) < 4 {
+		return CustomID{}, ErrInvalidCustomID
+	}
+
+	owner, err := strconv.ParseUint(parts[2], 36, 64)
+	if err != nil {
+		return CustomID{}, fmt.Errorf("%w: owner: %v", ErrInvalidCustomID, err)
+	}
+	expires, err := strconv.ParseInt(parts[3], 36, 64)
+	if err != nil {
+		return CustomID{}, fmt.Errorf("%w: expires: %v", ErrInvalidCustomID, err)
+	}
+
+	id := CustomID{
+		Namespace: parts[0],
+		Action:    parts[1],
+		Owner:     snowflake.ID(owner),
+	}
+	if expires > 0 {
+		id.Expires = time.Unix(expires, 0)
+	}
+	for _, p := range parts[4:] {
+		s, err := url.QueryUnescape(p)
+		if err != nil {
+			return CustomID{}, fmt.Errorf("%w: state: %v", ErrInvalidCustomID, err)
+		}
+		id.State = append(id.State, s)
+	}
+	return id, nil
+}
+
+// CustomID returns a custom ID namespaced to the invoked command, owned by
+// the invoking user and expiring after DefaultComponentTTL.
+func (ctx *CommandContext) CustomID(action string, state ...string) CustomID {
+	return NewCustomID(ctx.Data.CommandName(), action, state...).
+		WithOwner(ctx.User().ID).
+		WithTTL(DefaultComponentTTL)
+}
+
+type ComponentContext struct {
+	*events.ComponentInteractionCreate
+	*Bot
+	*request
+	Ctx context.Context
+	ID  CustomID
+}
+
+type ComponentHandler func(*ComponentContext) error
+
+type ModalContext struct {
+	*events.ModalSubmitInteractionCreate
+	*Bot
+	*request
+	Ctx context.Context
+	ID  CustomID
+}
+
+type ModalHandler func(*ModalContext) error
+
+// WithComponent handles the buttons and select menus of a command whose
+// custom ID has the given action.
+func WithComponent(action string, handler ComponentHandler) CommandOption {
+	return func(c *commandConfig) {
+		if c.components == nil {
+			c.components = map[string]ComponentHandler{}
+		}
+		c.components[action] = handler
+	}
+}
+
+// WithModal handles the modal submissions of a command whose custom ID has
+// the given action.
+func WithModal(action string, handler ModalHandler) CommandOption {
+	return func(c *commandConfig) {
+		if c.modals == nil {
+			c.modals = map[string]ModalHandler{}
+		}
+		c.modals[action] = handler
+	}
+}
+
+// checkCustomID parses a custom ID and verifies that it may be used by user.
+// The returned catalog key describes the problem to the user when the check
+// fails.
+func checkCustomID(raw string, user snowflake.ID) (CustomID, string, bool) {
+	id, err := ParseCustomID(raw)
+	if err != nil {
+		return id, "components.unsupported", false
+	}
+	if id.Expired() {
+		return id, "components.expired", false
+	}
+	if id.Owner != 0 && id.Owner != user {
+		return id, "components.not_owner", false
+	}
+	return id, "", true
+}
+
+func ComponentDispatcher(bot *Bot) func(*events.ComponentInteractionCreate) {
+	return func(event *events.ComponentInteractionCreate) {
+		if !bot.inflight.begin() {
+			replyRestarting(event)
+			return
+		}
+		defer bot.inflight.end()
+
+		id, ok := bot.checkRouted(event, event.Data.CustomID(), event.User().ID)
+		if !ok {
+			return
+		}
+		handler, ok := bot.Registry.component(id.route())
+		if !ok {
+			logger.Warnw("unknown component", logger.F{"route": id.route()})
+			_ = event.DeferUpdateMessage()
+			return
+		}
+
+		reqCtx, req, cancel := bot.newRequest(event.ComponentInteraction, id.route())
+		defer cancel()
+
+		ctx := &ComponentContext{
+			ComponentInteractionCreate: event,
+			Bot:                        bot,
+			request:                    req,
+			Ctx:                        reqCtx,
+			ID:                         id,
+		}
+		bot.runRouted(ctx.Ctx, "component", id, event.ComponentInteraction, event, req, func() error {
+			return handler(ctx)
+		})
+	}
+}
+
+func ModalDispatcher(bot *Bot) func(*events.ModalSubmitInteractionCreate) {
+	return func(event *events.ModalSubmitInteractionCreate) {
+		if !bot.inflight.begin() {
+			replyRestarting(event)
+			return
+		}
+		defer bot.inflight.end()
+
+		id, ok := bot.checkRouted(event, event.Data.CustomID, event.User().ID)
+		if !ok {
+			return
+		}
+		handler, ok := bot.Registry.modal(id.route())
+		if !ok {
+			logger.Warnw("unknown modal", logger.F{"route": id.route()})
+			_ = event.DeferUpdateMessage()
+			return
+		}
+
+		reqCtx, req, cancel := bot.newRequest(event.ModalSubmitInteraction, id.route())
+		defer cancel()
+
+		ctx := &ModalContext{
+			ModalSubmitInteractionCreate: event,
+			Bot:                          bot,
+			request:                      req,
+			Ctx:                          reqCtx,
+			ID:                           id,
+		}
+		bot.runRouted(ctx.Ctx, "modal", id, event.ModalSubmitInteraction, event, req, func() error {
+			return handler(ctx)
+		})
+	}
+}
+
+// checkRouted parses the custom ID of a component or modal interaction and
+// verifies that the user may use it, telling them why not otherwise.
+func (b *Bot) checkRouted(event messageCreator, raw string, user snowflake.ID) (CustomID, bool) {
+	id, reason, ok := checkCustomID(raw, user)
+	if !ok {
+		replyFailure(event, i18n.T(i18n.Negotiate(event.Locale(), event.GuildLocale()), reason))
+	}
+	return id, ok
+}
+
+// runRouted runs the handler of a component or modal interaction unless its
+// command is disabled in the guild. Panics are turned into crash reports and
+// the error the handler returns is shown to the user.
+func (b *Bot) runRouted(ctx context.Context, kind string, id CustomID, i discord.Interaction, event messageCreator, req *request, handler func() error) {
+	if !b.commandEnabled(ctx, i.GuildID(), id.Namespace) {
+		replyFailure(event, i18n.Localize(req.Lang, ErrCommandDisabled))
+		return
+	}
+
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = b.reportPanic(r, id.route(), i, req)
+			}
+		}()
+		return handler()
+	}()
+	if err != nil {
+		req.Log.Warnw(kind+" failed", logger.F{"err": err.Error()})
+		replyFailure(event, i18n.Localize(req.Lang, err))
+	}
+
+	req.Log.Debugw("executed "+kind, logger.F{"time": time.Since(start)})
+}
+
+// replyFailure sends text to the user as an ephemeral error message.
+func replyFailure(event messageCreator, text string) {
+	_ = event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetContentf("%s %s", emojis.EmojiCross, text).
+		SetEphemeral(true).
+		Build())
+}
@@ -0,0 +1,113 @@
+package bot
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+func TestCustomIDRoundTrip(t *testing.T) {
+	expires := time.Unix(1760000000, 0)
+	tests := []struct {
+		name    string
+		id      CustomID
+		encoded string
+	}{
+		{"bare", NewCustomID("fm", "refresh"), "fm:refresh:0:0"},
+		{"owner and expiry", CustomID{Namespace: "fm", Action: "refresh", Owner: 2001, Expires: expires}, "fm:refresh:1jl:t3uwow"},
+		{"state", NewCustomID("top", "page", "rj", "2"), "top:page:0:0:rj:2"},
+		{"escaped state", NewCustomID("top", "page", "a:b c/é"), "top:page:0:0:a%3Ab+c%2F%C3%A9"},
+		{"empty state", NewCustomID("top", "page", ""), "top:page:0:0:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.id.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if encoded != tt.encoded {
+				t.Errorf("Encode() = %q, want %q", encoded, tt.encoded)
+			}
+
+			parsed, err := ParseCustomID(encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Namespace != tt.id.Namespace || parsed.Action != tt.id.Action || parsed.Owner != tt.id.Owner ||
+				!parsed.Expires.Equal(tt.id.Expires) || !slices.Equal(parsed.State, tt.id.State) {
+				t.Errorf("ParseCustomID(%q) = %+v, want %+v", encoded, parsed, tt.id)
+			}
+		})
+	}
+}
+
+func TestCustomIDEncodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		id   CustomID
+		err  error
+	}{
+		{"separator in namespace", NewCustomID("a:b", "refresh"), ErrInvalidCustomID},
+		{"separator in action", NewCustomID("fm", "page:next"), ErrInvalidCustomID},
+		{"too long", NewCustomID("fm", "refresh", strings.Repeat("x", maxCustomIDLength)), ErrCustomIDTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.id.Encode(); !errors.Is(err, tt.err) {
+				t.Errorf("Encode() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseCustomIDErrors(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"fm:refresh",
+		"fm:refresh:0",
+		"fm:refresh:-1:0",
+		"fm:refresh:0:soon!",
+		"fm:refresh:0:0:%zz",
+	} {
+		if _, err := ParseCustomID(raw); !errors.Is(err, ErrInvalidCustomID) {
+			t.Errorf("ParseCustomID(%q) error = %v, want ErrInvalidCustomID", raw, err)
+		}
+	}
+}
+
+func TestCheckCustomID(t *testing.T) {
+	const owner, other = snowflake.ID(2001), snowflake.ID(2002)
+	encode := func(id CustomID) string {
+		raw, err := id.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		user snowflake.ID
+		key  string
+	}{
+		{"anyone", encode(NewCustomID("fm", "refresh")), other, ""},
+		{"owner", encode(NewCustomID("fm", "refresh").WithOwner(owner)), owner, ""},
+		{"not owner", encode(NewCustomID("fm", "refresh").WithOwner(owner)), other, "components.not_owner"},
+		{"expired", encode(NewCustomID("fm", "refresh").WithTTL(-time.Minute)), owner, "components.expired"},
+		{"not expired", encode(NewCustomID("fm", "refresh").WithTTL(time.Minute)), owner, ""},
+		{"legacy", "refresh_fm", owner, "components.unsupported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, key, ok := checkCustomID(tt.raw, tt.user)
+			if key != tt.key || ok != (tt.key == "") {
+				t.Errorf("checkCustomID() = %q, %v, want %q", key, ok, tt.key)
+			}
+		})
+	}
+}
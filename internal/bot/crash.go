@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// CrashReport describes a handler panic.
+type CrashReport struct {
+	// ID is the correlation ID shown to the user and logged with the stack.
+	ID            string
+	Time          time.Time
+	Route         string
+	InteractionID snowflake.ID
+	UserID        snowflake.ID
+	GuildID       snowflake.ID
+	ChannelID     snowflake.ID
+	Panic         string
+	Stack         string
+}
+
+// CrashLog keeps the most recent crash reports in memory.
+type CrashLog struct {
+	mu      sync.Mutex
+	reports []CrashReport
+	size    int
+}
+
+func NewCrashLog(size int) *CrashLog {
+	return &CrashLog{size: size}
+}
+
+// Add records a report, dropping the oldest one when the log is full.
+func (l *CrashLog) Add(report CrashReport) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.reports) >= l.size {
+		l.reports = l.reports[1:]
+	}
+	l.reports = append(l.reports, report)
+}
+
+// Recent returns up to n reports, newest first.
+func (l *CrashLog) Recent(n int) []CrashReport {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	recent := slices.Clone(l.reports)
+	slices.Reverse(recent)
+	if n > 0 && len(recent) > n {
+		recent = recent[:n]
+	}
+	return recent
+}
+
+// Get returns the report with the given correlation ID.
+func (l *CrashLog) Get(id string) (CrashReport, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, r := range l.reports {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return CrashReport{}, false
+}
+
+// Len returns the number of stored reports.
+func (l *CrashLog) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.reports)
+}
+
+// PanicError is returned in place of a handler that panicked.
+type PanicError struct {
+	Report CrashReport
+}
+
+func (e *PanicError) Error() string {
+	return e.Localize(i18n.Default)
+}
+
+// Localize returns the message with the correlation ID in locale.
+func (e *PanicError) Localize(locale discord.Locale) string {
+	return i18n.T(locale, "errors.panic", e.Report.ID)
+}
+
+// reportPanic logs a recovered panic with its stack trace to the request's
+// logger, records it in the crash log under the request ID and returns the
+// error to show.
+func (b *Bot) reportPanic(recovered any, route string, i discord.Interaction, req *request) *PanicError {
+	report := CrashReport{
+		ID:            req.RequestID,
+		Time:          time.Now(),
+		Route:         route,
+		InteractionID: i.ID(),
+		UserID:        i.User().ID,
+		ChannelID:     interactionChannelID(i),
+		Panic:         fmt.Sprint(recovered),
+		Stack:         string(debug.Stack()),
+	}
+	if guildID := i.GuildID(); guildID != nil {
+		report.GuildID = *guildID
+	}
+
+	req.Log.Errorw("recovered from panic", logger.F{
+		"interaction": report.InteractionID,
+		"channel":     report.ChannelID,
+		"panic":       report.Panic,
+		"stack":       report.Stack,
+	})
+
+	if b.Crashes != nil {
+		b.Crashes.Add(report)
+	}
+	return &PanicError{Report: report}
+}
+
+func newCorrelationID() string {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"first.fm/internal/logger"
+	"first.fm/internal/persistence/sqlc"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// DisableCommand stops the named command, and its components and modals,
+// from running in guild. The setting is stored in the database, so every
+// process sharing it applies it right away.
+func (b *Bot) DisableCommand(ctx context.Context, guild snowflake.ID, name string) error {
+	if !b.Registry.Has(name) {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, name)
+	}
+	return b.Queries.DisableCommand(ctx, sqlc.DisableCommandParams{GuildID: guild, Command: name})
+}
+
+// EnableCommand lets a command disabled with DisableCommand run in guild
+// again.
+func (b *Bot) EnableCommand(ctx context.Context, guild snowflake.ID, name string) error {
+	if !b.Registry.Has(name) {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, name)
+	}
+	return b.Queries.EnableCommand(ctx, sqlc.EnableCommandParams{GuildID: guild, Command: name})
+}
+
+// DisabledCommands returns the names of the commands disabled in guild,
+// sorted.
+func (b *Bot) DisabledCommands(ctx context.Context, guild snowflake.ID) ([]string, error) {
+	return b.Queries.ListDisabledCommands(ctx, guild)
+}
+
+// commandEnabled reports whether the named command may run in guild.
+// Commands are always enabled outside of guilds, and when the database can't
+// be read, so a database hiccup doesn't take every command down.
+func (b *Bot) commandEnabled(ctx context.Context, guild *snowflake.ID, name string) bool {
+	if guild == nil {
+		return true
+	}
+	n, err := b.Queries.IsCommandDisabled(ctx, sqlc.IsCommandDisabledParams{GuildID: *guild, Command: name})
+	if err != nil {
+		logger.FromContext(ctx).Warnw("failed to check disabled commands", logger.F{"command": name, "err": err.Error()})
+		return true
+	}
+	return n == 0
+}
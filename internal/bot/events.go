@@ -8,12 +8,16 @@ import (
 	"github.com/disgoorg/disgo/gateway"
 )
 
-func onReady(event *events.Ready) {
-	logger.Info("started client")
-	event.
-		Client().
-		SetPresence(
-			context.Background(),
-			gateway.WithCustomActivity("listen to crystal castles!"),
-		)
+// onReady sets the current custom status on a shard once it is connected, so
+// a shard that reconnects shows the same status as the others.
+func (b *Bot) onReady(event *events.Ready) {
+	logger.Infow("shard ready", logger.F{"shard": event.ShardID(), "guilds": len(event.Guilds)})
+	err := b.Client.SetPresenceForShard(
+		context.Background(),
+		event.ShardID(),
+		gateway.WithCustomActivity(b.currentStatus()),
+	)
+	if err != nil {
+		logger.Warnw("failed to set presence", logger.F{"shard": event.ShardID(), "err": err.Error()})
+	}
 }
@@ -0,0 +1,24 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrGatewayNotReady is returned by GatewayReady while a shard isn't
+// connected.
+var ErrGatewayNotReady = errors.New("gateway not ready")
+
+// GatewayReady reports whether every shard run by this process is connected
+// and ready, for readiness checks.
+func (b *Bot) GatewayReady(context.Context) error {
+	if notReady := b.notReadyShards(); len(notReady) > 0 {
+		return fmt.Errorf("%w: %s", ErrGatewayNotReady, strings.Join(notReady, ", "))
+	}
+	if len(b.shards()) == 0 {
+		return ErrGatewayNotReady
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"strings"
+
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// localizationPrefix returns the catalog prefix of a command:
+// "commands.<name>" for slash commands and "user_commands.<name>" or
+// "message_commands.<name>" for context menus, with spaces replaced by
+// underscores.
+func localizationPrefix(meta discord.ApplicationCommandCreate) string {
+	name := strings.ReplaceAll(strings.ToLower(meta.CommandName()), " ", "_")
+	switch meta.(type) {
+	case discord.UserCommandCreate:
+		return "user_commands." + name
+	case discord.MessageCommandCreate:
+		return "message_commands." + name
+	default:
+		return "commands." + name
+	}
+}
+
+// localizeCommand fills the name and description localizations of a command
+// and its options from the catalog. Descriptions in the default locale are
+// taken from the catalog too, the ones in code are a fallback, while names
+// stay as in code since commands are routed by them. It returns the keys the
+// command needs.
+func localizeCommand(meta discord.ApplicationCommandCreate) (discord.ApplicationCommandCreate, []string) {
+	prefix := localizationPrefix(meta)
+
+	switch c := meta.(type) {
+	case discord.SlashCommandCreate:
+		var keys []string
+		c.NameLocalizations, keys = localizeName(prefix, keys)
+		c.Description, c.DescriptionLocalizations, keys = localizeDescription(prefix, c.Description, keys)
+		c.Options, keys = localizeOptions(prefix, c.Options, keys)
+		return c, keys
+	case discord.UserCommandCreate:
+		c.NameLocalizations = i18n.Localizations(prefix + ".name")
+		return c, []string{prefix + ".name"}
+	case discord.MessageCommandCreate:
+		c.NameLocalizations = i18n.Localizations(prefix + ".name")
+		return c, []string{prefix + ".name"}
+	}
+	return meta, nil
+}
+
+// localizeName returns the name localizations of the command, subcommand or
+// group with the given catalog prefix.
+func localizeName(prefix string, keys []string) (map[discord.Locale]string, []string) {
+	key := prefix + ".name"
+	return i18n.Localizations(key), append(keys, key)
+}
+
+func localizeDescription(prefix, fallback string, keys []string) (string, map[discord.Locale]string, []string) {
+	key := prefix + ".description"
+	description, ok := i18n.Lookup(i18n.Default, key)
+	if !ok {
+		description = fallback
+	}
+	return description, i18n.Localizations(key), append(keys, key)
+}
+
+func localizeOptions(prefix string, options []discord.ApplicationCommandOption, keys []string) ([]discord.ApplicationCommandOption, []string) {
+	localized := make([]discord.ApplicationCommandOption, len(options))
+	for i, option := range options {
+		p := prefix + ".options." + option.OptionName()
+		switch o := option.(type) {
+		case discord.ApplicationCommandOptionSubCommand:
+			o, keys = localizeSubCommand(prefix, o, keys)
+			option = o
+		case discord.ApplicationCommandOptionSubCommandGroup:
+			gp := prefix + "." + o.Name
+			o.NameLocalizations, keys = localizeName(gp, keys)
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(gp, o.Description, keys)
+			for j, sub := range o.Options {
+				o.Options[j], keys = localizeSubCommand(gp, sub, keys)
+			}
+			option = o
+		case discord.ApplicationCommandOptionString:
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(p, o.Description, keys)
+			option = o
+		case discord.ApplicationCommandOptionInt:
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(p, o.Description, keys)
+			option = o
+		case discord.ApplicationCommandOptionBool:
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(p, o.Description, keys)
+			option = o
+		case discord.ApplicationCommandOptionUser:
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(p, o.Description, keys)
+			option = o
+		case discord.ApplicationCommandOptionChannel:
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(p, o.Description, keys)
+			option = o
+		case discord.ApplicationCommandOptionRole:
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(p, o.Description, keys)
+			option = o
+		case discord.ApplicationCommandOptionMentionable:
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(p, o.Description, keys)
+			option = o
+		case discord.ApplicationCommandOptionFloat:
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(p, o.Description, keys)
+			option = o
+		case discord.ApplicationCommandOptionAttachment:
+			o.Description, o.DescriptionLocalizations, keys = localizeDescription(p, o.Description, keys)
+			option = o
+		}
+		localized[i] = option
+	}
+	return localized, keys
+}
+
+func localizeSubCommand(prefix string, sub discord.ApplicationCommandOptionSubCommand, keys []string) (discord.ApplicationCommandOptionSubCommand, []string) {
+	p := prefix + "." + sub.Name
+	sub.NameLocalizations, keys = localizeName(p, keys)
+	sub.Description, sub.DescriptionLocalizations, keys = localizeDescription(p, sub.Description, keys)
+	sub.Options, keys = localizeOptions(p, sub.Options, keys)
+	return sub, keys
+}
+
+// checkLocalizations logs the catalog keys the registered commands and the
+// supported locales are missing.
+func (r *Registry) checkLocalizations() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for locale, keys := range i18n.Missing(r.localizationKeys...) {
+		logger.Warnw("missing localizations", logger.F{
+			"locale": locale.Code(),
+			"count":  len(keys),
+			"keys":   strings.Join(keys, ", "),
+		})
+	}
+}
+
+// T returns the message for key in the locale negotiated for the
+// interaction.
+func (r *request) T(key string, args ...any) string {
+	return i18n.T(r.Lang, key, args...)
+}
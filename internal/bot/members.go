@@ -0,0 +1,233 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"first.fm/internal/logger"
+	"first.fm/internal/persistence/sqlc"
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const (
+	// memberBatchSize is how many members are written per query.
+	memberBatchSize = 1000
+	// memberChunkTimeout bounds how long fetching the members of a guild
+	// may take.
+	memberChunkTimeout = 2 * time.Minute
+)
+
+// memberIndex keeps the guild_members table in sync with the gateway so
+// server features can query the registered members of a guild and find
+// members by name without fetching its members. Joins and leaves are written as they arrive; whole
+// guilds are indexed in the background, one at a time, since fetching the
+// members of a large guild waits on chunks delivered by the gateway goroutine
+// the listeners run on.
+type memberIndex struct {
+	mu sync.Mutex
+	// pending holds the guilds waiting to be indexed with the members their
+	// create event carried, or nil when they have to be fetched.
+	pending map[snowflake.ID][]discord.Member
+	order   []snowflake.ID
+	wake    chan struct{}
+}
+
+// queue schedules a guild for indexing. Queuing a guild again replaces its
+// members without indexing it twice.
+func (m *memberIndex) queue(guildID snowflake.ID, members []discord.Member) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pending == nil {
+		m.pending = map[snowflake.ID][]discord.Member{}
+	}
+	if m.wake == nil {
+		m.wake = make(chan struct{}, 1)
+	}
+	if _, ok := m.pending[guildID]; !ok {
+		m.order = append(m.order, guildID)
+	}
+	m.pending[guildID] = members
+
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// forget drops a guild that is still waiting to be indexed.
+func (m *memberIndex) forget(guildID snowflake.ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, guildID)
+}
+
+// next returns the next guild to index, skipping forgotten ones.
+func (m *memberIndex) next() (snowflake.ID, []discord.Member, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for len(m.order) > 0 {
+		guildID := m.order[0]
+		m.order = m.order[1:]
+		if members, ok := m.pending[guildID]; ok {
+			delete(m.pending, guildID)
+			return guildID, members, true
+		}
+	}
+	return 0, nil, false
+}
+
+// signal returns the channel notified when guilds are queued.
+func (m *memberIndex) signal() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.wake == nil {
+		m.wake = make(chan struct{}, 1)
+	}
+	return m.wake
+}
+
+// memberListeners returns the listeners keeping the member index up to date.
+func (b *Bot) memberListeners() []bot.EventListener {
+	return []bot.EventListener{
+		bot.NewListenerFunc(func(e *events.GuildReady) { b.queueGuild(e.Guild) }),
+		bot.NewListenerFunc(func(e *events.GuildJoin) { b.queueGuild(e.Guild) }),
+		bot.NewListenerFunc(func(e *events.GuildAvailable) { b.queueGuild(e.Guild) }),
+		bot.NewListenerFunc(func(e *events.GuildLeave) { b.removeGuild(e.GuildID) }),
+		bot.NewListenerFunc(func(e *events.GuildMemberJoin) { b.indexMember(e.Member) }),
+		bot.NewListenerFunc(func(e *events.GuildMemberUpdate) { b.indexMember(e.Member) }),
+		bot.NewListenerFunc(func(e *events.GuildMemberLeave) {
+			err := b.Queries.RemoveGuildMember(b.ctx, sqlc.RemoveGuildMemberParams{GuildID: e.GuildID, UserID: e.User.ID})
+			if err != nil {
+				logger.Warnw("failed to unindex member", logger.F{"guild": e.GuildID, "user": e.User.ID, "err": err.Error()})
+			}
+		}),
+	}
+}
+
+// indexMember adds a member to the index or updates its names.
+func (b *Bot) indexMember(m discord.Member) {
+	names := memberNamesOf(m)
+	err := b.Queries.AddGuildMember(b.ctx, sqlc.AddGuildMemberParams{
+		GuildID:    m.GuildID,
+		UserID:     m.User.ID,
+		Username:   names.Username,
+		GlobalName: names.GlobalName,
+		Nick:       names.Nick,
+	})
+	if err != nil {
+		logger.Warnw("failed to index member", logger.F{"guild": m.GuildID, "user": m.User.ID, "err": err.Error()})
+	}
+}
+
+// memberNames are the names a member can be found by, lowercased as stored
+// in the index.
+type memberNames struct {
+	ID         snowflake.ID `json:"id"`
+	Username   string       `json:"username"`
+	GlobalName string       `json:"global_name"`
+	Nick       string       `json:"nick"`
+}
+
+func memberNamesOf(m discord.Member) memberNames {
+	names := memberNames{ID: m.User.ID, Username: strings.ToLower(m.User.Username)}
+	if m.User.GlobalName != nil {
+		names.GlobalName = strings.ToLower(*m.User.GlobalName)
+	}
+	if m.Nick != nil {
+		names.Nick = strings.ToLower(*m.Nick)
+	}
+	return names
+}
+
+// queueGuild schedules a guild for indexing. The members sent with the guild
+// are used when they are all of them, which is the case for small guilds.
+func (b *Bot) queueGuild(guild discord.GatewayGuild) {
+	var members []discord.Member
+	if !guild.Large && len(guild.Members) >= guild.MemberCount {
+		members = guild.Members
+		if members == nil {
+			members = []discord.Member{}
+		}
+	}
+	b.members.queue(guild.ID, members)
+}
+
+// removeGuild drops the members of a guild the bot left.
+func (b *Bot) removeGuild(guildID snowflake.ID) {
+	b.members.forget(guildID)
+	if err := b.Queries.DeleteGuildMembers(b.ctx, guildID); err != nil {
+		logger.Warnw("failed to delete guild members", logger.F{"guild": guildID, "err": err.Error()})
+	}
+}
+
+// indexMembers indexes queued guilds until ctx is done.
+func (b *Bot) indexMembers(ctx context.Context) {
+	wake := b.members.signal()
+	for {
+		for {
+			guildID, members, ok := b.members.next()
+			if !ok {
+				break
+			}
+			if err := b.indexGuild(ctx, guildID, members); err != nil && ctx.Err() == nil {
+				logger.Warnw("failed to index guild members", logger.F{"guild": guildID, "err": err.Error()})
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		}
+	}
+}
+
+// indexGuild replaces the indexed members of a guild, fetching them from the
+// gateway when members is nil.
+func (b *Bot) indexGuild(ctx context.Context, guildID snowflake.ID, members []discord.Member) error {
+	start := time.Now()
+	if members == nil {
+		fetchCtx, cancel := context.WithTimeout(ctx, memberChunkTimeout)
+		defer cancel()
+
+		var err error
+		members, err = b.Client.MemberChunkingManager.RequestAllMembers(fetchCtx, guildID)
+		if err != nil {
+			return err
+		}
+	}
+
+	ids := make([]snowflake.ID, len(members))
+	names := make([]memberNames, len(members))
+	for i, m := range members {
+		ids[i] = m.User.ID
+		names[i] = memberNamesOf(m)
+	}
+	for batch := range slices.Chunk(names, memberBatchSize) {
+		encoded, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		if err := b.Queries.AddGuildMembers(ctx, sqlc.AddGuildMembersParams{GuildID: guildID, Members: string(encoded)}); err != nil {
+			return err
+		}
+	}
+
+	encoded, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	if err := b.Queries.PruneGuildMembers(ctx, sqlc.PruneGuildMembersParams{GuildID: guildID, UserIds: string(encoded)}); err != nil {
+		return err
+	}
+
+	logger.Debugw("indexed guild members", logger.F{"guild": guildID, "members": len(ids), "time": time.Since(start)})
+	return nil
+}
@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"first.fm/internal/persistence/sqlc"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+func TestMemberIndexQueue(t *testing.T) {
+	alice := []discord.Member{{User: discord.User{ID: 1}}}
+	bob := []discord.Member{{User: discord.User{ID: 2}}}
+
+	type queued struct {
+		guildID snowflake.ID
+		members []discord.Member
+	}
+	tests := []struct {
+		name string
+		run  func(m *memberIndex)
+		want []queued
+	}{
+		{
+			name: "empty",
+			run:  func(m *memberIndex) {},
+		},
+		{
+			name: "in order",
+			run: func(m *memberIndex) {
+				m.queue(10, alice)
+				m.queue(20, nil)
+			},
+			want: []queued{{10, alice}, {20, nil}},
+		},
+		{
+			name: "queued again replaces the members",
+			run: func(m *memberIndex) {
+				m.queue(10, alice)
+				m.queue(20, nil)
+				m.queue(10, bob)
+			},
+			want: []queued{{10, bob}, {20, nil}},
+		},
+		{
+			name: "forgotten guilds are skipped",
+			run: func(m *memberIndex) {
+				m.queue(10, alice)
+				m.queue(20, bob)
+				m.forget(10)
+			},
+			want: []queued{{20, bob}},
+		},
+		{
+			name: "queued after being forgotten",
+			run: func(m *memberIndex) {
+				m.queue(10, alice)
+				m.forget(10)
+				m.queue(10, bob)
+			},
+			want: []queued{{10, bob}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m memberIndex
+			tt.run(&m)
+
+			var got []queued
+			for {
+				guildID, members, ok := m.next()
+				if !ok {
+					break
+				}
+				got = append(got, queued{guildID, members})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("next returned %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i].guildID != tt.want[i].guildID || len(got[i].members) != len(tt.want[i].members) ||
+					(got[i].members == nil) != (tt.want[i].members == nil) {
+					t.Errorf("next returned %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemberIndexSignal(t *testing.T) {
+	var m memberIndex
+	wake := m.signal()
+	m.queue(10, nil)
+	m.queue(20, nil)
+
+	select {
+	case <-wake:
+	default:
+		t.Fatal("queue didn't signal")
+	}
+	select {
+	case <-wake:
+		t.Fatal("queue signaled twice before being woken")
+	default:
+	}
+}
+
+func TestMemberNamesOf(t *testing.T) {
+	globalName, nick := "Kate B", "KATIE"
+	tests := []struct {
+		name   string
+		member discord.Member
+		want   memberNames
+	}{
+		{
+			name:   "username only",
+			member: discord.Member{User: discord.User{ID: 1, Username: "RJ"}},
+			want:   memberNames{ID: 1, Username: "rj"},
+		},
+		{
+			name:   "every name",
+			member: discord.Member{User: discord.User{ID: 2, Username: "kate", GlobalName: &globalName}, Nick: &nick},
+			want:   memberNames{ID: 2, Username: "kate", GlobalName: "kate b", Nick: "katie"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := memberNamesOf(tt.member); got != tt.want {
+				t.Errorf("memberNamesOf = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexGuild(t *testing.T) {
+	ctx := context.Background()
+	b := &Bot{Queries: testQueries(t)}
+	const guildID = snowflake.ID(10)
+
+	nick := "Katie"
+	first := []discord.Member{
+		{User: discord.User{ID: 1, Username: "rj"}},
+		{User: discord.User{ID: 2, Username: "kate"}, Nick: &nick},
+	}
+	if err := b.indexGuild(ctx, guildID, first); err != nil {
+		t.Fatal(err)
+	}
+	// indexing again drops the members that left
+	second := []discord.Member{{User: discord.User{ID: 2, Username: "kate"}, Nick: &nick}}
+	if err := b.indexGuild(ctx, guildID, second); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		found snowflake.ID
+	}{
+		{name: "katie", found: 2},
+		{name: "kate", found: 2},
+		{name: "rj"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := b.Queries.FindGuildMember(ctx, sqlc.FindGuildMemberParams{GuildID: guildID, Name: tt.name})
+			if tt.found == 0 {
+				if !errors.Is(err, sql.ErrNoRows) {
+					t.Errorf("found %v, %v, want no member", id, err)
+				}
+				return
+			}
+			if err != nil || id != tt.found {
+				t.Errorf("found %v, %v, want %v", id, err, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"first.fm/internal/metrics"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+var (
+	commandsTotal = metrics.NewCounter("firstfm_commands_total",
+		"Commands executed, by path and status (ok or error).", "command", "status")
+	commandDuration = metrics.NewHistogram("firstfm_command_duration_seconds",
+		"Time commands took, by path.", metrics.DefaultBuckets, "command")
+)
+
+// observeCommand records a command run by Dispatcher.
+func observeCommand(path string, err error, took time.Duration) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	commandsTotal.Inc(path, status)
+	commandDuration.Observe(took.Seconds(), path)
+}
+
+// cacheStats is implemented by every cache.Cache.
+type cacheStats interface {
+	Stats() (hits, misses uint64, size int)
+}
+
+// registerMetrics adds the metrics read from the bot when scraped.
+func (b *Bot) registerMetrics() {
+	caches := b.caches()
+	each := func(emit func(float64, ...string), value func(hits, misses uint64, size int) float64) {
+		for _, name := range slices.Sorted(maps.Keys(caches)) {
+			emit(value(caches[name].Stats()), name)
+		}
+	}
+
+	metrics.NewFunc(metrics.KindCounter, "firstfm_cache_hits_total", "Cache lookups that found a value.", []string{"cache"},
+		func(emit func(float64, ...string)) {
+			each(emit, func(hits, _ uint64, _ int) float64 { return float64(hits) })
+		})
+	metrics.NewFunc(metrics.KindCounter, "firstfm_cache_misses_total", "Cache lookups that found nothing.", []string{"cache"},
+		func(emit func(float64, ...string)) {
+			each(emit, func(_, misses uint64, _ int) float64 { return float64(misses) })
+		})
+	metrics.NewFunc(metrics.KindGauge, "firstfm_cache_entries", "Entries held by each cache.", []string{"cache"},
+		func(emit func(float64, ...string)) {
+			each(emit, func(_, _ uint64, size int) float64 { return float64(size) })
+		})
+	metrics.NewFunc(metrics.KindGauge, "firstfm_inflight_interactions", "Interaction handlers running.", nil,
+		func(emit func(float64, ...string)) { emit(float64(b.Inflight())) })
+	metrics.NewFunc(metrics.KindGauge, "firstfm_guilds", "Guilds the bot is in.", nil,
+		func(emit func(float64, ...string)) { emit(float64(b.Client.Caches.GuildsLen())) })
+	metrics.NewFunc(metrics.KindGauge, "firstfm_shard_ready", "Whether each shard of this process is ready.", []string{"shard"},
+		func(emit func(float64, ...string)) {
+			for _, shard := range b.ShardStats() {
+				ready := 0.0
+				if shard.Status == gateway.StatusReady {
+					ready = 1
+				}
+				emit(ready, strconv.Itoa(shard.ID))
+			}
+		})
+	metrics.NewFunc(metrics.KindGauge, "firstfm_shard_latency_seconds", "Heartbeat latency of each shard of this process.", []string{"shard"},
+		func(emit func(float64, ...string)) {
+			for _, shard := range b.ShardStats() {
+				emit(shard.Latency.Seconds(), strconv.Itoa(shard.ID))
+			}
+		})
+}
+
+// caches returns the caches of the bot by metric label.
+func (b *Bot) caches() map[string]cacheStats {
+	return map[string]cacheStats{
+		"lastfm_users":  b.LastFM.User.InfoCache,
+		"searches":      b.LastFM.Search.ResultCache,
+		"chart_artists": b.LastFM.Chart.ArtistsCache,
+		"chart_tags":    b.LastFM.Chart.TagsCache,
+		"chart_tracks":  b.LastFM.Chart.TracksCache,
+		"cooldowns":     b.Registry.limits.cooldowns,
+		"paginators":    b.Registry.paginators,
+	}
+}
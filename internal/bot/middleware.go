@@ -0,0 +1,178 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"first.fm/internal/emojis"
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+)
+
+// Middleware wraps a CommandHandler with cross-cutting behavior.
+type Middleware func(next CommandHandler) CommandHandler
+
+// Chain wraps handler with middleware. The first middleware is the
+// outermost one and runs first.
+func Chain(handler CommandHandler, middleware ...Middleware) CommandHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// WithMiddleware wraps the command's handlers with the given middleware, in
+// addition to the middleware every command gets from the Registry.
+func WithMiddleware(middleware ...Middleware) CommandOption {
+	return func(c *commandConfig) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+var (
+	ErrNotRegistered = i18n.NewError("errors.not_registered")
+	ErrGuildOnly     = i18n.NewError("errors.guild_only")
+	ErrOwnerOnly     = i18n.NewError("errors.owner_only")
+
+	ErrMissingPermissions = i18n.NewError("errors.missing_permissions")
+)
+
+// Logging logs every command with how long it took, and failed commands with
+// their error.
+func Logging(next CommandHandler) CommandHandler {
+	return func(ctx *CommandContext) error {
+		start := time.Now()
+		err := next(ctx)
+		if err != nil {
+			ctx.Log.Warnw("command failed", logger.F{"err": err.Error()})
+		}
+		ctx.Log.Infow("executed command", logger.F{"time": time.Since(start)})
+		return err
+	}
+}
+
+// ReplyErrors shows the error of a failed command to the invoking user. The
+// error is still returned to outer middleware.
+func ReplyErrors(next CommandHandler) CommandHandler {
+	return func(ctx *CommandContext) error {
+		err := next(ctx)
+		if err != nil {
+			ctx.replyError(err)
+		}
+		return err
+	}
+}
+
+// Recover turns a panicking handler into a failed command. The panic is
+// logged with its stack trace and kept in the bot's crash log, and the user
+// is shown its correlation ID.
+func Recover(next CommandHandler) CommandHandler {
+	return func(ctx *CommandContext) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = ctx.reportPanic(r, ctx.Path, ctx.ApplicationCommandInteraction, ctx.request)
+			}
+		}()
+		return next(ctx)
+	}
+}
+
+// RequireRegistration fails the command when the invoking user hasn't linked
+// a Last.fm account.
+func RequireRegistration(next CommandHandler) CommandHandler {
+	return func(ctx *CommandContext) error {
+		if _, err := ctx.Queries.GetUserByID(ctx.Ctx, ctx.User().ID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotRegistered
+			}
+			return err
+		}
+		return next(ctx)
+	}
+}
+
+// GuildOnly fails the command when it isn't run in a server.
+func GuildOnly(next CommandHandler) CommandHandler {
+	return func(ctx *CommandContext) error {
+		if ctx.GuildID() == nil {
+			return ErrGuildOnly
+		}
+		return next(ctx)
+	}
+}
+
+// OwnerOnly fails the command when the invoking user isn't a bot owner.
+func OwnerOnly(next CommandHandler) CommandHandler {
+	return func(ctx *CommandContext) error {
+		if !slices.Contains(ctx.Owners, ctx.User().ID) {
+			return ErrOwnerOnly
+		}
+		return next(ctx)
+	}
+}
+
+// RequirePermissions fails the command when the invoking member lacks any of
+// perms. Discord only enforces the default member permissions of a command
+// for slash commands, not for commands run by message.
+func RequirePermissions(perms ...discord.Permissions) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *CommandContext) error {
+			member := ctx.Member()
+			if member == nil {
+				return ErrGuildOnly
+			}
+			if !member.Permissions.Has(perms...) {
+				return ErrMissingPermissions
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// AutoDefer defers the response before the handler runs, so slow handlers
+// don't hit the 3 second acknowledgement deadline.
+func AutoDefer(ephemeral bool) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *CommandContext) error {
+			if err := ctx.DeferCreateMessage(ephemeral); err != nil {
+				return err
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// DeferCreateMessage acknowledges the interaction with a loading state. It is
+// a no-op when the interaction was already deferred, so handlers can defer
+// themselves even when AutoDefer is in use.
+func (ctx *CommandContext) DeferCreateMessage(ephemeral bool, opts ...rest.RequestOpt) error {
+	if ctx.deferred {
+		return nil
+	}
+	if err := ctx.CommandEvent.DeferCreateMessage(ephemeral, opts...); err != nil {
+		return err
+	}
+	ctx.deferred = true
+	return nil
+}
+
+// replyError sends err to the invoking user, editing the deferred response
+// when there is one.
+func (ctx *CommandContext) replyError(err error) {
+	content := fmt.Sprintf("%s %s", emojis.EmojiCross, i18n.Localize(ctx.Lang, err))
+	if ctx.deferred {
+		_, _ = ctx.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+			SetContent(content).
+			Build())
+		return
+	}
+	_ = ctx.CreateMessage(discord.NewMessageCreateBuilder().
+		SetContent(content).
+		SetEphemeral(true).
+		Build())
+}
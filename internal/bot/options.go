@@ -0,0 +1,388 @@
+package bot
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"first.fm/internal/i18n"
+	"first.fm/internal/lastfm"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// optionField is a field of an option struct, see OptionsOf.
+type optionField struct {
+	index        int
+	typ          reflect.Type
+	name         string
+	description  string
+	required     bool
+	autocomplete bool
+	def          string
+	min, max     *float64
+}
+
+// LastFMUser is a user option resolved to a Last.fm username without
+// fetching the profile, for handlers that only need the name.
+type LastFMUser struct {
+	Name string
+}
+
+var (
+	periodType     = reflect.TypeFor[lastfm.Period]()
+	userInfoType   = reflect.TypeFor[*lastfm.UserInfo]()
+	lastFMUserType = reflect.TypeFor[LastFMUser]()
+	userType       = reflect.TypeFor[discord.User]()
+)
+
+// optionFields caches the parsed fields of option structs by type.
+var optionFields sync.Map
+
+func fieldsOf(t reflect.Type) []optionField {
+	if cached, ok := optionFields.Load(t); ok {
+		return cached.([]optionField)
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("bot: options must be a struct, got %s", t))
+	}
+
+	var fields []optionField
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		name, ok := sf.Tag.Lookup("option")
+		if !ok {
+			continue
+		}
+
+		f := optionField{
+			index:        i,
+			typ:          sf.Type,
+			name:         name,
+			description:  sf.Tag.Get("description"),
+			required:     sf.Tag.Get("required") == "true",
+			autocomplete: sf.Tag.Get("autocomplete") == "true",
+			def:          sf.Tag.Get("default"),
+			min:          parseBound(t, sf, "min"),
+			max:          parseBound(t, sf, "max"),
+		}
+		switch f.typ {
+		case periodType, userInfoType, lastFMUserType, userType:
+		default:
+			switch f.typ.Kind() {
+			case reflect.String, reflect.Int, reflect.Float64, reflect.Bool:
+			default:
+				panic(fmt.Sprintf("bot: unsupported option type %s of %s.%s", f.typ, t, sf.Name))
+			}
+		}
+		fields = append(fields, f)
+	}
+
+	optionFields.Store(t, fields)
+	return fields
+}
+
+func parseBound(t reflect.Type, sf reflect.StructField, tag string) *float64 {
+	raw, ok := sf.Tag.Lookup(tag)
+	if !ok {
+		return nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		panic(fmt.Sprintf("bot: invalid %s tag %q on %s.%s", tag, raw, t, sf.Name))
+	}
+	return &v
+}
+
+// OptionsOf generates the options of a command from its option struct, which
+// declares the options as tagged fields:
+//
+//	type topOptions struct {
+//		User   *lastfm.UserInfo `option:"user" description:"user to get top artists from"`
+//		Period lastfm.Period    `option:"period" description:"time period" default:"overall"`
+//		Limit  int              `option:"limit" description:"how many to show" min:"1" max:"25" default:"10"`
+//	}
+//
+// Supported field types are string, int, float64, bool, lastfm.Period (a
+// string option with a choice per period), discord.User (a user option),
+// *lastfm.UserInfo (a string option accepting mentions, IDs and Last.fm
+// usernames, resolved like GetLastFMUser and defaulting to the invoking user)
+// and LastFMUser (the same option resolved like LastFMUsername, which skips
+// the Last.fm request when the handler only needs the username).
+//
+// Tags:
+//   - option: the option name, required for the field to be an option
+//   - description: the option description
+//   - required: "true" makes the option required
+//   - default: the value used when the option is omitted
+//   - min, max: the value range of int and float64 options and the length
+//     range of string options
+//   - autocomplete: "true" marks the option as autocompleted
+//
+// It panics when the struct is invalid, which is a programming error.
+func OptionsOf[T any]() []discord.ApplicationCommandOption {
+	fields := slices.Clone(fieldsOf(reflect.TypeFor[T]()))
+	// Discord rejects optional options before required ones
+	slices.SortStableFunc(fields, func(a, b optionField) int {
+		switch {
+		case a.required && !b.required:
+			return -1
+		case !a.required && b.required:
+			return 1
+		}
+		return 0
+	})
+
+	options := make([]discord.ApplicationCommandOption, 0, len(fields))
+	for _, f := range fields {
+		options = append(options, f.option())
+	}
+	return options
+}
+
+func (f optionField) option() discord.ApplicationCommandOption {
+	switch f.typ {
+	case periodType:
+		choices := make([]discord.ApplicationCommandOptionChoiceString, 0, len(lastfm.Periods))
+		for _, p := range lastfm.Periods {
+			key := "periods." + string(p)
+			choices = append(choices, discord.ApplicationCommandOptionChoiceString{
+				Name:              i18n.T(i18n.Default, key),
+				NameLocalizations: i18n.Localizations(key),
+				Value:             string(p),
+			})
+		}
+		return discord.ApplicationCommandOptionString{
+			Name:        f.name,
+			Description: f.description,
+			Required:    f.required,
+			Choices:     choices,
+		}
+	case userInfoType, lastFMUserType:
+		return discord.ApplicationCommandOptionString{
+			Name:         f.name,
+			Description:  f.description,
+			Required:     f.required,
+			Autocomplete: f.autocomplete,
+		}
+	case userType:
+		return discord.ApplicationCommandOptionUser{
+			Name:        f.name,
+			Description: f.description,
+			Required:    f.required,
+		}
+	}
+
+	switch f.typ.Kind() {
+	case reflect.String:
+		return discord.ApplicationCommandOptionString{
+			Name:         f.name,
+			Description:  f.description,
+			Required:     f.required,
+			Autocomplete: f.autocomplete,
+			MinLength:    intBound(f.min),
+			MaxLength:    intBound(f.max),
+		}
+	case reflect.Int:
+		return discord.ApplicationCommandOptionInt{
+			Name:         f.name,
+			Description:  f.description,
+			Required:     f.required,
+			Autocomplete: f.autocomplete,
+			MinValue:     intBound(f.min),
+			MaxValue:     intBound(f.max),
+		}
+	case reflect.Float64:
+		return discord.ApplicationCommandOptionFloat{
+			Name:         f.name,
+			Description:  f.description,
+			Required:     f.required,
+			Autocomplete: f.autocomplete,
+			MinValue:     f.min,
+			MaxValue:     f.max,
+		}
+	default:
+		return discord.ApplicationCommandOptionBool{
+			Name:        f.name,
+			Description: f.description,
+			Required:    f.required,
+		}
+	}
+}
+
+func intBound(v *float64) *int {
+	if v == nil {
+		return nil
+	}
+	i := int(*v)
+	return &i
+}
+
+// OptionError is returned when an option fails validation.
+type OptionError struct {
+	Option string
+	Key    string
+	Args   []any
+}
+
+func (e *OptionError) Error() string {
+	return e.Localize(i18n.Default)
+}
+
+// Localize returns the validation message in locale.
+func (e *OptionError) Localize(locale discord.Locale) string {
+	return i18n.T(locale, e.Key, append([]any{e.Option}, e.Args...)...)
+}
+
+// ParseOptions fills the option struct pointed to by dst from the invoked
+// command and validates it.
+func (ctx *CommandContext) ParseOptions(dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("bot: ParseOptions needs a pointer to a struct, got %T", dst))
+	}
+	v = v.Elem()
+
+	data, _ := ctx.Data.(discord.SlashCommandInteractionData)
+	for _, f := range fieldsOf(v.Type()) {
+		if err := ctx.parseOption(data, f, v.Field(f.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ctx *CommandContext) parseOption(data discord.SlashCommandInteractionData, f optionField, dst reflect.Value) error {
+	option, present := data.Option(f.name)
+
+	// user options default to the invoking or targeted user
+	switch f.typ {
+	case userInfoType:
+		user, err := ctx.GetLastFMUser(f.name)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(user))
+		return nil
+	case lastFMUserType:
+		name, err := ctx.LastFMUsername(f.name)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(LastFMUser{Name: name}))
+		return nil
+	}
+
+	if !present && f.def == "" {
+		if f.required {
+			return &OptionError{Option: f.name, Key: "options.required"}
+		}
+		return nil
+	}
+
+	switch f.typ {
+	case periodType:
+		raw := f.def
+		if present {
+			raw = option.String()
+		}
+		period, ok := lastfm.ParsePeriod(raw)
+		if !ok {
+			return &OptionError{Option: f.name, Key: "options.period"}
+		}
+		dst.Set(reflect.ValueOf(period))
+		return nil
+	case userType:
+		if user, ok := data.OptUser(f.name); ok {
+			dst.Set(reflect.ValueOf(user))
+		}
+		return nil
+	}
+
+	switch f.typ.Kind() {
+	case reflect.String:
+		s := f.def
+		if present {
+			s = strings.TrimSpace(option.String())
+		}
+		if n := float64(utf8.RuneCountInString(s)); (f.min != nil && n < *f.min) || (f.max != nil && n > *f.max) {
+			return &OptionError{Option: f.name, Key: "options.length", Args: []any{bound(f.min, 0), bound(f.max, 6000)}}
+		}
+		dst.SetString(s)
+	case reflect.Int:
+		var n int
+		if present {
+			n = option.Int()
+		} else {
+			parsed, err := strconv.Atoi(f.def)
+			if err != nil {
+				panic(fmt.Sprintf("bot: invalid default %q for option %s", f.def, f.name))
+			}
+			n = parsed
+		}
+		if (f.min != nil && float64(n) < *f.min) || (f.max != nil && float64(n) > *f.max) {
+			return f.rangeError()
+		}
+		dst.SetInt(int64(n))
+	case reflect.Float64:
+		var n float64
+		if present {
+			n = option.Float()
+		} else {
+			parsed, err := strconv.ParseFloat(f.def, 64)
+			if err != nil {
+				panic(fmt.Sprintf("bot: invalid default %q for option %s", f.def, f.name))
+			}
+			n = parsed
+		}
+		if (f.min != nil && n < *f.min) || (f.max != nil && n > *f.max) {
+			return f.rangeError()
+		}
+		dst.SetFloat(n)
+	case reflect.Bool:
+		b := f.def == "true"
+		if present {
+			b = option.Bool()
+		}
+		dst.SetBool(b)
+	}
+	return nil
+}
+
+// rangeError returns the error of a number outside the option's range,
+// naming only the bounds that are set.
+func (f optionField) rangeError() *OptionError {
+	switch {
+	case f.min == nil:
+		return &OptionError{Option: f.name, Key: "options.max", Args: []any{bound(f.max, 0)}}
+	case f.max == nil:
+		return &OptionError{Option: f.name, Key: "options.min", Args: []any{bound(f.min, 0)}}
+	}
+	return &OptionError{Option: f.name, Key: "options.range", Args: []any{bound(f.min, 0), bound(f.max, 0)}}
+}
+
+// bound formats a range bound for validation messages.
+func bound(v *float64, fallback float64) string {
+	if v == nil {
+		return strconv.FormatFloat(fallback, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+// Handle adapts a handler that takes an option struct. The options are
+// parsed and validated before the handler runs, and validation errors are
+// shown to the user.
+func Handle[T any](handler func(*CommandContext, T) error) CommandHandler {
+	// parse the struct at registration so invalid tags fail fast
+	fieldsOf(reflect.TypeFor[T]())
+	return func(ctx *CommandContext) error {
+		var opts T
+		if err := ctx.ParseOptions(&opts); err != nil {
+			return err
+		}
+		return handler(ctx, opts)
+	}
+}
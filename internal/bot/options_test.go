@@ -0,0 +1,212 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"first.fm/internal/lastfm"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	disgohandler "github.com/disgoorg/disgo/handler"
+)
+
+type testOptions struct {
+	Query   string        `option:"query" description:"what to search" min:"2" max:"10"`
+	Limit   int           `option:"limit" description:"how many" min:"1" max:"25" default:"10"`
+	Offset  int           `option:"offset" description:"how many to skip" min:"0"`
+	Weight  float64       `option:"weight" description:"how much" max:"1.5"`
+	Private bool          `option:"private" description:"hide the reply"`
+	Period  lastfm.Period `option:"period" description:"time period" default:"overall"`
+	Name    string        `option:"name" description:"who" required:"true" autocomplete:"true"`
+	Ignored string
+}
+
+func TestOptionsOf(t *testing.T) {
+	options := OptionsOf[testOptions]()
+
+	var names []string
+	for _, o := range options {
+		names = append(names, o.OptionName())
+	}
+	// required options come first, the rest keep their order
+	want := []string{"name", "query", "limit", "offset", "weight", "private", "period"}
+	if len(names) != len(want) {
+		t.Fatalf("options %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("options %v, want %v", names, want)
+		}
+	}
+
+	name := options[0].(discord.ApplicationCommandOptionString)
+	if !name.Required || !name.Autocomplete {
+		t.Errorf("name = %+v, want required and autocompleted", name)
+	}
+	query := options[1].(discord.ApplicationCommandOptionString)
+	if query.MinLength == nil || *query.MinLength != 2 || query.MaxLength == nil || *query.MaxLength != 10 {
+		t.Errorf("query length = %v..%v, want 2..10", query.MinLength, query.MaxLength)
+	}
+	offset := options[3].(discord.ApplicationCommandOptionInt)
+	if offset.MinValue == nil || *offset.MinValue != 0 || offset.MaxValue != nil {
+		t.Errorf("offset range = %v..%v, want 0..", offset.MinValue, offset.MaxValue)
+	}
+	if _, ok := options[5].(discord.ApplicationCommandOptionBool); !ok {
+		t.Errorf("private is %T, want a bool option", options[5])
+	}
+	period := options[6].(discord.ApplicationCommandOptionString)
+	if len(period.Choices) != len(lastfm.Periods) {
+		t.Errorf("period has %d choices, want %d", len(period.Choices), len(lastfm.Periods))
+	}
+}
+
+func TestOptionsOfPanics(t *testing.T) {
+	type unsupported struct {
+		Tags []string `option:"tags" description:"tags"`
+	}
+	type badBound struct {
+		Limit int `option:"limit" description:"how many" max:"many"`
+	}
+	tests := map[string]func(){
+		"unsupported type": func() { OptionsOf[unsupported]() },
+		"invalid bound":    func() { OptionsOf[badBound]() },
+	}
+	for name, f := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("didn't panic")
+				}
+			}()
+			f()
+		})
+	}
+}
+
+// slashContext returns the context of a /test command invoked with options.
+func slashContext(t *testing.T, options ...map[string]any) *CommandContext {
+	t.Helper()
+	raw, err := json.Marshal(map[string]any{
+		"id":             "1",
+		"application_id": "1",
+		"type":           discord.InteractionTypeApplicationCommand,
+		"token":          "token",
+		"version":        1,
+		"user":           map[string]any{"id": "2001", "username": "rj"},
+		"data": map[string]any{
+			"id":      "1",
+			"name":    "test",
+			"type":    discord.ApplicationCommandTypeSlash,
+			"options": options,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var interaction discord.ApplicationCommandInteraction
+	if err := json.Unmarshal(raw, &interaction); err != nil {
+		t.Fatal(err)
+	}
+	return &CommandContext{CommandEvent: &disgohandler.CommandEvent{
+		ApplicationCommandInteractionCreate: &events.ApplicationCommandInteractionCreate{ApplicationCommandInteraction: interaction},
+	}}
+}
+
+func option(name string, typ discord.ApplicationCommandOptionType, value any) map[string]any {
+	return map[string]any{"name": name, "type": typ, "value": value}
+}
+
+func TestParseOptions(t *testing.T) {
+	name := option("name", discord.ApplicationCommandOptionTypeString, "rj")
+
+	tests := []struct {
+		name    string
+		options []map[string]any
+		want    testOptions
+		key     string
+		args    []any
+	}{
+		{
+			name:    "defaults",
+			options: []map[string]any{name},
+			want:    testOptions{Name: "rj", Limit: 10, Period: lastfm.PeriodOverall},
+		},
+		{
+			name: "values",
+			options: []map[string]any{
+				name,
+				option("query", discord.ApplicationCommandOptionTypeString, "  radiohead "),
+				option("limit", discord.ApplicationCommandOptionTypeInt, 25),
+				option("offset", discord.ApplicationCommandOptionTypeInt, 5),
+				option("weight", discord.ApplicationCommandOptionTypeFloat, 0.5),
+				option("private", discord.ApplicationCommandOptionTypeBool, true),
+				option("period", discord.ApplicationCommandOptionTypeString, "7day"),
+			},
+			want: testOptions{Name: "rj", Query: "radiohead", Limit: 25, Offset: 5, Weight: 0.5, Private: true, Period: lastfm.PeriodWeek},
+		},
+		{
+			name:    "required",
+			options: nil,
+			key:     "options.required",
+		},
+		{
+			name:    "string length counts characters",
+			options: []map[string]any{name, option("query", discord.ApplicationCommandOptionTypeString, "ééééééééééé")},
+			key:     "options.length",
+			args:    []any{"2", "10"},
+		},
+		{
+			name:    "both bounds",
+			options: []map[string]any{name, option("limit", discord.ApplicationCommandOptionTypeInt, 30)},
+			key:     "options.range",
+			args:    []any{"1", "25"},
+		},
+		{
+			name:    "lower bound only",
+			options: []map[string]any{name, option("offset", discord.ApplicationCommandOptionTypeInt, -1)},
+			key:     "options.min",
+			args:    []any{"0"},
+		},
+		{
+			name:    "upper bound only",
+			options: []map[string]any{name, option("weight", discord.ApplicationCommandOptionTypeFloat, 2)},
+			key:     "options.max",
+			args:    []any{"1.5"},
+		},
+		{
+			name:    "unknown period",
+			options: []map[string]any{name, option("period", discord.ApplicationCommandOptionTypeString, "forever")},
+			key:     "options.period",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testOptions
+			err := slashContext(t, tt.options...).ParseOptions(&got)
+
+			if tt.key == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.want {
+					t.Errorf("parsed %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+
+			var optErr *OptionError
+			if !errors.As(err, &optErr) {
+				t.Fatalf("error = %v, want an OptionError", err)
+			}
+			if optErr.Key != tt.key || len(optErr.Args) != len(tt.args) {
+				t.Fatalf("error = %s %v, want %s %v", optErr.Key, optErr.Args, tt.key, tt.args)
+			}
+			for i := range tt.args {
+				if optErr.Args[i] != tt.args[i] {
+					t.Errorf("error args = %v, want %v", optErr.Args, tt.args)
+				}
+			}
+		})
+	}
+}
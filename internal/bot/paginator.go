@@ -0,0 +1,244 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// DefaultPaginatorTimeout is how long a paginator accepts input when its
+// Timeout is unset.
+const DefaultPaginatorTimeout = 5 * time.Minute
+
+const paginatorNamespace = "paginator"
+
+// ListPageSize is how many lines a paginator made by NewListPaginator shows
+// per page.
+const ListPageSize = 10
+
+// PageFunc renders the page at the given zero based index.
+type PageFunc func(page int) (discord.ContainerComponent, error)
+
+// Paginator shows list-style output one page at a time with first, previous,
+// next and last buttons and a jump-to-page modal. Only the user who ran the
+// command can use it, and its buttons are disabled after Timeout.
+type Paginator struct {
+	// Pages is the total number of pages.
+	Pages int
+	// Render produces the container shown for a page.
+	Render PageFunc
+	// Timeout is how long the paginator accepts input. It can't exceed the
+	// 15 minute lifetime of the interaction token.
+	Timeout time.Duration
+}
+
+// NewListPaginator returns a paginator showing lines ListPageSize at a time
+// below header, as top lists and leaderboards do.
+func NewListPaginator(header string, lines []string) Paginator {
+	return Paginator{
+		Pages: (len(lines) + ListPageSize - 1) / ListPageSize,
+		Render: func(page int) (discord.ContainerComponent, error) {
+			start := page * ListPageSize
+			end := min(start+ListPageSize, len(lines))
+			return discord.NewContainer(
+				discord.NewTextDisplay(header),
+				discord.NewTextDisplay(strings.Join(lines[start:end], "\n")),
+			), nil
+		},
+	}
+}
+
+type paginatorState struct {
+	Paginator
+	mu    sync.Mutex
+	id    string
+	page  int
+	owner snowflake.ID
+}
+
+// registerPaginator routes the paginator buttons and jump modal.
+func (r *Registry) registerPaginator() {
+	for _, action := range []string{"first", "prev", "next", "last"} {
+		r.components[paginatorNamespace+":"+action] = onPaginatorPage
+	}
+	r.components[paginatorNamespace+":jump"] = onPaginatorJump
+	r.modals[paginatorNamespace+":jump"] = onPaginatorJumpSubmit
+}
+
+// Paginate sends the first page of p as the response to a deferred
+// interaction and handles its buttons until it times out.
+func (ctx *CommandContext) Paginate(p Paginator) error {
+	if p.Pages < 1 {
+		return i18n.NewError("paginator.empty")
+	}
+	if p.Timeout <= 0 || p.Timeout > DefaultComponentTTL {
+		p.Timeout = DefaultPaginatorTimeout
+	}
+
+	state := &paginatorState{
+		Paginator: p,
+		id:        strconv.FormatUint(uint64(ctx.ID()), 36),
+		owner:     ctx.User().ID,
+	}
+
+	update, err := state.render(false)
+	if err != nil {
+		return err
+	}
+	if _, err = ctx.UpdateInteractionResponse(update); err != nil {
+		return err
+	}
+
+	paginators := ctx.Registry.paginators
+	paginators.SetWithTTL(state.id, state, p.Timeout)
+
+	// the event's client answers commands run by message, see prefixRest
+	client, appID, token, log := ctx.CommandEvent.Client(), ctx.ApplicationID(), ctx.Token(), ctx.Log
+	time.AfterFunc(p.Timeout, func() {
+		paginators.Delete(state.id)
+
+		state.mu.Lock()
+		update, err := state.render(true)
+		state.mu.Unlock()
+		if err != nil {
+			return
+		}
+		if _, err = client.Rest.UpdateInteractionResponse(appID, token, update); err != nil {
+			log.Debugw("failed to disable paginator", logger.F{"err": err.Error()})
+		}
+	})
+
+	return nil
+}
+
+// render renders the current page together with the navigation buttons.
+// Callers must hold mu once the paginator is shared.
+func (s *paginatorState) render(disabled bool) (discord.MessageUpdate, error) {
+	container, err := s.Render(s.page)
+	if err != nil {
+		return discord.MessageUpdate{}, err
+	}
+
+	last := s.Pages - 1
+	var encodeErr error
+	id := func(action string, state ...string) string {
+		id, err := NewCustomID(paginatorNamespace, action, append([]string{s.id}, state...)...).
+			WithOwner(s.owner).
+			WithTTL(s.Timeout).
+			Encode()
+		if err != nil {
+			encodeErr = err
+		}
+		return id
+	}
+	page := func(p int) string { return strconv.Itoa(p) }
+
+	buttons := discord.NewActionRow(
+		discord.NewSecondaryButton("«", id("first", page(0))).
+			WithDisabled(disabled || s.page == 0),
+		discord.NewSecondaryButton("‹", id("prev", page(s.page-1))).
+			WithDisabled(disabled || s.page == 0),
+		discord.NewSecondaryButton(fmt.Sprintf("%d/%d", s.page+1, s.Pages), id("jump")).
+			WithDisabled(disabled || s.Pages == 1),
+		discord.NewSecondaryButton("›", id("next", page(s.page+1))).
+			WithDisabled(disabled || s.page == last),
+		discord.NewSecondaryButton("»", id("last", page(last))).
+			WithDisabled(disabled || s.page == last),
+	)
+	if encodeErr != nil {
+		return discord.MessageUpdate{}, encodeErr
+	}
+
+	return discord.NewMessageUpdateBuilder().
+		SetIsComponentsV2(true).
+		SetComponents(container, buttons).
+		Build(), nil
+}
+
+// show moves the paginator to page and renders it.
+func (s *paginatorState) show(page int) (discord.MessageUpdate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.page = max(0, min(page, s.Pages-1))
+	return s.render(false)
+}
+
+func lookupPaginator(r *Registry, id CustomID) (*paginatorState, error) {
+	if len(id.State) == 0 {
+		return nil, ErrInvalidCustomID
+	}
+	state, ok := r.paginators.Get(id.State[0])
+	if !ok {
+		return nil, i18n.NewError("paginator.expired")
+	}
+	return state, nil
+}
+
+func onPaginatorPage(ctx *ComponentContext) error {
+	state, err := lookupPaginator(ctx.Registry, ctx.ID)
+	if err != nil {
+		return err
+	}
+	if len(ctx.ID.State) < 2 {
+		return ErrInvalidCustomID
+	}
+	page, err := strconv.Atoi(ctx.ID.State[1])
+	if err != nil {
+		return ErrInvalidCustomID
+	}
+
+	update, err := state.show(page)
+	if err != nil {
+		return err
+	}
+	return ctx.UpdateMessage(update)
+}
+
+func onPaginatorJump(ctx *ComponentContext) error {
+	state, err := lookupPaginator(ctx.Registry, ctx.ID)
+	if err != nil {
+		return err
+	}
+
+	id, err := NewCustomID(paginatorNamespace, "jump", state.id).
+		WithOwner(state.owner).
+		WithTTL(state.Timeout).
+		Encode()
+	if err != nil {
+		return err
+	}
+	return ctx.Modal(discord.NewModalCreateBuilder().
+		SetCustomID(id).
+		SetTitle(ctx.T("paginator.jump.title")).
+		AddLabel(ctx.T("paginator.jump.label", state.Pages), discord.NewShortTextInput("page").
+			WithRequired(true).
+			WithMaxLength(len(strconv.Itoa(state.Pages))).
+			WithPlaceholder(strconv.Itoa(state.page+1))).
+		Build())
+}
+
+func onPaginatorJumpSubmit(ctx *ModalContext) error {
+	state, err := lookupPaginator(ctx.Registry, ctx.ID)
+	if err != nil {
+		return err
+	}
+
+	page, err := strconv.Atoi(strings.TrimSpace(ctx.Data.Text("page")))
+	if err != nil || page < 1 || page > state.Pages {
+		return i18n.NewError("paginator.jump.range", state.Pages)
+	}
+
+	update, err := state.show(page - 1)
+	if err != nil {
+		return err
+	}
+	return ctx.UpdateMessage(update)
+}
@@ -0,0 +1,400 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"first.fm/internal/emojis"
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"first.fm/internal/persistence/sqlc"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// MaxPrefixLength is the longest message prefix a server may use.
+const MaxPrefixLength = 5
+
+// ErrInvalidPrefix is returned for prefixes ValidPrefix rejects.
+var ErrInvalidPrefix = i18n.NewError("prefix.invalid", MaxPrefixLength)
+
+// prefixTokenPrefix starts the interaction tokens of commands run by message.
+const prefixTokenPrefix = "prefix."
+
+// prefixes holds the message prefix of every guild that set one. A guild is
+// only updated by the process running its shard, see ShardConfig.
+type prefixes struct {
+	mu      sync.RWMutex
+	byGuild map[snowflake.ID]string
+}
+
+// ValidPrefix reports whether prefix may be used to run commands: up to
+// MaxPrefixLength characters without spaces.
+func ValidPrefix(prefix string) bool {
+	n := len([]rune(prefix))
+	return n >= 1 && n <= MaxPrefixLength && !strings.ContainsFunc(prefix, unicode.IsSpace)
+}
+
+// MessagePrefix returns the prefix commands can be run with by message in a
+// guild, or "" when the guild didn't set one.
+func (b *Bot) MessagePrefix(guildID snowflake.ID) string {
+	b.prefixes.mu.RLock()
+	defer b.prefixes.mu.RUnlock()
+	return b.prefixes.byGuild[guildID]
+}
+
+// SetMessagePrefix stores the prefix of a guild. An empty prefix turns
+// commands by message off in the guild.
+func (b *Bot) SetMessagePrefix(ctx context.Context, guildID snowflake.ID, prefix string) error {
+	var err error
+	if prefix == "" {
+		err = b.Queries.DeleteGuildPrefix(ctx, guildID)
+	} else {
+		err = b.Queries.SetGuildPrefix(ctx, sqlc.SetGuildPrefixParams{GuildID: guildID, Prefix: prefix})
+	}
+	if err != nil {
+		return err
+	}
+
+	b.prefixes.mu.Lock()
+	defer b.prefixes.mu.Unlock()
+	if b.prefixes.byGuild == nil {
+		b.prefixes.byGuild = map[snowflake.ID]string{}
+	}
+	if prefix == "" {
+		delete(b.prefixes.byGuild, guildID)
+	} else {
+		b.prefixes.byGuild[guildID] = prefix
+	}
+	return nil
+}
+
+// loadPrefixes reads the prefixes of every guild.
+func (b *Bot) loadPrefixes(ctx context.Context) error {
+	rows, err := b.Queries.ListGuildPrefixes(ctx)
+	if err != nil {
+		return err
+	}
+
+	byGuild := make(map[snowflake.ID]string, len(rows))
+	for _, row := range rows {
+		byGuild[row.GuildID] = row.Prefix
+	}
+	b.prefixes.mu.Lock()
+	defer b.prefixes.mu.Unlock()
+	b.prefixes.byGuild = byGuild
+	return nil
+}
+
+// onGuildMessage runs the slash command a message invokes with the prefix of
+// its guild, such as ".fm" or "!top artists 7day". Messages naming no command
+// are ignored, so other bots can share the prefix.
+func (b *Bot) onGuildMessage(e *events.GuildMessageCreate) {
+	message := e.Message
+	if message.Author.Bot || message.WebhookID != nil {
+		return
+	}
+	prefix := b.MessagePrefix(e.GuildID)
+	if prefix == "" || len(message.Content) <= len(prefix) || !strings.EqualFold(message.Content[:len(prefix)], prefix) {
+		return
+	}
+	args := splitArgs(message.Content[len(prefix):])
+	if len(args) == 0 {
+		return
+	}
+	meta, ok := b.slashCommand(args[0])
+	if !ok {
+		return
+	}
+
+	locale := b.guildLocale(e.GuildID)
+	data, err := b.prefixData(meta, args[1:], message.Mentions)
+	if err != nil {
+		b.replyPrefixError(message, locale, err)
+		return
+	}
+	token := prefixTokenPrefix + message.ID.String()
+	interaction, err := b.prefixInteraction(e.GuildID, message, locale, data, token)
+	if err != nil {
+		logger.Warnw("failed to build prefix command", logger.F{"guild": e.GuildID, "message": message.ID, "err": err.Error()})
+		return
+	}
+
+	// the command's event gets a copy of the client whose REST client
+	// answers its token by replying to the message
+	client := *e.Client()
+	replies := newPrefixRest(client.Rest, token, message.ChannelID, message.ID)
+	client.Rest = replies
+	Dispatcher(b)(&events.ApplicationCommandInteractionCreate{
+		GenericEvent:                  events.NewGenericEvent(&client, e.SequenceNumber(), e.ShardID()),
+		ApplicationCommandInteraction: interaction,
+		Respond:                       replies.reply.respond,
+	})
+}
+
+// slashCommand returns the registered slash command with the given name.
+func (b *Bot) slashCommand(name string) (discord.SlashCommandCreate, bool) {
+	for _, c := range b.Registry.Commands() {
+		if slash, ok := c.(discord.SlashCommandCreate); ok && strings.EqualFold(slash.Name, name) {
+			return slash, true
+		}
+	}
+	return discord.SlashCommandCreate{}, false
+}
+
+// guildLocale returns the locale a guild set for its community, which stands
+// in for the locale of members running commands by message.
+func (b *Bot) guildLocale(guildID snowflake.ID) discord.Locale {
+	if guild, ok := b.Client.Caches.Guild(guildID); ok && guild.PreferredLocale != "" {
+		return discord.Locale(guild.PreferredLocale)
+	}
+	return i18n.Default
+}
+
+// prefixData maps the arguments of a command message onto the options of
+// meta and returns the interaction data Discord would send for the same
+// slash command. Subcommands come first, then options by position or as
+// name:value. Text left over after the last option is added to it when it
+// is a string, so only text before other options needs quotes.
+func (b *Bot) prefixData(meta discord.SlashCommandCreate, args []string, mentions []discord.User) (map[string]any, error) {
+	data := map[string]any{
+		"id":   0,
+		"name": meta.Name,
+		"type": discord.ApplicationCommandTypeSlash,
+	}
+
+	leaf, options := data, meta.Options
+	for {
+		names := subcommandNames(options)
+		if len(names) == 0 {
+			break
+		}
+		if len(args) == 0 || !slices.Contains(names, strings.ToLower(args[0])) {
+			return nil, i18n.NewError("prefix.subcommand", strings.Join(names, ", "))
+		}
+		name := strings.ToLower(args[0])
+		args = args[1:]
+
+		i := slices.IndexFunc(options, func(o discord.ApplicationCommandOption) bool { return o.OptionName() == name })
+		sub := map[string]any{"name": name, "type": options[i].Type()}
+		leaf["options"] = []map[string]any{sub}
+		leaf, options = sub, subcommandOptions(options[i])
+	}
+
+	named := map[string]string{}
+	var positional []string
+	for _, arg := range args {
+		if name, value, ok := strings.Cut(arg, ":"); ok && slices.ContainsFunc(options, func(o discord.ApplicationCommandOption) bool {
+			return o.OptionName() == strings.ToLower(name)
+		}) {
+			named[strings.ToLower(name)] = value
+			continue
+		}
+		positional = append(positional, arg)
+	}
+
+	var (
+		values []map[string]any
+		users  = map[string]discord.User{}
+		last   map[string]any
+	)
+	for _, option := range options {
+		raw, ok := named[option.OptionName()]
+		if !ok && len(positional) > 0 {
+			raw, positional, ok = positional[0], positional[1:], true
+		}
+		if !ok {
+			continue
+		}
+
+		value, err := b.prefixValue(option, raw, mentions, users)
+		if err != nil {
+			return nil, err
+		}
+		last = map[string]any{"name": option.OptionName(), "type": option.Type(), "value": value}
+		values = append(values, last)
+	}
+	if len(positional) > 0 {
+		if last == nil || last["type"] != discord.ApplicationCommandOptionTypeString {
+			return nil, i18n.NewError("prefix.too_many")
+		}
+		last["value"] = strings.Join(append([]string{last["value"].(string)}, positional...), " ")
+	}
+
+	if len(values) > 0 {
+		leaf["options"] = values
+	}
+	if len(users) > 0 {
+		data["resolved"] = map[string]any{"users": users}
+	}
+	return data, nil
+}
+
+// prefixValue converts an argument to the value of option, resolving users
+// from the mentions of the message or by ID.
+func (b *Bot) prefixValue(option discord.ApplicationCommandOption, raw string, mentions []discord.User, users map[string]discord.User) (any, error) {
+	name := option.OptionName()
+	switch option.Type() {
+	case discord.ApplicationCommandOptionTypeString:
+		return raw, nil
+	case discord.ApplicationCommandOptionTypeInt:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, i18n.NewError("prefix.number", name)
+		}
+		return n, nil
+	case discord.ApplicationCommandOptionTypeFloat:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, i18n.NewError("prefix.number", name)
+		}
+		return n, nil
+	case discord.ApplicationCommandOptionTypeBool:
+		switch strings.ToLower(raw) {
+		case "true", "yes", "on", "1":
+			return true, nil
+		case "false", "no", "off", "0":
+			return false, nil
+		}
+		return nil, i18n.NewError("prefix.bool", name)
+	case discord.ApplicationCommandOptionTypeUser:
+		id, ok := parseUserID(raw)
+		if !ok {
+			return nil, i18n.NewError("prefix.user", name)
+		}
+		i := slices.IndexFunc(mentions, func(u discord.User) bool { return u.ID == id })
+		if i >= 0 {
+			users[id.String()] = mentions[i]
+			return id, nil
+		}
+		user, err := b.Client.Rest.GetUser(id)
+		if err != nil {
+			return nil, i18n.NewError("prefix.user", name)
+		}
+		users[id.String()] = *user
+		return id, nil
+	default:
+		return nil, i18n.NewError("prefix.unsupported", name)
+	}
+}
+
+// subcommandNames returns the names of the subcommands and groups among
+// options.
+func subcommandNames(options []discord.ApplicationCommandOption) []string {
+	var names []string
+	for _, o := range options {
+		switch o.(type) {
+		case discord.ApplicationCommandOptionSubCommand, discord.ApplicationCommandOptionSubCommandGroup:
+			names = append(names, o.OptionName())
+		}
+	}
+	return names
+}
+
+// subcommandOptions returns the options of a subcommand or the subcommands
+// of a group.
+func subcommandOptions(option discord.ApplicationCommandOption) []discord.ApplicationCommandOption {
+	switch o := option.(type) {
+	case discord.ApplicationCommandOptionSubCommand:
+		return o.Options
+	case discord.ApplicationCommandOptionSubCommandGroup:
+		options := make([]discord.ApplicationCommandOption, len(o.Options))
+		for i, sub := range o.Options {
+			options[i] = sub
+		}
+		return options
+	}
+	return nil
+}
+
+// prefixInteraction returns the interaction Discord would send if the author
+// of message ran the slash command described by data. Its ID is the message
+// ID, so it expires like an interaction created with the message.
+func (b *Bot) prefixInteraction(guildID snowflake.ID, message discord.Message, locale discord.Locale, data map[string]any, token string) (discord.ApplicationCommandInteraction, error) {
+	member := discord.Member{User: message.Author, GuildID: guildID}
+	if message.Member != nil {
+		member = *message.Member
+		member.User = message.Author
+		member.GuildID = guildID
+	}
+
+	channelType := discord.ChannelTypeGuildText
+	if channel, ok := b.Client.Caches.Channel(message.ChannelID); ok {
+		channelType = channel.Type()
+	}
+
+	raw, err := json.Marshal(map[string]any{
+		"id":             message.ID,
+		"application_id": b.Client.ApplicationID,
+		"type":           discord.InteractionTypeApplicationCommand,
+		"token":          token,
+		"version":        1,
+		"locale":         locale,
+		"guild_id":       guildID,
+		"guild_locale":   locale,
+		"channel":        map[string]any{"id": message.ChannelID, "type": channelType},
+		"channel_id":     message.ChannelID,
+		"member": discord.ResolvedMember{
+			Member:      member,
+			Permissions: b.Client.Caches.MemberPermissions(member),
+		},
+		"data": data,
+	})
+	if err != nil {
+		return discord.ApplicationCommandInteraction{}, err
+	}
+
+	var interaction discord.ApplicationCommandInteraction
+	err = json.Unmarshal(raw, &interaction)
+	return interaction, err
+}
+
+// replyPrefixError replies to a command message that couldn't be parsed.
+func (b *Bot) replyPrefixError(message discord.Message, locale discord.Locale, err error) {
+	_, err = b.Client.Rest.CreateMessage(message.ChannelID, discord.MessageCreate{
+		Content:          fmt.Sprintf("%s %s", emojis.EmojiCross, i18n.Localize(locale, err)),
+		MessageReference: &discord.MessageReference{MessageID: &message.ID},
+		AllowedMentions:  &discord.AllowedMentions{},
+	})
+	if err != nil {
+		logger.Debugw("failed to reply to command message", logger.F{"message": message.ID, "err": err.Error()})
+	}
+}
+
+// splitArgs splits the arguments of a command message at spaces. Text in
+// double quotes is kept together.
+func splitArgs(s string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		quoted  bool
+		started bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+	return args
+}
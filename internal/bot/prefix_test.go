@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"first.fm/internal/i18n"
+	"github.com/disgoorg/disgo/discord"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "   ", want: nil},
+		{in: "fm", want: []string{"fm"}},
+		{in: " top  artists\t7day ", want: []string{"top", "artists", "7day"}},
+		{in: `plays "the national" rj`, want: []string{"plays", "the national", "rj"}},
+		{in: `artist:"sigur rós"`, want: []string{"artist:sigur rós"}},
+		{in: `say ""`, want: []string{"say", ""}},
+		{in: `"unterminated quote`, want: []string{"unterminated quote"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := splitArgs(tt.in); !slices.Equal(got, tt.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+var prefixCommand = discord.SlashCommandCreate{
+	Name: "test",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionSubCommand{
+			Name: "search",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{Name: "query", Required: true},
+				discord.ApplicationCommandOptionInt{Name: "limit"},
+				discord.ApplicationCommandOptionBool{Name: "private"},
+			},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name: "count",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionInt{Name: "limit"},
+				discord.ApplicationCommandOptionFloat{Name: "weight"},
+			},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name: "say",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionInt{Name: "times"},
+				discord.ApplicationCommandOptionString{Name: "text"},
+			},
+		},
+	},
+}
+
+func TestPrefixData(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+		key  string
+	}{
+		{
+			name: "subcommand without options",
+			args: []string{"COUNT"},
+			want: `{"id":0,"name":"test","options":[{"name":"count","type":1}],"type":1}`,
+		},
+		{
+			name: "positional",
+			args: []string{"search", "radiohead", "5", "yes"},
+			want: `{"id":0,"name":"test","options":[{"name":"search","options":[{"name":"query","type":3,"value":"radiohead"},{"name":"limit","type":4,"value":5},{"name":"private","type":5,"value":true}],"type":1}],"type":1}`,
+		},
+		{
+			name: "named",
+			args: []string{"search", "Limit:3", "query:reckoner"},
+			want: `{"id":0,"name":"test","options":[{"name":"search","options":[{"name":"query","type":3,"value":"reckoner"},{"name":"limit","type":4,"value":3}],"type":1}],"type":1}`,
+		},
+		{
+			name: "text left over joins the last string",
+			args: []string{"say", "2", "hello", "there"},
+			want: `{"id":0,"name":"test","options":[{"name":"say","options":[{"name":"times","type":4,"value":2},{"name":"text","type":3,"value":"hello there"}],"type":1}],"type":1}`,
+		},
+		{
+			name: "unknown names are positional",
+			args: []string{"search", "artist:radiohead"},
+			want: `{"id":0,"name":"test","options":[{"name":"search","options":[{"name":"query","type":3,"value":"artist:radiohead"}],"type":1}],"type":1}`,
+		},
+		{
+			name: "missing subcommand",
+			args: nil,
+			key:  "prefix.subcommand",
+		},
+		{
+			name: "unknown subcommand",
+			args: []string{"albums"},
+			key:  "prefix.subcommand",
+		},
+		{
+			name: "invalid number",
+			args: []string{"count", "five"},
+			key:  "prefix.number",
+		},
+		{
+			name: "invalid float",
+			args: []string{"count", "weight:heavy"},
+			key:  "prefix.number",
+		},
+		{
+			name: "invalid bool",
+			args: []string{"search", "radiohead", "5", "maybe"},
+			key:  "prefix.bool",
+		},
+		{
+			name: "too many arguments",
+			args: []string{"count", "1", "2", "3"},
+			key:  "prefix.too_many",
+		},
+	}
+	b := &Bot{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := b.prefixData(prefixCommand, tt.args, nil)
+			if tt.key != "" {
+				if !errors.Is(err, i18n.NewError(tt.key)) {
+					t.Fatalf("error = %v, want %s", err, tt.key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := json.Marshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("data = %s\nwant   %s", got, tt.want)
+			}
+
+			// the data has to be accepted as an interaction
+			var parsed discord.SlashCommandInteractionData
+			if err := json.Unmarshal(got, &parsed); err != nil {
+				t.Errorf("data doesn't unmarshal: %v", err)
+			}
+		})
+	}
+}
+
+func TestPrefixDataMention(t *testing.T) {
+	mentioned := discord.User{ID: 2002, Username: "kate"}
+	b := &Bot{}
+	data, err := b.prefixData(discord.SlashCommandCreate{
+		Name:    "profile",
+		Options: []discord.ApplicationCommandOption{discord.ApplicationCommandOptionUser{Name: "user"}},
+	}, []string{"<@2002>"}, []discord.User{mentioned})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed discord.SlashCommandInteractionData
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if user := parsed.User("user"); user.ID != mentioned.ID || user.Username != mentioned.Username {
+		t.Errorf("user = %+v, want %+v", user, mentioned)
+	}
+}
@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"errors"
+	"sync"
+
+	"first.fm/internal/i18n"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+var (
+	// ErrSlashOnly is returned when a command run by message responds in a
+	// way only interactions support, such as opening a modal.
+	ErrSlashOnly = i18n.NewError("prefix.slash_only")
+
+	errNoResponse = errors.New("no response sent yet")
+)
+
+// prefixRest stands in for the interaction webhooks of a command run by
+// message. Responses and follow-ups sent with its token are posted as replies
+// to the message instead, so handlers run unchanged. Every other request goes
+// to the wrapped client. It is only handed to the command's event, the
+// bot's client keeps the plain REST client.
+type prefixRest struct {
+	rest.Rest
+	token string
+	reply *prefixReply
+}
+
+func newPrefixRest(r rest.Rest, token string, channelID, messageID snowflake.ID) *prefixRest {
+	return &prefixRest{
+		Rest:  r,
+		token: token,
+		reply: &prefixReply{rest: r, channelID: channelID, messageID: messageID},
+	}
+}
+
+// replyTo returns the reply answering token, or nil for the tokens of real
+// interactions.
+func (p *prefixRest) replyTo(token string) *prefixReply {
+	if token != p.token {
+		return nil
+	}
+	return p.reply
+}
+
+// prefixReply answers a command message. The first response replies to it
+// and later edits of the response edit that reply.
+type prefixReply struct {
+	rest      rest.Rest
+	channelID snowflake.ID
+	messageID snowflake.ID
+
+	mu       sync.Mutex
+	response *discord.Message
+}
+
+// respond is the interaction responder of the command. Deferring shows the
+// typing indicator until the response is sent.
+func (r *prefixReply) respond(typ discord.InteractionResponseType, data discord.InteractionResponseData, opts ...rest.RequestOpt) error {
+	switch typ {
+	case discord.InteractionResponseTypeCreateMessage:
+		create, _ := data.(discord.MessageCreate)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		message, err := r.create(create, opts...)
+		if err == nil {
+			r.response = message
+		}
+		return err
+	case discord.InteractionResponseTypeDeferredCreateMessage:
+		return r.rest.SendTyping(r.channelID, opts...)
+	default:
+		return ErrSlashOnly
+	}
+}
+
+// create replies to the command message. Replies can't be ephemeral and
+// don't ping anyone unless the handler allows it.
+func (r *prefixReply) create(create discord.MessageCreate, opts ...rest.RequestOpt) (*discord.Message, error) {
+	create.Flags = create.Flags.Remove(discord.MessageFlagEphemeral)
+	create.MessageReference = &discord.MessageReference{MessageID: &r.messageID}
+	if create.AllowedMentions == nil {
+		create.AllowedMentions = &discord.AllowedMentions{}
+	}
+	return r.rest.CreateMessage(r.channelID, create, opts...)
+}
+
+// createFromUpdate turns the edit of a deferred response into the message it
+// creates.
+func createFromUpdate(update discord.MessageUpdate) discord.MessageCreate {
+	create := discord.MessageCreate{
+		Files:           update.Files,
+		AllowedMentions: update.AllowedMentions,
+	}
+	if update.Content != nil {
+		create.Content = *update.Content
+	}
+	if update.Embeds != nil {
+		create.Embeds = *update.Embeds
+	}
+	if update.Components != nil {
+		create.Components = *update.Components
+	}
+	if update.Flags != nil {
+		create.Flags = *update.Flags
+	}
+	return create
+}
+
+func (p *prefixRest) GetInteractionResponse(applicationID snowflake.ID, token string, opts ...rest.RequestOpt) (*discord.Message, error) {
+	reply := p.replyTo(token)
+	if reply == nil {
+		return p.Rest.GetInteractionResponse(applicationID, token, opts...)
+	}
+	reply.mu.Lock()
+	defer reply.mu.Unlock()
+	if reply.response == nil {
+		return nil, errNoResponse
+	}
+	return reply.response, nil
+}
+
+func (p *prefixRest) UpdateInteractionResponse(applicationID snowflake.ID, token string, update discord.MessageUpdate, opts ...rest.RequestOpt) (*discord.Message, error) {
+	reply := p.replyTo(token)
+	if reply == nil {
+		return p.Rest.UpdateInteractionResponse(applicationID, token, update, opts...)
+	}
+	reply.mu.Lock()
+	defer reply.mu.Unlock()
+
+	var (
+		message *discord.Message
+		err     error
+	)
+	if reply.response == nil {
+		message, err = reply.create(createFromUpdate(update), opts...)
+	} else {
+		message, err = reply.rest.UpdateMessage(reply.channelID, reply.response.ID, update, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reply.response = message
+	return message, nil
+}
+
+func (p *prefixRest) DeleteInteractionResponse(applicationID snowflake.ID, token string, opts ...rest.RequestOpt) error {
+	reply := p.replyTo(token)
+	if reply == nil {
+		return p.Rest.DeleteInteractionResponse(applicationID, token, opts...)
+	}
+	reply.mu.Lock()
+	defer reply.mu.Unlock()
+	if reply.response == nil {
+		return nil
+	}
+	if err := reply.rest.DeleteMessage(reply.channelID, reply.response.ID, opts...); err != nil {
+		return err
+	}
+	reply.response = nil
+	return nil
+}
+
+func (p *prefixRest) GetFollowupMessage(applicationID snowflake.ID, token string, messageID snowflake.ID, opts ...rest.RequestOpt) (*discord.Message, error) {
+	reply := p.replyTo(token)
+	if reply == nil {
+		return p.Rest.GetFollowupMessage(applicationID, token, messageID, opts...)
+	}
+	return reply.rest.GetMessage(reply.channelID, messageID, opts...)
+}
+
+func (p *prefixRest) CreateFollowupMessage(applicationID snowflake.ID, token string, create discord.MessageCreate, opts ...rest.RequestOpt) (*discord.Message, error) {
+	reply := p.replyTo(token)
+	if reply == nil {
+		return p.Rest.CreateFollowupMessage(applicationID, token, create, opts...)
+	}
+	return reply.create(create, opts...)
+}
+
+func (p *prefixRest) UpdateFollowupMessage(applicationID snowflake.ID, token string, messageID snowflake.ID, update discord.MessageUpdate, opts ...rest.RequestOpt) (*discord.Message, error) {
+	reply := p.replyTo(token)
+	if reply == nil {
+		return p.Rest.UpdateFollowupMessage(applicationID, token, messageID, update, opts...)
+	}
+	return reply.rest.UpdateMessage(reply.channelID, messageID, update, opts...)
+}
+
+func (p *prefixRest) DeleteFollowupMessage(applicationID snowflake.ID, token string, messageID snowflake.ID, opts ...rest.RequestOpt) error {
+	reply := p.replyTo(token)
+	if reply == nil {
+		return p.Rest.DeleteFollowupMessage(applicationID, token, messageID, opts...)
+	}
+	return reply.rest.DeleteMessage(reply.channelID, messageID, opts...)
+}
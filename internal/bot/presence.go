@@ -0,0 +1,192 @@
+package bot
+
+import (
+	"context"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"first.fm/internal/config"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// PresenceConfig configures the status shown under the bot's name.
+type PresenceConfig struct {
+	// Default is shown when there are no templates or none can be filled in.
+	Default string
+	// Templates are shown in turn, see config.Presence.
+	Templates []string
+	// Interval is how long each template is shown.
+	Interval time.Duration
+	// Sample is how many opted-in users are polled on each refresh.
+	Sample int
+}
+
+var placeholderPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// rotatePresence shows the presence templates in turn until ctx is done,
+// starting one interval after the default status is set on ready. Values are
+// refreshed before every change, so opted-in users are polled once per
+// interval through the Last.fm rate limiter shared with commands.
+func (b *Bot) rotatePresence(ctx context.Context) {
+	if len(b.Presence.Templates) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(b.Presence.Interval)
+	defer ticker.Stop()
+
+	next := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		status, shown := b.nextPresence(ctx, next)
+		next = (shown + 1) % len(b.Presence.Templates)
+		b.setStatus(ctx, status)
+	}
+}
+
+// currentStatus returns the custom status last set, or the default one.
+func (b *Bot) currentStatus() string {
+	if status := b.status.Load(); status != nil {
+		return *status
+	}
+	return b.Presence.Default
+}
+
+// setStatus shows status on every shard run by this process. Shards that
+// aren't ready get it when they are.
+func (b *Bot) setStatus(ctx context.Context, status string) {
+	b.status.Store(&status)
+	for _, shard := range b.shards() {
+		if shard.Status() != gateway.StatusReady {
+			continue
+		}
+		err := b.Client.SetPresenceForShard(ctx, shard.ShardID(), gateway.WithCustomActivity(status))
+		if err != nil && ctx.Err() == nil {
+			logger.Warnw("failed to update presence", logger.F{"shard": shard.ShardID(), "err": err.Error()})
+		}
+	}
+}
+
+// nextPresence renders the first template from start on that can be filled
+// in. It returns the status and the index of the template shown, or the
+// default status and start when none can.
+func (b *Bot) nextPresence(ctx context.Context, start int) (string, int) {
+	values := b.presenceValues(ctx)
+	templates := b.Presence.Templates
+	for i := range templates {
+		n := (start + i) % len(templates)
+		if status, ok := renderPresence(templates[n], values); ok {
+			return status, n
+		}
+	}
+	return b.Presence.Default, start
+}
+
+// presenceValues returns the values of the placeholders known right now.
+// Placeholders that need Last.fm are only looked up when a template uses
+// them.
+func (b *Bot) presenceValues(ctx context.Context) map[string]string {
+	values := map[string]string{}
+	if servers, err := b.serverCount(ctx); err == nil {
+		values["servers"] = strconv.Itoa(servers)
+	} else {
+		logger.Warnw("failed to count servers", logger.F{"err": err.Error()})
+	}
+	if count, err := b.Queries.CountUsers(ctx); err == nil {
+		values["users"] = strconv.FormatInt(count, 10)
+	} else {
+		logger.Warnw("failed to count users", logger.F{"err": err.Error()})
+	}
+
+	if !b.presenceNeeds("top_artist", "listener", "track", "artist") {
+		return values
+	}
+	names, err := b.Queries.ListPresenceUsers(ctx, int64(b.Presence.Sample))
+	if err != nil {
+		logger.Warnw("failed to list presence users", logger.F{"err": err.Error()})
+		return values
+	}
+
+	plays := map[string]int{}
+	var top string
+	var listening []map[string]string
+	for _, name := range names {
+		if ctx.Err() != nil {
+			break
+		}
+		recent, err := b.LastFM.User.RecentTrack(ctx, name)
+		if err != nil || recent.Track == nil {
+			continue
+		}
+		track := recent.Track
+		if artist := track.Artist.Name; artist != "" {
+			plays[artist]++
+			if plays[artist] > plays[top] {
+				top = artist
+			}
+		}
+		if track.NowPlaying {
+			listening = append(listening, map[string]string{
+				"listener": name,
+				"track":    track.Title,
+				"artist":   track.Artist.Name,
+			})
+		}
+	}
+
+	if top != "" {
+		values["top_artist"] = top
+	}
+	if len(listening) > 0 {
+		for k, v := range listening[rand.IntN(len(listening))] {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// serverCount returns how many guilds the bot is in. When other processes
+// run some of the shards, the guilds are counted from the member index, which
+// every process writes to, instead of the guild cache of this one.
+func (b *Bot) serverCount(ctx context.Context) (int, error) {
+	if !b.Sharding.partial() {
+		return b.Client.Caches.GuildsLen(), nil
+	}
+	count, err := b.Queries.CountGuilds(ctx)
+	return int(count), err
+}
+
+// presenceNeeds reports whether any template uses one of the placeholders.
+func (b *Bot) presenceNeeds(placeholders ...string) bool {
+	for _, t := range b.Presence.Templates {
+		for _, p := range placeholders {
+			if strings.Contains(t, "{"+p+"}") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// renderPresence fills in the placeholders of template. It reports false when
+// one of them has no value.
+func renderPresence(template string, values map[string]string) (string, bool) {
+	ok := true
+	status := placeholderPattern.ReplaceAllStringFunc(template, func(m string) string {
+		v, found := values[m[1:len(m)-1]]
+		if !found || v == "" {
+			ok = false
+		}
+		return v
+	})
+	return Ellipsize(status, config.MaxPresenceLength), ok
+}
@@ -0,0 +1,301 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"first.fm/internal/cache"
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"first.fm/internal/persistence/sqlc"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// CooldownScope decides who shares a cooldown.
+type CooldownScope int
+
+const (
+	// CooldownUser gives every user their own cooldown.
+	CooldownUser CooldownScope = iota
+	// CooldownGuild shares the cooldown between everyone in a server. Outside
+	// of servers it falls back to the user.
+	CooldownGuild
+	// CooldownChannel shares the cooldown between everyone in a channel.
+	CooldownChannel
+)
+
+func (s CooldownScope) String() string {
+	switch s {
+	case CooldownGuild:
+		return "guild"
+	case CooldownChannel:
+		return "channel"
+	default:
+		return "user"
+	}
+}
+
+// CooldownError is returned when a command is used again before its cooldown
+// ran out.
+type CooldownError struct {
+	Scope CooldownScope
+	Wait  time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return e.Localize(i18n.Default)
+}
+
+// Localize returns the "try again in Ns" message in locale.
+func (e *CooldownError) Localize(locale discord.Locale) string {
+	seconds := max(1, int(math.Ceil(e.Wait.Seconds())))
+	return i18n.T(locale, "errors.cooldown."+e.Scope.String(), seconds)
+}
+
+// ErrBusy is returned when a command is already running as many times as its
+// concurrency cap allows.
+var ErrBusy = i18n.NewError("errors.busy")
+
+// limiter holds the cooldown and concurrency state of every command of a
+// registry. Cooldowns of a user and concurrency slots are kept in the
+// database, so they hold across every process sharing it: a user's
+// interactions can arrive on any shard. Guild and channel cooldowns stay in
+// memory, since the interactions of a guild always arrive on the shard, and
+// so the process, that owns it.
+type limiter struct {
+	mu sync.Mutex
+	// cooldowns maps "path:scope:id" to when the cooldown ends.
+	cooldowns *cache.Cache[string, time.Time]
+	// running counts the capped commands running in this process by path.
+	running map[string]int
+
+	throttled atomic.Uint64
+	busy      atomic.Uint64
+}
+
+func newLimiter() *limiter {
+	return &limiter{
+		cooldowns: cache.New[string, time.Time](time.Minute, 50000),
+		running:   map[string]int{},
+	}
+}
+
+// LimiterStats describes the current cooldown and concurrency state.
+type LimiterStats struct {
+	// Cooldowns is the number of guild and channel cooldowns tracked by this
+	// process, expired ones included until the cache cleans them up.
+	Cooldowns int
+	// Throttled is how many commands were rejected by a cooldown.
+	Throttled uint64
+	// Busy is how many commands were rejected by a concurrency cap.
+	Busy uint64
+	// Running is the number of capped commands currently running in this
+	// process by path.
+	Running map[string]int
+}
+
+// LimiterStats returns the current cooldown and concurrency state of the
+// registry's commands.
+func (r *Registry) LimiterStats() LimiterStats {
+	l := r.limits
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	running := make(map[string]int, len(l.running))
+	for path, n := range l.running {
+		running[path] = n
+	}
+	return LimiterStats{
+		Cooldowns: l.cooldowns.Size(),
+		Throttled: l.throttled.Load(),
+		Busy:      l.busy.Load(),
+		Running:   running,
+	}
+}
+
+// take starts the cooldown for key unless it is still running, in which case
+// it returns how long is left.
+func (l *limiter) take(key string, d time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if until, ok := l.cooldowns.Get(key); ok && now.Before(until) {
+		l.throttled.Add(1)
+		return until.Sub(now)
+	}
+	l.cooldowns.SetWithTTL(key, now.Add(d), d)
+	return 0
+}
+
+// takeShared is take for the cooldowns kept in the database. Database errors
+// let the command run, so a database hiccup doesn't take every command down.
+func (l *limiter) takeShared(ctx *CommandContext, key string, d time.Duration) time.Duration {
+	now := time.Now()
+	taken, err := ctx.Queries.TakeCooldown(ctx.Ctx, sqlc.TakeCooldownParams{
+		Key:       key,
+		ExpiresAt: now.Add(d).UnixMilli(),
+		Now:       now.UnixMilli(),
+	})
+	if err != nil {
+		ctx.Log.Warnw("failed to take cooldown", logger.F{"key": key, "err": err.Error()})
+		return 0
+	}
+	if taken > 0 {
+		return 0
+	}
+
+	until, err := ctx.Queries.GetCooldown(ctx.Ctx, key)
+	if err != nil {
+		ctx.Log.Warnw("failed to get cooldown", logger.F{"key": key, "err": err.Error()})
+		return 0
+	}
+	wait := time.UnixMilli(until).Sub(now)
+	if wait > 0 {
+		l.throttled.Add(1)
+	}
+	return wait
+}
+
+// acquire reserves one of n slots for the command in the database. A slot is
+// held until release or, should the process die first, until the
+// interaction expires. Database errors let the command run.
+func (l *limiter) acquire(ctx *CommandContext, n int) bool {
+	now := time.Now()
+	acquired, err := ctx.Queries.AcquireSlot(ctx.Ctx, sqlc.AcquireSlotParams{
+		Path:      ctx.Path,
+		Holder:    ctx.RequestID,
+		ExpiresAt: now.Add(InteractionTokenLifetime).UnixMilli(),
+		Now:       now.UnixMilli(),
+		Slots:     int64(n),
+	})
+	if err != nil {
+		ctx.Log.Warnw("failed to acquire concurrency slot", logger.F{"err": err.Error()})
+	} else if acquired == 0 {
+		l.busy.Add(1)
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running[ctx.Path]++
+	return true
+}
+
+func (l *limiter) release(ctx *CommandContext) {
+	// the request may have been cancelled while the command ran
+	err := ctx.Queries.ReleaseSlot(context.WithoutCancel(ctx.Ctx), sqlc.ReleaseSlotParams{Path: ctx.Path, Holder: ctx.RequestID})
+	if err != nil {
+		ctx.Log.Warnw("failed to release concurrency slot", logger.F{"err": err.Error()})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running[ctx.Path]--; l.running[ctx.Path] <= 0 {
+		delete(l.running, ctx.Path)
+	}
+}
+
+// purgeLimits deletes expired cooldowns and concurrency slots from the
+// database every minute until ctx is done.
+func (b *Bot) purgeLimits(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now().UnixMilli()
+		if err := b.Queries.DeleteExpiredCooldowns(ctx, now); err != nil {
+			logger.Warnw("failed to delete expired cooldowns", logger.F{"err": err.Error()})
+		}
+		if err := b.Queries.DeleteExpiredSlots(ctx, now); err != nil {
+			logger.Warnw("failed to delete expired concurrency slots", logger.F{"err": err.Error()})
+		}
+	}
+}
+
+// Cooldown limits the command to one use per d within scope.
+func Cooldown(scope CooldownScope, d time.Duration) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *CommandContext) error {
+			subject := cooldownSubject(ctx, scope)
+			key := fmt.Sprintf("%s:%s:%s", ctx.Path, scope, subject)
+
+			var wait time.Duration
+			if subject == ctx.User().ID {
+				wait = ctx.Registry.limits.takeShared(ctx, key, d)
+			} else {
+				wait = ctx.Registry.limits.take(key, d)
+			}
+			if wait > 0 {
+				return &CooldownError{Scope: scope, Wait: wait}
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// Concurrency caps how many instances of the command run at once across all
+// users and processes. Further uses fail with ErrBusy until one finishes.
+func Concurrency(n int) Middleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx *CommandContext) error {
+			limits := ctx.Registry.limits
+			if !limits.acquire(ctx, n) {
+				return ErrBusy
+			}
+			defer limits.release(ctx)
+			return next(ctx)
+		}
+	}
+}
+
+// WithCooldown limits the command to one use per d within scope. Cooldowns
+// run before the command's other middleware, so rejected uses reply
+// ephemerally even when the command defers.
+func WithCooldown(scope CooldownScope, d time.Duration) CommandOption {
+	return func(c *commandConfig) {
+		c.limits = append(c.limits, Cooldown(scope, d))
+	}
+}
+
+// WithConcurrency caps how many instances of the command run at once. Use it
+// for expensive commands that make many Last.fm requests.
+func WithConcurrency(n int) CommandOption {
+	return func(c *commandConfig) {
+		c.limits = append(c.limits, Concurrency(n))
+	}
+}
+
+// cooldownSubject returns the ID that shares a cooldown within scope.
+func cooldownSubject(ctx *CommandContext, scope CooldownScope) snowflake.ID {
+	switch scope {
+	case CooldownGuild:
+		if guildID := ctx.GuildID(); guildID != nil {
+			return *guildID
+		}
+	case CooldownChannel:
+		if channelID := interactionChannelID(ctx.ApplicationCommandInteraction); channelID != 0 {
+			return channelID
+		}
+	}
+	return ctx.User().ID
+}
+
+// interactionChannelID returns the ID of the channel the interaction was
+// created in, or 0 when Discord didn't send it.
+func interactionChannelID(i discord.Interaction) snowflake.ID {
+	if channel := i.Channel(); channel.MessageChannel != nil {
+		return channel.ID()
+	}
+	return 0
+}
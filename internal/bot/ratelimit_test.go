@@ -0,0 +1,137 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"first.fm/internal/persistence/sqlc"
+	disgohandler "github.com/disgoorg/disgo/handler"
+)
+
+// testQueries opens a database private to the test, shared by everything
+// opening the same name like the processes of a sharded bot.
+func testQueries(t *testing.T) *sqlc.Queries {
+	t.Helper()
+	queries, db, err := sqlc.Start(context.Background(), "file:"+strings.ReplaceAll(t.Name(), "/", "_")+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		queries.Close()
+		db.Close()
+	})
+	return queries
+}
+
+// limitContext returns the context of a command run by the process using
+// queries.
+func limitContext(q *sqlc.Queries, path, requestID string) *CommandContext {
+	return &CommandContext{
+		CommandEvent: &disgohandler.CommandEvent{Ctx: context.Background()},
+		Bot:          &Bot{Queries: q},
+		request:      &request{RequestID: requestID, Log: logger.Default()},
+		Path:         path,
+	}
+}
+
+func TestLimiterTake(t *testing.T) {
+	l := newLimiter()
+	defer l.cooldowns.Close()
+
+	steps := []struct {
+		key       string
+		d         time.Duration
+		throttled bool
+	}{
+		{"/fm:guild:1", time.Minute, false},
+		{"/fm:guild:1", time.Minute, true},
+		{"/fm:guild:2", time.Minute, false},
+		{"/top:guild:1", time.Minute, false},
+		{"/fm:channel:1", time.Millisecond, false},
+	}
+	for i, s := range steps {
+		if wait := l.take(s.key, s.d); (wait > 0) != s.throttled {
+			t.Errorf("step %d: take(%q) waits %s, want throttled %v", i, s.key, wait, s.throttled)
+		}
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if wait := l.take("/fm:channel:1", time.Minute); wait > 0 {
+		t.Errorf("expired cooldown still waits %s", wait)
+	}
+	if got := l.throttled.Load(); got != 1 {
+		t.Errorf("throttled %d uses, want 1", got)
+	}
+}
+
+func TestSharedCooldownAcrossProcesses(t *testing.T) {
+	q := testQueries(t)
+	first, second := newLimiter(), newLimiter()
+	defer first.cooldowns.Close()
+	defer second.cooldowns.Close()
+
+	if wait := first.takeShared(limitContext(q, "/fm", "a"), "/fm:user:1", time.Minute); wait > 0 {
+		t.Fatalf("first use waits %s", wait)
+	}
+	wait := second.takeShared(limitContext(q, "/fm", "b"), "/fm:user:1", time.Minute)
+	if wait <= 0 || wait > time.Minute {
+		t.Errorf("use on another process waits %s, want the rest of the cooldown", wait)
+	}
+	if wait := second.takeShared(limitContext(q, "/fm", "c"), "/fm:user:2", time.Minute); wait > 0 {
+		t.Errorf("another user waits %s", wait)
+	}
+}
+
+func TestConcurrencyAcrossProcesses(t *testing.T) {
+	q := testQueries(t)
+	first, second := newLimiter(), newLimiter()
+	defer first.cooldowns.Close()
+	defer second.cooldowns.Close()
+
+	running := limitContext(q, "/leaderboard", "a")
+	if !first.acquire(running, 1) {
+		t.Fatal("free slot wasn't acquired")
+	}
+	if second.acquire(limitContext(q, "/leaderboard", "b"), 1) {
+		t.Fatal("another process acquired a taken slot")
+	}
+	if !second.acquire(limitContext(q, "/top", "c"), 1) {
+		t.Fatal("a slot of another command wasn't acquired")
+	}
+	if got := first.running["/leaderboard"]; got != 1 {
+		t.Errorf("first process runs %d, want 1", got)
+	}
+
+	first.release(running)
+	if _, ok := first.running["/leaderboard"]; ok {
+		t.Error("released command still counted as running")
+	}
+	if !second.acquire(limitContext(q, "/leaderboard", "b"), 1) {
+		t.Error("released slot wasn't acquired by another process")
+	}
+	if got := second.busy.Load(); got != 1 {
+		t.Errorf("busy %d, want 1", got)
+	}
+}
+
+func TestCooldownErrorRoundsUp(t *testing.T) {
+	tests := []struct {
+		wait    time.Duration
+		seconds int
+	}{
+		{100 * time.Millisecond, 1},
+		{time.Second, 1},
+		{1100 * time.Millisecond, 2},
+		{30 * time.Second, 30},
+	}
+	for _, tt := range tests {
+		err := &CooldownError{Scope: CooldownUser, Wait: tt.wait}
+		if got, want := err.Localize(i18n.Default), i18n.T(i18n.Default, "errors.cooldown.user", tt.seconds); got != want {
+			t.Errorf("wait %s = %q, want %q", tt.wait, got, want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"first.fm/internal/cache"
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+)
+
+var (
+	ErrDuplicateCommand = errors.New("duplicate command")
+	ErrDuplicateRoute   = errors.New("duplicate route")
+	ErrUnknownCommand   = errors.New("unknown command")
+
+	// ErrCommandDisabled is shown when a command disabled in the guild is
+	// used, see Bot.DisableCommand.
+	ErrCommandDisabled = i18n.NewError("errors.command_disabled")
+)
+
+// Module registers a group of commands, usually the commands of one package.
+type Module func(*Registry) error
+
+// Registry holds the commands of a bot and routes interactions to their
+// handlers. It also owns the state the commands share while the bot runs:
+// their cooldowns and concurrency slots and the open paginators.
+type Registry struct {
+	mu             sync.RWMutex
+	commands       []discord.ApplicationCommandCreate
+	handlers       map[string]CommandHandler
+	autocompleters map[string]map[string]AutocompleteHandler
+	components     map[string]ComponentHandler
+	modals         map[string]ModalHandler
+	// localizationKeys are the catalog keys the registered commands need.
+	localizationKeys []string
+	// middleware runs around every command, outside of the command's own
+	// limits and middleware.
+	middleware []Middleware
+	limits     *limiter
+	paginators *cache.Cache[string, *paginatorState]
+}
+
+// NewRegistry returns a registry with only the built-in component handlers,
+// such as the paginator buttons, and the default middleware: Logging,
+// ReplyErrors and Recover.
+func NewRegistry() *Registry {
+	r := &Registry{
+		handlers:       map[string]CommandHandler{},
+		autocompleters: map[string]map[string]AutocompleteHandler{},
+		components:     map[string]ComponentHandler{},
+		modals:         map[string]ModalHandler{},
+		middleware:     []Middleware{Logging, ReplyErrors, Recover},
+		limits:         newLimiter(),
+		paginators:     cache.New[string, *paginatorState](0, 1000),
+	}
+	r.registerPaginator()
+	return r
+}
+
+// Use registers modules in order and stops at the first one failing.
+func (r *Registry) Use(modules ...Module) error {
+	for _, module := range modules {
+		if err := module(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Register registers a command routed to a single handler.
+func (r *Registry) Register(meta discord.ApplicationCommandCreate, handler CommandHandler, opts ...CommandOption) error {
+	cfg := newCommandConfig(opts)
+
+	path := "/" + meta.CommandName()
+	if slash, ok := meta.(discord.SlashCommandCreate); ok && len(cfg.autocomplete) > 0 {
+		slash.Options = markAutocomplete(slash.Options, cfg.autocomplete)
+		meta = slash
+	}
+
+	var completers map[string]map[string]AutocompleteHandler
+	if len(cfg.autocomplete) > 0 {
+		completers = map[string]map[string]AutocompleteHandler{path: cfg.autocomplete}
+	}
+	return r.register(meta, map[string]CommandHandler{path: cfg.wrap(handler)}, completers, cfg)
+}
+
+// RegisterTree registers a command made of subcommands and subcommand groups.
+// Its registration metadata is generated from the tree and every leaf is
+// routed to its own handler by path. Autocomplete is configured per
+// subcommand; limits and middleware in opts wrap every subcommand and opts
+// may add component and modal handlers.
+func (r *Registry) RegisterTree(tree CommandTree, opts ...CommandOption) error {
+	cfg := newCommandConfig(opts)
+	meta, handlers, completers := tree.build()
+	for path, handler := range handlers {
+		handlers[path] = cfg.wrap(handler)
+	}
+	return r.register(meta, handlers, completers, cfg)
+}
+
+// register adds a command and its routes. Nothing is added when any of them
+// is already taken.
+func (r *Registry) register(meta discord.ApplicationCommandCreate, handlers map[string]CommandHandler, completers map[string]map[string]AutocompleteHandler, cfg commandConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	namespace := meta.CommandName()
+	for _, c := range r.commands {
+		if c.Type() == meta.Type() && c.CommandName() == meta.CommandName() {
+			return fmt.Errorf("%w: %s", ErrDuplicateCommand, meta.CommandName())
+		}
+	}
+	for path := range handlers {
+		if _, ok := r.handlers[path]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateRoute, path)
+		}
+	}
+	for action := range cfg.components {
+		if strings.Contains(action, ":") {
+			return fmt.Errorf("%w: component action %q", ErrInvalidCustomID, action)
+		}
+		if _, ok := r.components[namespace+":"+action]; ok {
+			return fmt.Errorf("%w: component %s:%s", ErrDuplicateRoute, namespace, action)
+		}
+	}
+	for action := range cfg.modals {
+		if strings.Contains(action, ":") {
+			return fmt.Errorf("%w: modal action %q", ErrInvalidCustomID, action)
+		}
+		if _, ok := r.modals[namespace+":"+action]; ok {
+			return fmt.Errorf("%w: modal %s:%s", ErrDuplicateRoute, namespace, action)
+		}
+	}
+
+	meta, keys := localizeCommand(meta)
+	r.localizationKeys = append(r.localizationKeys, keys...)
+
+	logger.Infow("registered command", logger.F{"name": meta.CommandName(), "routes": len(handlers)})
+	r.commands = append(r.commands, meta)
+	for path, handler := range handlers {
+		r.handlers[path] = handler
+	}
+	for path, c := range completers {
+		r.autocompleters[path] = c
+	}
+	for action, handler := range cfg.components {
+		r.components[namespace+":"+action] = handler
+	}
+	for action, handler := range cfg.modals {
+		r.modals[namespace+":"+action] = handler
+	}
+	return nil
+}
+
+// UseMiddleware replaces the middleware that runs around every command.
+func (r *Registry) UseMiddleware(middleware ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = slices.Clone(middleware)
+}
+
+// defaultMiddleware returns the middleware that runs around every command.
+func (r *Registry) defaultMiddleware() []Middleware {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.middleware
+}
+
+// ResizeCaches sets how many cooldowns and open paginators are kept.
+func (r *Registry) ResizeCaches(cooldowns, paginators int) {
+	r.limits.cooldowns.Resize(cooldowns)
+	r.paginators.Resize(paginators)
+}
+
+// Close stops the background goroutines of the registry's caches.
+func (r *Registry) Close() {
+	r.limits.cooldowns.Close()
+	r.paginators.Close()
+}
+
+// Commands returns the registration metadata of every command.
+func (r *Registry) Commands() []discord.ApplicationCommandCreate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.commands)
+}
+
+// Has reports whether a command with the given name is registered.
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.ContainsFunc(r.commands, func(c discord.ApplicationCommandCreate) bool {
+		return c.CommandName() == name
+	})
+}
+
+func (r *Registry) handler(path string) (CommandHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	handler, ok := r.handlers[path]
+	return handler, ok
+}
+
+func (r *Registry) autocompleter(path, option string) (AutocompleteHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	handler, ok := r.autocompleters[path][option]
+	return handler, ok
+}
+
+func (r *Registry) component(route string) (ComponentHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	handler, ok := r.components[route]
+	return handler, ok
+}
+
+func (r *Registry) modal(route string) (ModalHandler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	handler, ok := r.modals[route]
+	return handler, ok
+}
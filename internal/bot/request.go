@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// InteractionTokenLifetime is how long Discord accepts responses and
+// follow-ups for an interaction after it was created.
+const InteractionTokenLifetime = 15 * time.Minute
+
+// NearDeadlineMargin is how long before the interaction token expires
+// handlers are told to wrap up.
+const NearDeadlineMargin = 30 * time.Second
+
+type requestIDKey struct{}
+
+// request is embedded in the contexts handed to interaction handlers.
+type request struct {
+	// RequestID identifies the interaction in logs.
+	RequestID string
+	// Log is a logger carrying the request ID, route and interaction metadata.
+	Log *logger.Logger
+	// Lang is the locale replies are sent in, negotiated from the user and
+	// guild locales.
+	Lang discord.Locale
+
+	near context.Context
+}
+
+// NearDeadline is closed shortly before the interaction token expires, after
+// which the response can no longer be edited. Long running handlers should
+// send what they have when it fires.
+func (r *request) NearDeadline() <-chan struct{} {
+	return r.near.Done()
+}
+
+// TimeLeft returns how long the interaction can still be responded to.
+func (r *request) TimeLeft() time.Duration {
+	deadline, _ := r.near.Deadline()
+	return time.Until(deadline.Add(NearDeadlineMargin))
+}
+
+// RequestID returns the ID of the request ctx belongs to, or "" outside of
+// interaction handlers.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequest derives the context of an interaction from the bot's run
+// context. It is cancelled on shutdown and when the interaction token
+// expires.
+func (b *Bot) newRequest(i discord.Interaction, route string) (context.Context, *request, context.CancelFunc) {
+	parent := b.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	id := newCorrelationID()
+	fields := logger.F{
+		"request": id,
+		"route":   route,
+		"user":    i.User().ID,
+	}
+	if guildID := i.GuildID(); guildID != nil {
+		fields["guild"] = *guildID
+	}
+	log := logger.WithFields(fields)
+
+	deadline := i.CreatedAt().Add(InteractionTokenLifetime)
+	ctx, cancel := context.WithDeadline(parent, deadline)
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	ctx = logger.NewContext(ctx, log)
+
+	near, cancelNear := context.WithDeadline(ctx, deadline.Add(-NearDeadlineMargin))
+
+	return ctx, &request{
+		RequestID: id,
+		Log:       log,
+		Lang:      i18n.Negotiate(i.Locale(), i.GuildLocale()),
+		near:      near,
+	}, func() {
+		cancelNear()
+		cancel()
+	}
+}
@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"first.fm/internal/i18n"
+	"first.fm/internal/lastfm"
+	"first.fm/internal/lastfm/api"
+	"first.fm/internal/logger"
+	"first.fm/internal/persistence/sqlc"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+var (
+	ErrTargetNotRegistered = i18n.NewError("errors.target_not_registered")
+	// ErrUserNotFound matches the errors returned for input that is neither
+	// a member nor a Last.fm user.
+	ErrUserNotFound = i18n.NewError("errors.user_not_found")
+)
+
+// lastFMUsernamePattern matches the usernames Last.fm accepts at sign up.
+// They start with a letter, so input made of digits is always a Discord ID.
+var lastFMUsernamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{1,14}$`)
+
+// memberSearchLimit is how many members are fetched when matching a name.
+const memberSearchLimit = 10
+
+// GetLastFMUser returns the Last.fm profile of the user the option refers
+// to, as resolved by LastFMUsername.
+func (ctx *CommandContext) GetLastFMUser(optionName string) (*lastfm.UserInfo, error) {
+	name, err := ctx.LastFMUsername(optionName)
+	if err != nil {
+		return nil, err
+	}
+	user, err := ctx.LastFM.User.Info(ctx.Ctx, name)
+	if err != nil {
+		return nil, UserNotFound(err, name)
+	}
+	return user, nil
+}
+
+// UserNotFound turns the error Last.fm returns for an unknown user into
+// ErrUserNotFound naming name. Other errors are returned unchanged.
+func UserNotFound(err error, name string) error {
+	if errors.Is(err, api.NewLastFMError(api.ErrInvalidParameters, "")) {
+		return i18n.NewError(ErrUserNotFound.Key, name)
+	}
+	return err
+}
+
+// LastFMUsername returns the Last.fm username the option refers to without
+// fetching the profile. User context menus resolve to the targeted member and
+// commands without the option to the invoking user. optionName defaults to
+// "user".
+func (ctx *CommandContext) LastFMUsername(optionName string) (string, error) {
+	if optionName == "" {
+		optionName = "user"
+	}
+
+	switch data := ctx.Data.(type) {
+	case discord.UserCommandInteractionData:
+		return ctx.linkedUsername(data.TargetID())
+	case discord.SlashCommandInteractionData:
+		if option, ok := data.Option(optionName); ok && option.Type == discord.ApplicationCommandOptionTypeUser {
+			return ctx.linkedUsername(option.Snowflake())
+		}
+		raw, _ := data.OptString(optionName)
+		return ctx.ResolveLastFMUsername(raw)
+	}
+	return ctx.linkedUsername(ctx.User().ID)
+}
+
+// ResolveLastFMUsername returns the Last.fm username input refers to. Input
+// is tried as a mention or ID of a Discord user, a Last.fm profile URL, the
+// name of a member of the current guild and finally a Last.fm username,
+// which is returned as typed without checking that the account exists. A
+// Last.fm user sharing a name with a member can be named by profile URL.
+// Empty input is the invoking user.
+func (ctx *CommandContext) ResolveLastFMUsername(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return ctx.linkedUsername(ctx.User().ID)
+	}
+	if id, ok := parseUserID(input); ok {
+		return ctx.linkedUsername(id)
+	}
+	if name, ok := parseLastFMProfileURL(input); ok {
+		return name, nil
+	}
+	if id, ok := ctx.findMember(input); ok {
+		return ctx.linkedUsername(id)
+	}
+	if !lastFMUsernamePattern.MatchString(input) {
+		return "", i18n.NewError(ErrUserNotFound.Key, input)
+	}
+	return input, nil
+}
+
+// linkedUsername returns the Last.fm username linked to a Discord user.
+func (ctx *CommandContext) linkedUsername(id snowflake.ID) (string, error) {
+	user, err := ctx.Queries.GetUserByID(ctx.Ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if id == ctx.User().ID {
+				return "", ErrNotRegistered
+			}
+			return "", ErrTargetNotRegistered
+		}
+		return "", err
+	}
+	return user.LastfmUsername, nil
+}
+
+// findMember returns the ID of the member of the current guild whose
+// username, display name or nickname is name, ignoring case. The member
+// index is tried first, and Discord is searched for members it doesn't know
+// yet. Failed lookups are logged and treated as no match, so Last.fm
+// usernames still resolve.
+func (ctx *CommandContext) findMember(name string) (snowflake.ID, bool) {
+	guildID := ctx.GuildID()
+	if guildID == nil {
+		return 0, false
+	}
+
+	id, err := ctx.Queries.FindGuildMember(ctx.Ctx, sqlc.FindGuildMemberParams{GuildID: *guildID, Name: strings.ToLower(name)})
+	switch {
+	case err == nil:
+		return id, true
+	case !errors.Is(err, sql.ErrNoRows):
+		ctx.Log.Warnw("failed to find indexed member", logger.F{"err": err.Error()})
+	}
+
+	members, err := ctx.Client.Rest.SearchMembers(*guildID, name, memberSearchLimit)
+	if err != nil {
+		ctx.Log.Warnw("failed to search members", logger.F{"err": err.Error()})
+		return 0, false
+	}
+	for _, m := range members {
+		names := memberNamesOf(m)
+		for _, n := range []string{names.Username, names.GlobalName, names.Nick} {
+			if n != "" && strings.EqualFold(n, name) {
+				return m.User.ID, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// parseUserID parses a user mention or a raw user ID.
+func parseUserID(input string) (snowflake.ID, bool) {
+	if strings.HasPrefix(input, "<@") && strings.HasSuffix(input, ">") {
+		input = strings.TrimPrefix(strings.TrimSuffix(input[2:], ">"), "!")
+	}
+	id, err := snowflake.Parse(input)
+	return id, err == nil && id != 0
+}
+
+// parseLastFMProfileURL returns the username of a Last.fm profile URL such
+// as "https://www.last.fm/user/rj" or "last.fm/pt/user/rj/library".
+func parseLastFMProfileURL(input string) (string, bool) {
+	if !strings.Contains(input, "://") {
+		input = "https://" + input
+	}
+	u, err := url.Parse(input)
+	if err != nil || strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") != "last.fm" {
+		return "", false
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, s := range segments[:len(segments)-1] {
+		if s == "user" {
+			name, err := url.PathUnescape(segments[i+1])
+			if err != nil || !lastFMUsernamePattern.MatchString(name) {
+				return "", false
+			}
+			return name, true
+		}
+	}
+	return "", false
+}
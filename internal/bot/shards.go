@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/disgo/sharding"
+)
+
+// ShardConfig decides which gateway shards the bot connects.
+//
+// A deployment may split the shards between processes sharing the database.
+// Settings that must hold everywhere, such as registrations and the commands
+// a guild disabled, are read from the database. The rest of the state lives
+// in memory and is per process: cooldowns, concurrency caps, paginators and
+// the member index queue. Discord sends the interactions of a guild to the
+// shard that owns it and those of DMs to shard 0, so guild and channel
+// cooldowns mostly behave as with a single process, but a user has a
+// separate cooldown in every process and concurrency caps apply per process.
+type ShardConfig struct {
+	// Count is the total number of shards, or 0 for the count Discord
+	// recommends.
+	Count int
+	// IDs are the shards run by this process, every shard when empty.
+	IDs []int
+}
+
+// runs reports whether this process runs the shard.
+func (c ShardConfig) runs(shardID int) bool {
+	return len(c.IDs) == 0 || slices.Contains(c.IDs, shardID)
+}
+
+// partial reports whether other processes run some of the shards, in which
+// case the guild cache only holds the guilds of this process.
+func (c ShardConfig) partial() bool {
+	return len(c.IDs) > 0 && len(c.IDs) < c.Count
+}
+
+// options returns the shard manager options connecting the configured shards
+// with opts applied to every shard.
+func (c ShardConfig) options(opts ...gateway.ConfigOpt) []sharding.ConfigOpt {
+	options := []sharding.ConfigOpt{sharding.WithGatewayConfigOpts(opts...)}
+	if c.Count == 0 {
+		return options
+	}
+
+	ids := c.IDs
+	if len(ids) == 0 {
+		ids = make([]int, c.Count)
+		for i := range ids {
+			ids[i] = i
+		}
+	}
+	return append(options, sharding.WithShardCount(c.Count), sharding.WithShardIDs(ids...))
+}
+
+// ShardStat describes a shard run by this process.
+type ShardStat struct {
+	ID      int
+	Status  gateway.Status
+	Latency time.Duration
+	// Guilds is how many cached guilds the shard owns.
+	Guilds int
+}
+
+// ShardStats returns the shards run by this process, by ID.
+func (b *Bot) ShardStats() []ShardStat {
+	shards := b.shards()
+	if len(shards) == 0 {
+		return nil
+	}
+
+	guilds := map[int]int{}
+	count := shards[0].ShardCount()
+	for guild := range b.Client.Caches.Guilds() {
+		guilds[sharding.ShardIDByGuild(guild.ID, count)]++
+	}
+
+	stats := make([]ShardStat, len(shards))
+	for i, shard := range shards {
+		stats[i] = ShardStat{
+			ID:      shard.ShardID(),
+			Status:  shard.Status(),
+			Latency: shard.Latency(),
+			Guilds:  guilds[shard.ShardID()],
+		}
+	}
+	return stats
+}
+
+// shards returns the opened shards of this process, by ID. They are copied
+// out of the shard manager, which is locked while iterating.
+func (b *Bot) shards() []gateway.Gateway {
+	if b.Client.ShardManager == nil {
+		return nil
+	}
+	shards := slices.Collect(b.Client.ShardManager.Shards())
+	slices.SortFunc(shards, func(a, b gateway.Gateway) int { return cmp.Compare(a.ShardID(), b.ShardID()) })
+	return shards
+}
+
+// notReadyShards describes the shards of this process that aren't ready,
+// including configured shards that were never opened.
+func (b *Bot) notReadyShards() []string {
+	var notReady []string
+	opened := map[int]bool{}
+	for _, shard := range b.shards() {
+		opened[shard.ShardID()] = true
+		if status := shard.Status(); status != gateway.StatusReady {
+			notReady = append(notReady, fmt.Sprintf("shard %d: %s", shard.ShardID(), strings.ToLower(status.String())))
+		}
+	}
+	for _, id := range b.Sharding.IDs {
+		if !opened[id] {
+			notReady = append(notReady, fmt.Sprintf("shard %d: not opened", id))
+		}
+	}
+	return notReady
+}
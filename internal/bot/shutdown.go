@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"first.fm/internal/emojis"
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+)
+
+// DefaultDrainTimeout is how long Run waits for in-flight interactions on
+// shutdown when Bot.DrainTimeout is unset.
+const DefaultDrainTimeout = 30 * time.Second
+
+// cancelGrace is how long Run waits for the interactions it cancelled after
+// the drain timed out, so they stop using the database before the caller
+// closes it.
+const cancelGrace = 5 * time.Second
+
+// ErrRestarting is shown to users whose interaction arrives while the bot is
+// draining.
+var ErrRestarting = i18n.NewError("errors.restarting")
+
+// inflight tracks the interaction handlers that are currently running.
+type inflight struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	draining bool
+	count    atomic.Int64
+}
+
+// begin registers a new handler. It returns false once draining started, in
+// which case the handler must not run.
+func (f *inflight) begin() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.draining {
+		return false
+	}
+	f.wg.Add(1)
+	f.count.Add(1)
+	return true
+}
+
+// end marks a handler registered with begin as finished.
+func (f *inflight) end() {
+	f.count.Add(-1)
+	f.wg.Done()
+}
+
+// drain rejects new handlers and waits up to timeout for the running ones.
+// It reports whether they all finished.
+func (f *inflight) drain(timeout time.Duration) bool {
+	f.mu.Lock()
+	f.draining = true
+	f.mu.Unlock()
+	return f.wait(timeout)
+}
+
+// wait waits up to timeout for the running handlers and reports whether they
+// all finished.
+func (f *inflight) wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Inflight returns the number of interaction handlers currently running.
+func (b *Bot) Inflight() int {
+	return int(b.inflight.count.Load())
+}
+
+type messageCreator interface {
+	CreateMessage(discord.MessageCreate, ...rest.RequestOpt) error
+	Locale() discord.Locale
+	GuildLocale() *discord.Locale
+}
+
+// replyRestarting tells the user the interaction was rejected because the bot
+// is shutting down.
+func replyRestarting(event messageCreator) {
+	_ = event.CreateMessage(discord.NewMessageCreateBuilder().
+		SetContentf("%s %s", emojis.EmojiCross, ErrRestarting.Localize(i18n.Negotiate(event.Locale(), event.GuildLocale()))).
+		SetEphemeral(true).
+		Build())
+}
+
+// shutdown drains in-flight interactions and cancels the ones still running
+// after the drain timeout, then gives those up to cancelGrace to return.
+func (b *Bot) shutdown(cancel func()) {
+	timeout := b.DrainTimeout
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+
+	logger.Infow("draining in-flight interactions", logger.F{"inflight": b.Inflight(), "timeout": timeout})
+	if b.inflight.drain(timeout) {
+		logger.Info("drained in-flight interactions")
+		return
+	}
+
+	logger.Warnw("drain timed out, cancelling interactions", logger.F{"inflight": b.Inflight()})
+	cancel()
+	if !b.inflight.wait(cancelGrace) {
+		logger.Warnw("interactions still running after cancel", logger.F{"inflight": b.Inflight()})
+	}
+}
+
+// Close stops the background goroutines owned by the bot. The queries and
+// database are owned by the caller and stay open.
+func (b *Bot) Close() {
+	b.Registry.Close()
+	if b.LastFM != nil {
+		b.LastFM.Close()
+	}
+}
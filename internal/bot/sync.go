@@ -0,0 +1,329 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// RegistrationMode decides where commands are registered.
+type RegistrationMode string
+
+const (
+	// RegisterGlobal registers commands globally, which is required for user
+	// installs to see them.
+	RegisterGlobal RegistrationMode = "global"
+	// RegisterGuilds registers commands in every guild of SyncConfig.Guilds.
+	RegisterGuilds RegistrationMode = "guilds"
+	// RegisterDev registers commands in SyncConfig.DevGuild only, where
+	// changes show up instantly.
+	RegisterDev RegistrationMode = "dev"
+)
+
+// SyncConfig configures how Run registers commands with Discord.
+type SyncConfig struct {
+	Mode     RegistrationMode
+	Guilds   []snowflake.ID
+	DevGuild snowflake.ID
+	// DryRun logs the diff without pushing it.
+	DryRun bool
+}
+
+// CommandDiff is the difference between the local commands and the ones
+// registered in a scope.
+type CommandDiff struct {
+	// Scope is "global" or the guild ID.
+	Scope   string
+	Created []discord.ApplicationCommandCreate
+	Updated []CommandUpdate
+	Deleted []discord.ApplicationCommand
+	// Unchanged is the number of commands that are already up to date.
+	Unchanged int
+}
+
+// CommandUpdate is a local command that differs from its registered version.
+type CommandUpdate struct {
+	ID      snowflake.ID
+	Command discord.ApplicationCommandCreate
+	// Fields are the top level fields that changed, e.g. "options".
+	Fields []string
+}
+
+// Empty reports whether the scope is already up to date.
+func (d CommandDiff) Empty() bool {
+	return len(d.Created) == 0 && len(d.Updated) == 0 && len(d.Deleted) == 0
+}
+
+func (d CommandDiff) String() string {
+	if d.Empty() {
+		return fmt.Sprintf("%s: %d commands up to date", d.Scope, d.Unchanged)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d to create, %d to update, %d to delete, %d unchanged",
+		d.Scope, len(d.Created), len(d.Updated), len(d.Deleted), d.Unchanged)
+	for _, c := range d.Created {
+		fmt.Fprintf(&b, "\n  + %s", c.CommandName())
+	}
+	for _, u := range d.Updated {
+		fmt.Fprintf(&b, "\n  ~ %s (%s)", u.Command.CommandName(), strings.Join(u.Fields, ", "))
+	}
+	for _, c := range d.Deleted {
+		fmt.Fprintf(&b, "\n  - %s", c.Name())
+	}
+	return b.String()
+}
+
+// DiffCommands compares local commands with the registered ones. Commands are
+// matched by type and name.
+func DiffCommands(scope string, local []discord.ApplicationCommandCreate, remote []discord.ApplicationCommand) (CommandDiff, error) {
+	diff := CommandDiff{Scope: scope}
+
+	byKey := make(map[string]discord.ApplicationCommand, len(remote))
+	for _, c := range remote {
+		byKey[commandKey(c.Type(), c.Name())] = c
+	}
+
+	for _, l := range local {
+		key := commandKey(l.Type(), l.CommandName())
+		r, ok := byKey[key]
+		if !ok {
+			diff.Created = append(diff.Created, l)
+			continue
+		}
+		delete(byKey, key)
+
+		fields, err := changedFields(l, r)
+		if err != nil {
+			return diff, fmt.Errorf("failed to compare %s: %w", l.CommandName(), err)
+		}
+		if len(fields) == 0 {
+			diff.Unchanged++
+			continue
+		}
+		diff.Updated = append(diff.Updated, CommandUpdate{ID: r.ID(), Command: l, Fields: fields})
+	}
+
+	for _, r := range remote {
+		if _, ok := byKey[commandKey(r.Type(), r.Name())]; ok {
+			diff.Deleted = append(diff.Deleted, r)
+		}
+	}
+	return diff, nil
+}
+
+func commandKey(t discord.ApplicationCommandType, name string) string {
+	return strconv.Itoa(int(t)) + ":" + name
+}
+
+// comparedFields are the fields of a command that can be set on creation.
+var comparedFields = []string{
+	"name",
+	"name_localizations",
+	"description",
+	"description_localizations",
+	"options",
+	"default_member_permissions",
+	"nsfw",
+	"integration_types",
+	"contexts",
+}
+
+// changedFields returns the compared fields that differ between the local
+// and the registered command.
+func changedFields(local discord.ApplicationCommandCreate, remote discord.ApplicationCommand) ([]string, error) {
+	l, err := normalizeCommand(local)
+	if err != nil {
+		return nil, err
+	}
+	r, err := normalizeCommand(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for _, f := range comparedFields {
+		if !reflect.DeepEqual(l[f], r[f]) {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// normalizeCommand returns the compared fields of a command as generic JSON
+// with zero values dropped, so omitted fields equal Discord's defaults.
+func normalizeCommand(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err = json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]any, len(comparedFields))
+	for _, f := range comparedFields {
+		if v := pruneZero(m[f]); v != nil {
+			out[f] = v
+		}
+	}
+	// commands without integration types are guild installable only
+	if out["integration_types"] == nil {
+		out["integration_types"] = []any{float64(discord.ApplicationIntegrationTypeGuildInstall)}
+	}
+	// disgo decodes missing permissions as 0, which we can't tell apart from
+	// an explicit 0
+	if out["default_member_permissions"] == "0" {
+		delete(out, "default_member_permissions")
+	}
+	return out, nil
+}
+
+func pruneZero(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			if e = pruneZero(e); e != nil {
+				out[k] = e
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+		// elements keep their zero values, [0] isn't the same as []
+		out := make([]any, len(v))
+		for i, e := range v {
+			if m, ok := e.(map[string]any); ok {
+				out[i] = pruneZero(m)
+			} else {
+				out[i] = e
+			}
+		}
+		return out
+	case bool:
+		if !v {
+			return nil
+		}
+	case string:
+		if v == "" {
+			return nil
+		}
+	case float64:
+		if v == 0 {
+			return nil
+		}
+	}
+	return v
+}
+
+// SyncCommands registers the commands of b.Registry according to b.Sync,
+// only pushing the commands that changed.
+func (b *Bot) SyncCommands() error {
+	local := b.Registry.Commands()
+	appID := b.Client.ApplicationID
+	rest := b.Client.Rest
+
+	switch b.Sync.Mode {
+	case RegisterGlobal:
+		remote, err := rest.GetGlobalCommands(appID, true)
+		if err != nil {
+			return fmt.Errorf("failed to get global commands: %w", err)
+		}
+		diff, err := DiffCommands("global", local, remote)
+		if err != nil {
+			return err
+		}
+		return b.applyDiff(diff, commandSyncer{
+			create: func(c discord.ApplicationCommandCreate) error {
+				_, err := rest.CreateGlobalCommand(appID, c)
+				return err
+			},
+			delete: func(id snowflake.ID) error {
+				return rest.DeleteGlobalCommand(appID, id)
+			},
+		})
+
+	case RegisterGuilds, RegisterDev:
+		guilds := b.Sync.Guilds
+		if b.Sync.Mode == RegisterDev {
+			guilds = []snowflake.ID{b.Sync.DevGuild}
+		}
+		if len(guilds) == 0 || slices.Contains(guilds, 0) {
+			return fmt.Errorf("registration mode %q needs at least one guild", b.Sync.Mode)
+		}
+
+		for _, guildID := range guilds {
+			remote, err := rest.GetGuildCommands(appID, guildID, true)
+			if err != nil {
+				return fmt.Errorf("failed to get commands of guild %s: %w", guildID, err)
+			}
+			diff, err := DiffCommands(guildID.String(), local, remote)
+			if err != nil {
+				return err
+			}
+			err = b.applyDiff(diff, commandSyncer{
+				create: func(c discord.ApplicationCommandCreate) error {
+					_, err := rest.CreateGuildCommand(appID, guildID, c)
+					return err
+				},
+				delete: func(id snowflake.ID) error {
+					return rest.DeleteGuildCommand(appID, guildID, id)
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown registration mode %q", b.Sync.Mode)
+	}
+}
+
+// commandSyncer pushes changes to one scope. Creating a command with the
+// name of an existing one overwrites it, so create also handles updates.
+type commandSyncer struct {
+	create func(discord.ApplicationCommandCreate) error
+	delete func(snowflake.ID) error
+}
+
+func (b *Bot) applyDiff(diff CommandDiff, s commandSyncer) error {
+	if b.Sync.DryRun || !diff.Empty() {
+		logger.Infow("command diff", logger.F{"mode": b.Sync.Mode, "dry_run": b.Sync.DryRun, "diff": diff.String()})
+	}
+	if b.Sync.DryRun || diff.Empty() {
+		return nil
+	}
+
+	for _, c := range diff.Created {
+		if err := s.create(c); err != nil {
+			return fmt.Errorf("failed to create %s in %s: %w", c.CommandName(), diff.Scope, err)
+		}
+	}
+	for _, u := range diff.Updated {
+		if err := s.create(u.Command); err != nil {
+			return fmt.Errorf("failed to update %s in %s: %w", u.Command.CommandName(), diff.Scope, err)
+		}
+	}
+	for _, c := range diff.Deleted {
+		if err := s.delete(c.ID()); err != nil {
+			return fmt.Errorf("failed to delete %s in %s: %w", c.Name(), diff.Scope, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package bot
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// registered returns c as Discord sends it back after registration, with
+// edit applied to its JSON.
+func registered(t *testing.T, c discord.ApplicationCommandCreate, id snowflake.ID, edit func(map[string]any)) discord.ApplicationCommand {
+	t.Helper()
+	raw, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatal(err)
+	}
+	m["id"] = id.String()
+	m["application_id"] = "1"
+	m["version"] = "1"
+	if edit != nil {
+		edit(m)
+	}
+	if raw, err = json.Marshal(m); err != nil {
+		t.Fatal(err)
+	}
+
+	var cmd discord.UnmarshalApplicationCommand
+	if err := json.Unmarshal(raw, &cmd); err != nil {
+		t.Fatal(err)
+	}
+	return cmd.ApplicationCommand
+}
+
+func TestDiffCommands(t *testing.T) {
+	fm := discord.SlashCommandCreate{
+		Name:        "fm",
+		Description: "display what you're listening to",
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionString{Name: "user", Description: "user to get fm from"},
+		},
+	}
+	profile := discord.SlashCommandCreate{Name: "profile", Description: "display a profile"}
+	lookup := discord.MessageCommandCreate{Name: "profile"}
+
+	type want struct {
+		created, deleted []string
+		updated          map[string][]string
+		unchanged        int
+	}
+	tests := []struct {
+		name   string
+		local  []discord.ApplicationCommandCreate
+		remote func(t *testing.T) []discord.ApplicationCommand
+		want   want
+	}{
+		{
+			name:  "unchanged",
+			local: []discord.ApplicationCommandCreate{fm, profile},
+			remote: func(t *testing.T) []discord.ApplicationCommand {
+				return []discord.ApplicationCommand{registered(t, fm, 10, nil), registered(t, profile, 11, nil)}
+			},
+			want: want{unchanged: 2},
+		},
+		{
+			name:  "discord defaults",
+			local: []discord.ApplicationCommandCreate{fm},
+			remote: func(t *testing.T) []discord.ApplicationCommand {
+				return []discord.ApplicationCommand{registered(t, fm, 10, func(m map[string]any) {
+					m["integration_types"] = []any{0}
+					m["default_member_permissions"] = nil
+					m["nsfw"] = false
+					m["name_localizations"] = map[string]any{}
+				})}
+			},
+			want: want{unchanged: 1},
+		},
+		{
+			name:  "added",
+			local: []discord.ApplicationCommandCreate{fm, profile},
+			remote: func(t *testing.T) []discord.ApplicationCommand {
+				return []discord.ApplicationCommand{registered(t, fm, 10, nil)}
+			},
+			want: want{created: []string{"profile"}, unchanged: 1},
+		},
+		{
+			name:  "removed",
+			local: []discord.ApplicationCommandCreate{fm},
+			remote: func(t *testing.T) []discord.ApplicationCommand {
+				return []discord.ApplicationCommand{registered(t, fm, 10, nil), registered(t, profile, 11, nil)}
+			},
+			want: want{deleted: []string{"profile"}, unchanged: 1},
+		},
+		{
+			name:  "same name of another type",
+			local: []discord.ApplicationCommandCreate{lookup},
+			remote: func(t *testing.T) []discord.ApplicationCommand {
+				return []discord.ApplicationCommand{registered(t, profile, 11, nil)}
+			},
+			want: want{created: []string{"profile"}, deleted: []string{"profile"}},
+		},
+		{
+			name:  "changed",
+			local: []discord.ApplicationCommandCreate{fm},
+			remote: func(t *testing.T) []discord.ApplicationCommand {
+				return []discord.ApplicationCommand{registered(t, fm, 10, func(m map[string]any) {
+					m["description"] = "show what you're listening to"
+					m["options"] = []any{}
+				})}
+			},
+			want: want{updated: map[string][]string{"fm": {"description", "options"}}},
+		},
+		{
+			name: "localizations only",
+			local: []discord.ApplicationCommandCreate{discord.SlashCommandCreate{
+				Name:                     "profile",
+				Description:              "display a profile",
+				DescriptionLocalizations: map[discord.Locale]string{discord.LocalePortugueseBR: "mostrar um perfil"},
+			}},
+			remote: func(t *testing.T) []discord.ApplicationCommand {
+				return []discord.ApplicationCommand{registered(t, profile, 11, nil)}
+			},
+			want: want{updated: map[string][]string{"profile": {"description_localizations"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := DiffCommands("global", tt.local, tt.remote(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var created, deleted []string
+			for _, c := range diff.Created {
+				created = append(created, c.CommandName())
+			}
+			for _, c := range diff.Deleted {
+				deleted = append(deleted, c.Name())
+			}
+			updated := map[string][]string{}
+			for _, u := range diff.Updated {
+				updated[u.Command.CommandName()] = u.Fields
+			}
+
+			if !slices.Equal(created, tt.want.created) {
+				t.Errorf("created %v, want %v", created, tt.want.created)
+			}
+			if !slices.Equal(deleted, tt.want.deleted) {
+				t.Errorf("deleted %v, want %v", deleted, tt.want.deleted)
+			}
+			if len(updated) != len(tt.want.updated) {
+				t.Errorf("updated %v, want %v", updated, tt.want.updated)
+			}
+			for name, fields := range tt.want.updated {
+				if !slices.Equal(updated[name], fields) {
+					t.Errorf("updated fields of %s = %v, want %v", name, updated[name], fields)
+				}
+			}
+			if diff.Unchanged != tt.want.unchanged {
+				t.Errorf("unchanged %d, want %d", diff.Unchanged, tt.want.unchanged)
+			}
+		})
+	}
+}
+
+func TestNormalizeCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b discord.ApplicationCommandCreate
+		same bool
+	}{
+		{
+			name: "missing integration types are guild installs",
+			a:    discord.SlashCommandCreate{Name: "fm", Description: "fm"},
+			b: discord.SlashCommandCreate{Name: "fm", Description: "fm", IntegrationTypes: []discord.ApplicationIntegrationType{
+				discord.ApplicationIntegrationTypeGuildInstall,
+			}},
+			same: true,
+		},
+		{
+			name: "user installs differ",
+			a:    discord.SlashCommandCreate{Name: "fm", Description: "fm"},
+			b: discord.SlashCommandCreate{Name: "fm", Description: "fm", IntegrationTypes: []discord.ApplicationIntegrationType{
+				discord.ApplicationIntegrationTypeUserInstall,
+			}},
+		},
+		{
+			name: "empty localizations are omitted",
+			a:    discord.SlashCommandCreate{Name: "fm", Description: "fm"},
+			b:    discord.SlashCommandCreate{Name: "fm", Description: "fm", NameLocalizations: map[discord.Locale]string{}},
+			same: true,
+		},
+		{
+			name: "option order matters",
+			a: discord.SlashCommandCreate{Name: "fm", Description: "fm", Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{Name: "a", Description: "a"},
+				discord.ApplicationCommandOptionString{Name: "b", Description: "b"},
+			}},
+			b: discord.SlashCommandCreate{Name: "fm", Description: "fm", Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{Name: "b", Description: "b"},
+				discord.ApplicationCommandOptionString{Name: "a", Description: "a"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := normalizeCommand(tt.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := normalizeCommand(tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if same := reflect.DeepEqual(a, b); same != tt.same {
+				t.Errorf("normalized equal = %v, want %v\n%v\n%v", same, tt.same, a, b)
+			}
+		})
+	}
+}
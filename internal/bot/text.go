@@ -0,0 +1,20 @@
+package bot
+
+import "unicode/utf8"
+
+// Truncate cuts s to at most n runes, never splitting a multi-byte
+// character.
+func Truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// Ellipsize shortens s to at most n runes, ending in "…" when it was cut.
+func Ellipsize(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return Truncate(s, n-1) + "…"
+}
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"slices"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+// CommandTree describes a slash command whose behavior lives in subcommands,
+// e.g. "/top artists" and "/top albums". The embedded SlashCommandCreate
+// holds the top-level metadata; its Options are generated from the tree.
+type CommandTree struct {
+	discord.SlashCommandCreate
+	Groups      []SubCommandGroup
+	SubCommands []SubCommand
+}
+
+// SubCommandGroup groups subcommands under a name, e.g. "/server crowns list".
+type SubCommandGroup struct {
+	Name        string
+	Description string
+	SubCommands []SubCommand
+}
+
+// SubCommand is a leaf of a CommandTree routed to its own handler.
+type SubCommand struct {
+	Name        string
+	Description string
+	Options     []discord.ApplicationCommandOption
+	Handler     CommandHandler
+	// Autocomplete maps option names to their autocomplete handlers.
+	Autocomplete map[string]AutocompleteHandler
+	// Middleware wraps only this subcommand's handler.
+	Middleware []Middleware
+}
+
+// NewSubCommand returns a subcommand whose options are generated from the
+// option struct T and parsed into it before handler runs, like Handle does
+// for plain commands. See OptionsOf for the struct tags.
+func NewSubCommand[T any](name, description string, handler func(*CommandContext, T) error) SubCommand {
+	return SubCommand{
+		Name:        name,
+		Description: description,
+		Options:     OptionsOf[T](),
+		Handler:     Handle(handler),
+	}
+}
+
+// With returns the subcommand wrapped with middleware in addition to its own.
+func (s SubCommand) With(middleware ...Middleware) SubCommand {
+	s.Middleware = append(slices.Clone(s.Middleware), middleware...)
+	return s
+}
+
+func (s SubCommand) option() discord.ApplicationCommandOptionSubCommand {
+	return discord.ApplicationCommandOptionSubCommand{
+		Name:        s.Name,
+		Description: s.Description,
+		Options:     markAutocomplete(s.Options, s.Autocomplete),
+	}
+}
+
+// build generates the registration metadata of the tree and the handlers and
+// autocomplete handlers keyed by command path.
+func (t CommandTree) build() (discord.SlashCommandCreate, map[string]CommandHandler, map[string]map[string]AutocompleteHandler) {
+	meta := t.SlashCommandCreate
+	meta.Options = nil
+	handlers := map[string]CommandHandler{}
+	completers := map[string]map[string]AutocompleteHandler{}
+
+	for _, group := range t.Groups {
+		option := discord.ApplicationCommandOptionSubCommandGroup{
+			Name:        group.Name,
+			Description: group.Description,
+		}
+		for _, sub := range group.SubCommands {
+			path := "/" + meta.Name + "/" + group.Name + "/" + sub.Name
+			option.Options = append(option.Options, sub.option())
+			handlers[path] = Chain(sub.Handler, sub.Middleware...)
+			if len(sub.Autocomplete) > 0 {
+				completers[path] = sub.Autocomplete
+			}
+		}
+		meta.Options = append(meta.Options, option)
+	}
+
+	for _, sub := range t.SubCommands {
+		path := "/" + meta.Name + "/" + sub.Name
+		meta.Options = append(meta.Options, sub.option())
+		handlers[path] = Chain(sub.Handler, sub.Middleware...)
+		if len(sub.Autocomplete) > 0 {
+			completers[path] = sub.Autocomplete
+		}
+	}
+
+	return meta, handlers, completers
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,8 +12,12 @@ type Cache[K comparable, V any] struct {
 	defaultTTL time.Duration
 	maxSize    int
 
-	hits   uint64
-	misses uint64
+	// hits and misses are atomic since Get only holds the read lock.
+	hits   atomic.Uint64
+	misses atomic.Uint64
+
+	stop      chan struct{}
+	closeOnce sync.Once
 }
 
 type Item[V any] struct {
@@ -26,6 +31,7 @@ func New[K comparable, V any](defaultTTL time.Duration, maxSize int) *Cache[K, V
 		items:      make(map[K]*Item[V]),
 		defaultTTL: defaultTTL,
 		maxSize:    maxSize,
+		stop:       make(chan struct{}),
 	}
 
 	if defaultTTL > 0 {
@@ -41,19 +47,19 @@ func (c *Cache[K, V]) Get(key K) (V, bool) {
 
 	item, exists := c.items[key]
 	if !exists {
-		c.misses++
+		c.misses.Add(1)
 		var zero V
 		return zero, false
 	}
 
 	if !item.ExpiresAt.IsZero() && time.Now().After(item.ExpiresAt) {
-		c.misses++
+		c.misses.Add(1)
 		var zero V
 		return zero, false
 	}
 
 	item.LastAccess = time.Now()
-	c.hits++
+	c.hits.Add(1)
 	return item.Value, true
 }
 
@@ -102,7 +108,19 @@ func (c *Cache[K, V]) Size() int {
 func (c *Cache[K, V]) Stats() (hits, misses uint64, size int) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.hits, c.misses, len(c.items)
+	return c.hits.Load(), c.misses.Load(), len(c.items)
+}
+
+// Resize changes the maximum number of items, evicting the least recently
+// used ones while the cache holds more. 0 is unbounded.
+func (c *Cache[K, V]) Resize(maxSize int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxSize = maxSize
+	for maxSize > 0 && len(c.items) > maxSize {
+		c.evictOldest()
+	}
 }
 
 func (c *Cache[K, V]) evictOldest() {
@@ -127,11 +145,22 @@ func (c *Cache[K, V]) cleanupLoop() {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		c.cleanup()
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.stop:
+			return
+		}
 	}
 }
 
+// Close stops the cleanup goroutine. The cache stays usable, but expired
+// items are only dropped on eviction afterwards.
+func (c *Cache[K, V]) Close() {
+	c.closeOnce.Do(func() { close(c.stop) })
+}
+
 func (c *Cache[K, V]) cleanup() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
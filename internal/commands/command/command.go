@@ -0,0 +1,126 @@
+package command
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/i18n"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// Module registers the /command command, which disables and enables the
+// other commands in a server.
+func Module(r *bot.Registry) error {
+	return r.RegisterTree(tree,
+		bot.WithCooldown(bot.CooldownGuild, 3*time.Second),
+		bot.WithMiddleware(bot.GuildOnly),
+	)
+}
+
+// name is the name of this command, which can't be disabled so a server can
+// always enable its commands again.
+const name = "command"
+
+// manageServer guards the subcommands changing which commands run.
+var manageServer = []bot.Middleware{bot.RequirePermissions(discord.PermissionManageGuild)}
+
+// complete suggests the names of the commands that can be toggled.
+var complete = map[string]bot.AutocompleteHandler{"name": commandNames}
+
+var tree = bot.CommandTree{
+	SlashCommandCreate: discord.SlashCommandCreate{
+		Name:        name,
+		Description: "choose which commands can be used in this server",
+		Contexts:    []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+	},
+	SubCommands: []bot.SubCommand{
+		withAutocomplete(bot.NewSubCommand("disable", "stop a command from being used in this server", disable)).
+			With(manageServer...),
+		withAutocomplete(bot.NewSubCommand("enable", "let a disabled command be used in this server again", enable)).
+			With(manageServer...),
+		{
+			Name:        "list",
+			Description: "list the commands disabled in this server",
+			Handler:     list,
+		},
+	},
+}
+
+type toggleOptions struct {
+	Name string `option:"name" description:"name of the command" required:"true" max:"32"`
+}
+
+func withAutocomplete(sub bot.SubCommand) bot.SubCommand {
+	sub.Autocomplete = complete
+	return sub
+}
+
+func disable(ctx *bot.CommandContext, opts toggleOptions) error {
+	command := normalize(opts.Name)
+	if command == name {
+		return i18n.NewError("command.protected", name)
+	}
+	if err := ctx.DisableCommand(ctx.Ctx, *ctx.GuildID(), command); err != nil {
+		return toggleError(err, command)
+	}
+	return reply(ctx, ctx.T("command.disabled", command))
+}
+
+func enable(ctx *bot.CommandContext, opts toggleOptions) error {
+	command := normalize(opts.Name)
+	if err := ctx.EnableCommand(ctx.Ctx, *ctx.GuildID(), command); err != nil {
+		return toggleError(err, command)
+	}
+	return reply(ctx, ctx.T("command.enabled", command))
+}
+
+func list(ctx *bot.CommandContext) error {
+	names, err := ctx.DisabledCommands(ctx.Ctx, *ctx.GuildID())
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return reply(ctx, ctx.T("command.none_disabled"))
+	}
+	return reply(ctx, ctx.T("command.list", "`"+strings.Join(names, "`, `")+"`"))
+}
+
+// commandNames suggests the registered commands starting with what was typed.
+func commandNames(ctx *bot.AutocompleteContext) ([]discord.AutocompleteChoice, error) {
+	_, typed := ctx.Focused()
+	typed = normalize(typed)
+
+	var choices []discord.AutocompleteChoice
+	for _, c := range ctx.Registry.Commands() {
+		n := c.CommandName()
+		if c.Type() != discord.ApplicationCommandTypeSlash || n == name || !strings.HasPrefix(n, typed) {
+			continue
+		}
+		choices = append(choices, discord.AutocompleteChoiceString{Name: n, Value: n})
+		if len(choices) == 25 {
+			break
+		}
+	}
+	return choices, nil
+}
+
+// normalize accepts names typed with their slash, such as "/fm".
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), "/"))
+}
+
+func toggleError(err error, command string) error {
+	if errors.Is(err, bot.ErrUnknownCommand) {
+		return i18n.NewError("command.unknown", command)
+	}
+	return err
+}
+
+func reply(ctx *bot.CommandContext, content string) error {
+	return ctx.CreateMessage(discord.NewMessageCreateBuilder().
+		SetContent(content).
+		SetEphemeral(true).
+		Build())
+}
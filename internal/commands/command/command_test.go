@@ -0,0 +1,51 @@
+package command_test
+
+import (
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/command"
+	"first.fm/internal/commands/stats"
+	"github.com/disgoorg/disgo/discord"
+)
+
+func TestDisableAndEnable(t *testing.T) {
+	h := bottest.New(t, command.Module, stats.Module)
+
+	res := h.Slash("command disable").Option("name", "/stats").Permissions(discord.PermissionManageGuild).Run()
+	bottest.Golden(t, "disable", res.String())
+
+	res = h.Slash("stats").Run()
+	bottest.Golden(t, "disabled_command", res.String())
+
+	res = h.Slash("command list").Run()
+	bottest.Golden(t, "list", res.String())
+
+	res = h.Slash("command enable").Option("name", "stats").Permissions(discord.PermissionManageGuild).Run()
+	bottest.Golden(t, "enable", res.String())
+
+	if res := h.Slash("stats").Run(); res.Final().Ephemeral {
+		t.Errorf("stats is still disabled:\n%s", res)
+	}
+}
+
+func TestDisableProtected(t *testing.T) {
+	h := bottest.New(t, command.Module)
+
+	res := h.Slash("command disable").Option("name", "command").Permissions(discord.PermissionManageGuild).Run()
+	bottest.Golden(t, "protected", res.String())
+}
+
+func TestDisableUnknown(t *testing.T) {
+	h := bottest.New(t, command.Module)
+
+	res := h.Slash("command disable").Option("name", "nope").Permissions(discord.PermissionManageGuild).Run()
+	bottest.Golden(t, "unknown", res.String())
+}
+
+func TestAutocompleteNames(t *testing.T) {
+	h := bottest.New(t, command.Module, stats.Module)
+
+	res := h.Autocomplete("command disable").Focus("name", "st").Run()
+	bottest.Golden(t, "autocomplete", res.String())
+}
@@ -0,0 +1,79 @@
+package crashes
+
+import (
+	"fmt"
+	"strings"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/i18n"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// maxStackLength keeps the stack trace within Discord's message limits.
+const maxStackLength = 3500
+
+// Module registers the owner-only /crashes command.
+func Module(r *bot.Registry) error {
+	return r.Register(data, bot.Handle(handle), bot.WithMiddleware(bot.OwnerOnly))
+}
+
+var data = discord.SlashCommandCreate{
+	Name:        "crashes",
+	Description: "display recent crash reports",
+	Options:     bot.OptionsOf[options](),
+}
+
+type options struct {
+	ID string `option:"id" description:"crash id to show the stack trace of"`
+}
+
+func handle(ctx *bot.CommandContext, opts options) error {
+	var text string
+	if id := strings.Trim(opts.ID, "`"); id != "" {
+		report, ok := ctx.Crashes.Get(id)
+		if !ok {
+			return i18n.NewError("crashes.not_found", id)
+		}
+		text = formatReport(ctx, report)
+	} else {
+		text = formatRecent(ctx, ctx.Crashes.Recent(10), ctx.Crashes.Len())
+	}
+
+	component := discord.NewContainer(
+		discord.NewTextDisplay(text),
+	)
+
+	return ctx.CreateMessage(discord.NewMessageCreateBuilder().
+		SetIsComponentsV2(true).
+		SetComponents(component).
+		SetEphemeral(true).
+		Build())
+}
+
+func formatRecent(ctx *bot.CommandContext, reports []bot.CrashReport, total int) string {
+	if len(reports) == 0 {
+		return ctx.T("crashes.none")
+	}
+
+	var b strings.Builder
+	b.WriteString(ctx.T("crashes.showing", len(reports), total) + "\n")
+	for _, r := range reports {
+		fmt.Fprintf(&b, "`%s` <t:%d:R> **%s** <@%s>: %s\n", r.ID, r.Time.Unix(), r.Route, r.UserID, bot.Ellipsize(r.Panic, 100))
+	}
+	return b.String()
+}
+
+func formatReport(ctx *bot.CommandContext, r bot.CrashReport) string {
+	lines := []string{
+		ctx.T("crashes.title", r.ID),
+		ctx.T("crashes.route", r.Route),
+		ctx.T("crashes.time", r.Time.Unix()),
+		ctx.T("crashes.user", r.UserID, r.UserID),
+		ctx.T("crashes.guild", r.GuildID),
+		ctx.T("crashes.channel", r.ChannelID),
+		ctx.T("crashes.interaction", r.InteractionID),
+		ctx.T("crashes.panic", bot.Ellipsize(r.Panic, 200)),
+		"```\n" + bot.Ellipsize(r.Stack, maxStackLength) + "\n```",
+	}
+	return strings.Join(lines, "\n")
+}
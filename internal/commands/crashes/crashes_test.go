@@ -0,0 +1,66 @@
+package crashes_test
+
+import (
+	"testing"
+	"time"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/crashes"
+)
+
+func addCrash(h *bottest.Harness) {
+	h.Bot.Crashes.Add(bot.CrashReport{
+		ID:            "abc123",
+		Time:          time.Unix(1700000000, 0),
+		Route:         "/fm",
+		InteractionID: 4000,
+		UserID:        bottest.UserID + 100,
+		GuildID:       h.Guild,
+		ChannelID:     h.Channel,
+		Panic:         "runtime error: invalid memory address or nil pointer dereference",
+		Stack:         "goroutine 1 [running]:\nfirst.fm/internal/commands/fm.handle(...)",
+	})
+}
+
+func TestCrashesNone(t *testing.T) {
+	h := bottest.New(t, crashes.Module)
+	h.Owner(h.User)
+
+	res := h.Slash("crashes").Run()
+	bottest.Golden(t, "none", res.String())
+}
+
+func TestCrashesRecent(t *testing.T) {
+	h := bottest.New(t, crashes.Module)
+	h.Owner(h.User)
+	addCrash(h)
+
+	res := h.Slash("crashes").Run()
+	bottest.Golden(t, "recent", res.String())
+}
+
+func TestCrashesReport(t *testing.T) {
+	h := bottest.New(t, crashes.Module)
+	h.Owner(h.User)
+	addCrash(h)
+
+	res := h.Slash("crashes").Option("id", "`abc123`").Run()
+	bottest.Golden(t, "report", res.String())
+}
+
+func TestCrashesNotFound(t *testing.T) {
+	h := bottest.New(t, crashes.Module)
+	h.Owner(h.User)
+
+	res := h.Slash("crashes").Option("id", "nope").Run()
+	bottest.Golden(t, "not_found", res.String())
+}
+
+func TestCrashesOwnerOnly(t *testing.T) {
+	h := bottest.New(t, crashes.Module)
+	addCrash(h)
+
+	res := h.Slash("crashes").Run()
+	bottest.Golden(t, "owner_only", res.String())
+}
@@ -1,15 +1,25 @@
 package fm
 
 import (
-	"errors"
 	"time"
 
 	"first.fm/internal/bot"
+	"first.fm/internal/i18n"
+	"first.fm/internal/lastfm"
 	"github.com/disgoorg/disgo/discord"
 )
 
-func init() {
-	bot.Register(data, handle)
+// Module registers the /fm command and its "Now playing" context menu.
+func Module(r *bot.Registry) error {
+	opts := []bot.CommandOption{
+		bot.WithCooldown(bot.CooldownUser, 3*time.Second),
+		bot.WithCooldown(bot.CooldownChannel, time.Second),
+		bot.WithMiddleware(bot.AutoDefer(false)),
+	}
+	if err := r.Register(data, bot.Handle(handle), opts...); err != nil {
+		return err
+	}
+	return r.Register(userData, bot.Handle(handle), opts...)
 }
 
 var data = discord.SlashCommandCreate{
@@ -19,37 +29,39 @@ var data = discord.SlashCommandCreate{
 		discord.ApplicationIntegrationTypeGuildInstall,
 		discord.ApplicationIntegrationTypeUserInstall,
 	},
-	Options: []discord.ApplicationCommandOption{
-		discord.ApplicationCommandOptionString{
-			Name:        "user",
-			Description: "user to get fm from",
-			Required:    false,
-		},
+	Options: bot.OptionsOf[options](),
+}
+
+type options struct {
+	User *lastfm.UserInfo `option:"user" description:"user to get fm from"`
+}
+
+// userData is the context menu shown when right-clicking a member.
+var userData = discord.UserCommandCreate{
+	Name: "Now playing",
+	IntegrationTypes: []discord.ApplicationIntegrationType{
+		discord.ApplicationIntegrationTypeGuildInstall,
+		discord.ApplicationIntegrationTypeUserInstall,
 	},
 }
 
-func handle(ctx *bot.CommandContext) error {
-	err := ctx.DeferCreateMessage(false)
-	if err != nil {
-		return err
-	}
+func handle(ctx *bot.CommandContext, opts options) error {
+	user := opts.User
 
-	user, err := ctx.GetLastFMUser("")
+	recentTrack, err := ctx.LastFM.User.RecentTrack(ctx.Ctx, user.Name)
 	if err != nil {
-		return err
+		return i18n.NewError("fm.recent_failed")
 	}
-
-	recentTrack, err := ctx.LastFM.User.RecentTrack(user.Name)
-	if err != nil {
-		return errors.New("failed to get recent track")
+	if recentTrack.Track == nil {
+		return i18n.NewError("fm.no_scrobbles", user.Name)
 	}
 
 	var text discord.TextDisplayComponent
 
 	if recentTrack.Track.NowPlaying {
-		text = discord.NewTextDisplayf("-# *Current track for **%s***", recentTrack.User)
+		text = discord.NewTextDisplay(ctx.T("fm.current", recentTrack.User))
 	} else {
-		text = discord.NewTextDisplayf("-# *Last track for **%s**, scrobbled at %s*", recentTrack.User, recentTrack.Track.ScrobbledAt.Format(time.Kitchen))
+		text = discord.NewTextDisplay(ctx.T("fm.last", recentTrack.User, recentTrack.Track.ScrobbledAt.Format(time.Kitchen)))
 	}
 
 	component := discord.NewContainer(
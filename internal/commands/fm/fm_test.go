@@ -0,0 +1,60 @@
+package fm_test
+
+import (
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/fm"
+	"first.fm/internal/lastfm/api"
+)
+
+const userInfo = `<user><name>rj</name><url>https://www.last.fm/user/rj</url><playcount>1234</playcount></user>`
+
+const nowPlaying = `<recenttracks user="rj" page="1" perPage="1" totalPages="1234" total="1234">
+  <track nowplaying="true">
+    <artist mbid="">Radiohead</artist>
+    <name>Reckoner</name>
+    <album mbid="">In Rainbows</album>
+    <url>https://www.last.fm/music/Radiohead/_/Reckoner</url>
+    <image size="small">https://lastfm.freetls.fastly.net/i/u/34s/cover.png</image>
+    <image size="extralarge">https://lastfm.freetls.fastly.net/i/u/300x300/cover.png</image>
+  </track>
+</recenttracks>`
+
+func TestNowPlaying(t *testing.T) {
+	h := bottest.New(t, fm.Module)
+	h.Link(h.User, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+	h.LastFM.Handle(api.UserGetRecentTracksMethod, nowPlaying)
+
+	res := h.Slash("fm").Run()
+	bottest.Golden(t, "now_playing", res.String())
+}
+
+func TestNowPlayingContextMenu(t *testing.T) {
+	h := bottest.New(t, fm.Module)
+	target := bottest.UserID + 100
+	h.Link(target, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+	h.LastFM.Handle(api.UserGetRecentTracksMethod, nowPlaying)
+
+	res := h.UserCommand("Now playing", target).Run()
+	bottest.Golden(t, "context_menu", res.String())
+}
+
+func TestNoScrobbles(t *testing.T) {
+	h := bottest.New(t, fm.Module)
+	h.Link(h.User, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+	h.LastFM.Handle(api.UserGetRecentTracksMethod, `<recenttracks user="rj" page="1" perPage="1" totalPages="0" total="0"></recenttracks>`)
+
+	res := h.Slash("fm").Run()
+	bottest.Golden(t, "no_scrobbles", res.String())
+}
+
+func TestNotRegistered(t *testing.T) {
+	h := bottest.New(t, fm.Module)
+
+	res := h.Slash("fm").Run()
+	bottest.Golden(t, "not_registered", res.String())
+}
@@ -0,0 +1,126 @@
+package leaderboard
+
+import (
+	"cmp"
+	"database/sql"
+	"net/url"
+	"slices"
+	"time"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
+	"first.fm/internal/persistence/sqlc"
+	"github.com/disgoorg/disgo/discord"
+)
+
+const (
+	// maxRefresh caps how many playcounts are fetched per run, since every
+	// one of them costs a Last.fm request. The stalest are fetched first, so
+	// large servers catch up over a few runs.
+	maxRefresh = 50
+	// playcountTTL is how long a stored playcount is ranked without
+	// fetching it again.
+	playcountTTL = time.Hour
+)
+
+// Module registers the /leaderboard command, which ranks the members of a
+// server by their scrobbles.
+func Module(r *bot.Registry) error {
+	return r.Register(data, handle,
+		bot.WithCooldown(bot.CooldownGuild, 30*time.Second),
+		bot.WithConcurrency(2),
+		bot.WithMiddleware(bot.GuildOnly, bot.AutoDefer(false)),
+	)
+}
+
+var data = discord.SlashCommandCreate{
+	Name:        "leaderboard",
+	Description: "rank the members of this server by their scrobbles",
+	Contexts:    []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+}
+
+type entry struct {
+	name      string
+	playcount int64
+}
+
+func handle(ctx *bot.CommandContext) error {
+	// every registered member, the ones with the stalest playcounts first
+	users, err := ctx.Queries.ListGuildPlaycounts(ctx.Ctx, *ctx.GuildID())
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return i18n.NewError("leaderboard.empty")
+	}
+
+	entries := make([]entry, 0, len(users))
+	refreshed, unranked := 0, 0
+	for _, u := range users {
+		if refreshed < maxRefresh && stale(u) && !nearDeadline(ctx) {
+			refreshed++
+			if playcount, ok := refresh(ctx, u.LastfmUsername); ok {
+				u.Playcount = sql.NullInt64{Int64: playcount, Valid: true}
+			}
+		}
+		if !u.Playcount.Valid {
+			unranked++
+			continue
+		}
+		entries = append(entries, entry{name: u.LastfmUsername, playcount: u.Playcount.Int64})
+	}
+	if len(entries) == 0 {
+		return i18n.NewError("leaderboard.failed")
+	}
+
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		return cmp.Compare(b.playcount, a.playcount)
+	})
+
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = ctx.T("leaderboard.entry", i+1, e.name, "https://www.last.fm/user/"+url.PathEscape(e.name), e.playcount)
+	}
+	header := ctx.T("leaderboard.header", len(entries))
+	if unranked > 0 {
+		header += "\n" + ctx.T("leaderboard.partial", unranked)
+	}
+	return ctx.Paginate(bot.NewListPaginator(header, lines))
+}
+
+// stale reports whether the playcount of u has to be fetched again.
+func stale(u sqlc.ListGuildPlaycountsRow) bool {
+	return !u.UpdatedAt.Valid || time.Since(u.UpdatedAt.Time) > playcountTTL
+}
+
+// nearDeadline reports whether the interaction is about to expire, after
+// which the members already fetched are ranked.
+func nearDeadline(ctx *bot.CommandContext) bool {
+	select {
+	case <-ctx.NearDeadline():
+		return true
+	default:
+		return false
+	}
+}
+
+// refresh fetches and stores the playcount of a Last.fm user.
+func refresh(ctx *bot.CommandContext, username string) (int64, bool) {
+	profile, err := ctx.LastFM.User.Info(ctx.Ctx, username)
+	if err != nil {
+		ctx.Log.Debugw("failed to get leaderboard user", logger.F{"user": username, "err": err.Error()})
+		return 0, false
+	}
+
+	playcount := int64(profile.Playcount)
+	err = ctx.Queries.SetPlaycount(ctx.Ctx, sqlc.SetPlaycountParams{
+		LastfmUsername: username,
+		Playcount:      playcount,
+		UpdatedAt:      time.Now(),
+	})
+	if err != nil {
+		ctx.Log.Warnw("failed to store playcount", logger.F{"user": username, "err": err.Error()})
+	}
+	return playcount, true
+}
@@ -0,0 +1,105 @@
+package leaderboard_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/leaderboard"
+	"first.fm/internal/lastfm/api"
+	"first.fm/internal/persistence/sqlc"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+var playcounts = map[string]int{"rj": 1234, "portishead": 98765, "thom": 4321}
+
+func userInfo(params url.Values) string {
+	name := params.Get("user")
+	return fmt.Sprintf(`<user><name>%s</name><url>https://www.last.fm/user/%s</url><playcount>%d</playcount></user>`, name, name, playcounts[name])
+}
+
+func join(t *testing.T, h *bottest.Harness, user snowflake.ID, username string) {
+	t.Helper()
+	h.Link(user, username)
+	err := h.Queries.AddGuildMember(context.Background(), sqlc.AddGuildMemberParams{GuildID: h.Guild, UserID: user})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	h := bottest.New(t, leaderboard.Module)
+	h.LastFM.HandleFunc(api.UserGetInfoMethod, userInfo)
+	join(t, h, h.User, "rj")
+	join(t, h, bottest.UserID+100, "portishead")
+	join(t, h, bottest.UserID+101, "thom")
+	// linked, but not a member of this server
+	h.Link(bottest.UserID+102, "someone")
+
+	res := h.Slash("leaderboard").Run()
+	bottest.Golden(t, "leaderboard", res.String())
+}
+
+func TestLeaderboardStoredPlaycounts(t *testing.T) {
+	h := bottest.New(t, leaderboard.Module)
+	h.LastFM.HandleFunc(api.UserGetInfoMethod, userInfo)
+	join(t, h, h.User, "rj")
+	join(t, h, bottest.UserID+100, "portishead")
+	join(t, h, bottest.UserID+101, "thom")
+	err := h.Queries.SetPlaycount(context.Background(), sqlc.SetPlaycountParams{
+		LastfmUsername: "rj",
+		Playcount:      1234,
+		UpdatedAt:      time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := h.Slash("leaderboard").Run()
+	bottest.Golden(t, "leaderboard", res.String())
+
+	for _, call := range h.LastFM.Calls() {
+		if call.Get("user") == "rj" {
+			t.Error("fetched a playcount that was stored recently")
+		}
+	}
+
+	// the fetched playcounts are stored for the next run
+	h.Slash("leaderboard").Run()
+	if n := len(h.LastFM.Calls()); n != 2 {
+		t.Errorf("made %d Last.fm calls over two runs, want 2", n)
+	}
+}
+
+func TestLeaderboardPartial(t *testing.T) {
+	h := bottest.New(t, leaderboard.Module)
+	h.LastFM.HandleFunc(api.UserGetInfoMethod, func(params url.Values) string {
+		if params.Get("user") == "thom" {
+			return `<user><playcount>not a number</playcount></user>`
+		}
+		return userInfo(params)
+	})
+	join(t, h, h.User, "rj")
+	join(t, h, bottest.UserID+100, "portishead")
+	join(t, h, bottest.UserID+101, "thom")
+
+	res := h.Slash("leaderboard").Run()
+	bottest.Golden(t, "partial", res.String())
+}
+
+func TestLeaderboardEmpty(t *testing.T) {
+	h := bottest.New(t, leaderboard.Module)
+
+	res := h.Slash("leaderboard").Run()
+	bottest.Golden(t, "empty", res.String())
+}
+
+func TestLeaderboardGuildOnly(t *testing.T) {
+	h := bottest.New(t, leaderboard.Module)
+
+	res := h.Slash("leaderboard").InDM().Run()
+	bottest.Golden(t, "guild_only", res.String())
+}
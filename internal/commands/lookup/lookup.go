@@ -0,0 +1,150 @@
+package lookup
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/emojis"
+	"first.fm/internal/i18n"
+	"first.fm/internal/lastfm"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// maxQueryLength keeps search queries from long messages reasonable, in
+// characters.
+const maxQueryLength = 200
+
+// Module registers the "Look up track" message command.
+func Module(r *bot.Registry) error {
+	return r.Register(data, handle,
+		bot.WithCooldown(bot.CooldownUser, 3*time.Second),
+		bot.WithMiddleware(bot.AutoDefer(false)),
+	)
+}
+
+// data is the context menu shown when right-clicking a message.
+var data = discord.MessageCommandCreate{
+	Name: "Look up track",
+	IntegrationTypes: []discord.ApplicationIntegrationType{
+		discord.ApplicationIntegrationTypeGuildInstall,
+		discord.ApplicationIntegrationTypeUserInstall,
+	},
+}
+
+var (
+	trackURLPattern = regexp.MustCompile(`last\.fm/(?:[a-z]{2}/)?music/([^/\s]+)/_/([^/\s?#>)]+)`)
+	linkPattern     = regexp.MustCompile(`<?https?://\S+>?`)
+	mentionPattern  = regexp.MustCompile(`<[@#:a]\S*?>`)
+	markdownReplace = strings.NewReplacer("*", "", "_", " ", "`", "", "~", "", "|", "", ">", "", "#", "")
+)
+
+func handle(ctx *bot.CommandContext) error {
+	message := ctx.MessageCommandInteractionData().TargetMessage()
+
+	track, err := findTrack(ctx, messageText(message))
+	if err != nil {
+		return err
+	}
+
+	title := discord.NewTextDisplayf("# %s", track.Title)
+	artist := discord.NewTextDisplayf("**%s** **·** *%s*", track.Artist.Name, track.Album.Title)
+	stats := discord.NewTextDisplay(ctx.T("lookup.stats", track.Listeners, track.Playcount))
+
+	// sections need an accessory, and tracks without an album have no cover
+	component := discord.NewContainer(title, artist, stats)
+	if cover := track.Album.Image.OriginalURL(); cover != "" {
+		component = discord.NewContainer(discord.NewSection(title, artist, stats).WithAccessory(discord.NewThumbnail(cover)))
+	}
+
+	_, err = ctx.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+		SetIsComponentsV2(true).
+		SetComponents(
+			component,
+			discord.NewActionRow(
+				discord.NewLinkButton("Last.fm", track.URL).WithEmoji(discord.NewCustomComponentEmoji(emojis.EmojiLastFMRed.Snowflake())),
+			),
+		).
+		Build())
+	return err
+}
+
+// messageText returns the text of a message including its embeds.
+func messageText(m discord.Message) string {
+	parts := []string{m.Content}
+	for _, e := range m.Embeds {
+		parts = append(parts, e.Title, e.Description)
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// findTrack looks up the track named in text. Last.fm track links are
+// resolved directly, "artist - title" lines are tried as is and anything
+// else goes through search.
+func findTrack(ctx *bot.CommandContext, text string) (*lastfm.TrackInfo, error) {
+	if text == "" {
+		return nil, i18n.NewError("lookup.no_text")
+	}
+
+	autocorrect := true
+	if m := trackURLPattern.FindStringSubmatch(text); m != nil {
+		artist, _ := url.QueryUnescape(m[1])
+		title, _ := url.QueryUnescape(m[2])
+		if info, err := ctx.LastFM.Track.Info(ctx.Ctx, lastfm.TrackInfoParams{Artist: artist, Track: title, AutoCorrect: &autocorrect}); err == nil {
+			return info, nil
+		}
+	}
+
+	query := cleanQuery(text)
+	if query == "" {
+		return nil, i18n.NewError("lookup.no_text")
+	}
+
+	if artist, title, ok := strings.Cut(query, " - "); ok {
+		info, err := ctx.LastFM.Track.Info(ctx.Ctx, lastfm.TrackInfoParams{
+			Artist:      strings.TrimSpace(artist),
+			Track:       strings.TrimSpace(title),
+			AutoCorrect: &autocorrect,
+		})
+		if err == nil {
+			return info, nil
+		}
+	}
+
+	res, err := ctx.LastFM.Search.All(ctx.Ctx, lastfm.SearchParams{
+		Query: query,
+		Kinds: []lastfm.SearchKind{lastfm.SearchKindTrack},
+		Limit: 5,
+	})
+	if err != nil || len(res.Hits) == 0 {
+		return nil, i18n.NewError("lookup.not_found")
+	}
+
+	hit := res.Hits[0]
+	return ctx.LastFM.Track.Info(ctx.Ctx, lastfm.TrackInfoParams{Artist: hit.Artist, Track: hit.Name})
+}
+
+// cleanQuery strips links, mentions and markdown and returns the first line
+// that looks like "artist - title", or the first non-empty line.
+func cleanQuery(text string) string {
+	text = linkPattern.ReplaceAllString(text, "")
+	text = mentionPattern.ReplaceAllString(text, "")
+	text = markdownReplace.Replace(text)
+
+	var first string
+	for line := range strings.Lines(text) {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if strings.Contains(line, " - ") {
+			return bot.Truncate(line, maxQueryLength)
+		}
+		if first == "" {
+			first = line
+		}
+	}
+	return bot.Truncate(first, maxQueryLength)
+}
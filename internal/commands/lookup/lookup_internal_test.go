@@ -0,0 +1,37 @@
+package lookup
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCleanQuery(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{"plain", "reckoner", "reckoner"},
+		{"markdown", "**Radiohead** - `Reckoner`", "Radiohead - Reckoner"},
+		{"links and mentions", "<@2001> listen https://example.com/x now", "listen now"},
+		{"artist title line preferred", "listening to\nRadiohead - Reckoner", "Radiohead - Reckoner"},
+		{"first line", "\n\n  reckoner  \nsomething else", "reckoner"},
+		{"only links", "<https://example.com>", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanQuery(tt.text); got != tt.want {
+				t.Errorf("cleanQuery(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanQueryTruncatesCharacters(t *testing.T) {
+	got := cleanQuery(strings.Repeat("é", maxQueryLength+50))
+	if !utf8.ValidString(got) {
+		t.Fatalf("cleanQuery split a character: %q", got)
+	}
+	if n := utf8.RuneCountInString(got); n != maxQueryLength {
+		t.Errorf("cleanQuery kept %d characters, want %d", n, maxQueryLength)
+	}
+}
@@ -0,0 +1,77 @@
+package lookup_test
+
+import (
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/lookup"
+	"first.fm/internal/lastfm/api"
+)
+
+const trackInfo = `<track>
+  <name>Reckoner</name>
+  <url>https://www.last.fm/music/Radiohead/_/Reckoner</url>
+  <listeners>1200000</listeners>
+  <playcount>24000000</playcount>
+  <artist><name>Radiohead</name><url>https://www.last.fm/music/Radiohead</url></artist>
+  <album position="7">
+    <artist>Radiohead</artist>
+    <title>In Rainbows</title>
+    <image size="extralarge">https://lastfm.freetls.fastly.net/i/u/300x300/cover.png</image>
+  </album>
+</track>`
+
+const trackSearch = `<results for="reckoner">
+  <trackmatches>
+    <track><name>Reckoner</name><artist>Radiohead</artist><url>https://www.last.fm/music/Radiohead/_/Reckoner</url></track>
+  </trackmatches>
+</results>`
+
+func TestLookupArtistTitle(t *testing.T) {
+	h := bottest.New(t, lookup.Module)
+	h.LastFM.Handle(api.TrackGetInfoMethod, trackInfo)
+
+	res := h.MessageCommand("Look up track", "**Radiohead - Reckoner** <https://example.com>").Run()
+	bottest.Golden(t, "artist_title", res.String())
+
+	calls := h.LastFM.Calls()
+	if len(calls) != 1 || calls[0].Get("artist") != "Radiohead" || calls[0].Get("track") != "Reckoner" {
+		t.Errorf("calls = %v, want one track.getInfo of Radiohead - Reckoner", calls)
+	}
+}
+
+func TestLookupTrackLink(t *testing.T) {
+	h := bottest.New(t, lookup.Module)
+	h.LastFM.Handle(api.TrackGetInfoMethod, trackInfo)
+
+	h.MessageCommand("Look up track", "https://www.last.fm/music/Radiohead/_/Reckoner").Run()
+
+	calls := h.LastFM.Calls()
+	if len(calls) != 1 || calls[0].Get("artist") != "Radiohead" || calls[0].Get("track") != "Reckoner" {
+		t.Errorf("calls = %v, want one track.getInfo of the linked track", calls)
+	}
+}
+
+func TestLookupSearch(t *testing.T) {
+	h := bottest.New(t, lookup.Module)
+	h.LastFM.Handle(api.TrackSearchMethod, trackSearch)
+	h.LastFM.Handle(api.TrackGetInfoMethod, trackInfo)
+
+	res := h.MessageCommand("Look up track", "reckoner").Run()
+	bottest.Golden(t, "search", res.String())
+}
+
+func TestLookupNotFound(t *testing.T) {
+	h := bottest.New(t, lookup.Module)
+	h.LastFM.Handle(api.TrackSearchMethod, `<results><trackmatches></trackmatches></results>`)
+
+	res := h.MessageCommand("Look up track", "nothing like this").Run()
+	bottest.Golden(t, "not_found", res.String())
+}
+
+func TestLookupNoText(t *testing.T) {
+	h := bottest.New(t, lookup.Module)
+
+	res := h.MessageCommand("Look up track", "<@2001> <https://example.com>").Run()
+	bottest.Golden(t, "no_text", res.String())
+}
@@ -0,0 +1,220 @@
+package plays
+
+import (
+	"errors"
+	"time"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/emojis"
+	"first.fm/internal/i18n"
+	"first.fm/internal/lastfm"
+	"first.fm/internal/lastfm/api"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// Module registers the /artistplays, /albumplays and /trackplays commands.
+func Module(r *bot.Registry) error {
+	if err := r.Register(artistData, bot.Handle(artist), options("artist", bot.ArtistAutocomplete)...); err != nil {
+		return err
+	}
+	if err := r.Register(albumData, bot.Handle(album), options("album", bot.AlbumAutocomplete)...); err != nil {
+		return err
+	}
+	return r.Register(trackData, bot.Handle(track), options("track", bot.TrackAutocomplete)...)
+}
+
+// options returns the command options shared by the commands, with the
+// named option suggesting from the user's top lists and Last.fm search.
+func options(option string, complete bot.AutocompleteHandler) []bot.CommandOption {
+	return []bot.CommandOption{
+		bot.WithCooldown(bot.CooldownUser, 3*time.Second),
+		bot.WithMiddleware(bot.AutoDefer(false)),
+		bot.WithAutocomplete(option, complete),
+	}
+}
+
+var integrationTypes = []discord.ApplicationIntegrationType{
+	discord.ApplicationIntegrationTypeGuildInstall,
+	discord.ApplicationIntegrationTypeUserInstall,
+}
+
+var artistData = discord.SlashCommandCreate{
+	Name:             "artistplays",
+	Description:      "display how many times someone played an artist",
+	IntegrationTypes: integrationTypes,
+	Options:          bot.OptionsOf[artistOptions](),
+}
+
+var albumData = discord.SlashCommandCreate{
+	Name:             "albumplays",
+	Description:      "display how many times someone played an album",
+	IntegrationTypes: integrationTypes,
+	Options:          bot.OptionsOf[albumOptions](),
+}
+
+var trackData = discord.SlashCommandCreate{
+	Name:             "trackplays",
+	Description:      "display how many times someone played a track",
+	IntegrationTypes: integrationTypes,
+	Options:          bot.OptionsOf[trackOptions](),
+}
+
+type artistOptions struct {
+	Artist string         `option:"artist" description:"artist to count the plays of" required:"true"`
+	User   bot.LastFMUser `option:"user" description:"user to count the plays of"`
+}
+
+type albumOptions struct {
+	Album string         `option:"album" description:"album to count the plays of" required:"true"`
+	User  bot.LastFMUser `option:"user" description:"user to count the plays of"`
+}
+
+type trackOptions struct {
+	Track string         `option:"track" description:"track to count the plays of" required:"true"`
+	User  bot.LastFMUser `option:"user" description:"user to count the plays of"`
+}
+
+func artist(ctx *bot.CommandContext, opts artistOptions) error {
+	_, name := lastfm.ParseSearchValue(opts.Artist)
+
+	autocorrect := true
+	info, err := ctx.LastFM.Artist.UserInfo(ctx.Ctx, lastfm.ArtistUserInfoParams{
+		Artist:      name,
+		User:        opts.User.Name,
+		AutoCorrect: &autocorrect,
+	})
+	if err != nil {
+		return notFound(err, name)
+	}
+	return reply(ctx, playsCard{
+		title:     info.Name,
+		url:       info.URL,
+		image:     info.Image.OriginalURL(),
+		user:      opts.User.Name,
+		playcount: info.UserPlaycount,
+	})
+}
+
+func album(ctx *bot.CommandContext, opts albumOptions) error {
+	artist, name, err := resolve(ctx, opts.Album, lastfm.SearchKindAlbum)
+	if err != nil {
+		return err
+	}
+
+	autocorrect := true
+	info, err := ctx.LastFM.Album.UserInfo(ctx.Ctx, lastfm.AlbumUserInfoParams{
+		Artist:      artist,
+		Album:       name,
+		User:        opts.User.Name,
+		AutoCorrect: &autocorrect,
+	})
+	if err != nil {
+		return notFound(err, opts.Album)
+	}
+	return reply(ctx, playsCard{
+		title:     info.Title,
+		artist:    info.Artist,
+		url:       info.URL,
+		image:     info.Image.OriginalURL(),
+		user:      opts.User.Name,
+		playcount: info.UserPlaycount,
+	})
+}
+
+func track(ctx *bot.CommandContext, opts trackOptions) error {
+	artist, name, err := resolve(ctx, opts.Track, lastfm.SearchKindTrack)
+	if err != nil {
+		return err
+	}
+
+	autocorrect := true
+	info, err := ctx.LastFM.Track.UserInfo(ctx.Ctx, lastfm.TrackUserInfoParams{
+		Artist:      artist,
+		Track:       name,
+		User:        opts.User.Name,
+		AutoCorrect: &autocorrect,
+	})
+	if err != nil {
+		return notFound(err, opts.Track)
+	}
+	return reply(ctx, playsCard{
+		title:     info.Title,
+		artist:    info.Artist.Name,
+		url:       info.URL,
+		image:     info.Album.Image.OriginalURL(),
+		user:      opts.User.Name,
+		playcount: info.UserPlaycount,
+	})
+}
+
+// resolve splits an album or track option into artist and name. Values
+// picked from the autocomplete choices carry their artist, while typed ones
+// are looked up with search and resolve to the best match.
+func resolve(ctx *bot.CommandContext, value string, kind lastfm.SearchKind) (artist, name string, err error) {
+	artist, name = lastfm.ParseSearchValue(value)
+	if artist != "" {
+		return artist, name, nil
+	}
+
+	res, err := ctx.LastFM.Search.All(ctx.Ctx, lastfm.SearchParams{
+		Query: name,
+		Kinds: []lastfm.SearchKind{kind},
+		Limit: 1,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if len(res.Hits) == 0 {
+		return "", "", i18n.NewError("plays.not_found", name)
+	}
+	return res.Hits[0].Artist, res.Hits[0].Name, nil
+}
+
+// notFound turns the error Last.fm returns for unknown names into a reply.
+func notFound(err error, name string) error {
+	if errors.Is(err, api.NewLastFMError(api.ErrInvalidParameters, "")) {
+		return i18n.NewError("plays.not_found", name)
+	}
+	return err
+}
+
+type playsCard struct {
+	title, artist, url, image string
+	user                      string
+	playcount                 int
+}
+
+func reply(ctx *bot.CommandContext, card playsCard) error {
+	texts := []discord.TextDisplayComponent{discord.NewTextDisplayf("# %s", card.title)}
+	if card.artist != "" {
+		texts = append(texts, discord.NewTextDisplayf("**%s**", card.artist))
+	}
+	texts = append(texts, discord.NewTextDisplay(ctx.T("plays.count", card.user, card.playcount)))
+
+	// sections need an accessory, and Last.fm often has no image
+	var component discord.ContainerComponent
+	if card.image != "" {
+		section := make([]discord.SectionSubComponent, len(texts))
+		for i, t := range texts {
+			section[i] = t
+		}
+		component = discord.NewContainer(discord.NewSection(section...).WithAccessory(discord.NewThumbnail(card.image)))
+	} else {
+		container := make([]discord.ContainerSubComponent, len(texts))
+		for i, t := range texts {
+			container[i] = t
+		}
+		component = discord.NewContainer(container...)
+	}
+
+	_, err := ctx.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+		SetIsComponentsV2(true).
+		SetComponents(
+			component,
+			discord.NewActionRow(
+				discord.NewLinkButton("Last.fm", card.url).WithEmoji(discord.NewCustomComponentEmoji(emojis.EmojiLastFMRed.Snowflake())),
+			),
+		).
+		Build())
+	return err
+}
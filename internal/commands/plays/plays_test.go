@@ -0,0 +1,106 @@
+package plays_test
+
+import (
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/plays"
+	"first.fm/internal/lastfm/api"
+)
+
+const artistInfo = `<artist>
+  <name>Radiohead</name>
+  <url>https://www.last.fm/music/Radiohead</url>
+  <stats><listeners>7000000</listeners><playcount>900000000</playcount><userplaycount>4242</userplaycount></stats>
+</artist>`
+
+const trackInfo = `<track>
+  <name>Reckoner</name>
+  <url>https://www.last.fm/music/Radiohead/_/Reckoner</url>
+  <artist><name>Radiohead</name><url>https://www.last.fm/music/Radiohead</url></artist>
+  <album position="7">
+    <artist>Radiohead</artist>
+    <title>In Rainbows</title>
+    <image size="extralarge">https://lastfm.freetls.fastly.net/i/u/300x300/cover.png</image>
+  </album>
+  <userplaycount>97</userplaycount>
+  <userloved>1</userloved>
+</track>`
+
+const trackSearch = `<results for="reckoner">
+  <trackmatches>
+    <track><name>Reckoner</name><artist>Radiohead</artist><url>https://www.last.fm/music/Radiohead/_/Reckoner</url></track>
+  </trackmatches>
+</results>`
+
+const topArtists = `<topartists user="rj" page="1" perPage="25" totalPages="1" total="2">
+  <artist rank="1"><name>Radiohead</name><playcount>4242</playcount><url>https://www.last.fm/music/Radiohead</url></artist>
+  <artist rank="2"><name>Portishead</name><playcount>1337</playcount><url>https://www.last.fm/music/Portishead</url></artist>
+</topartists>`
+
+func newHarness(t *testing.T) *bottest.Harness {
+	h := bottest.New(t, plays.Module)
+	h.Link(h.User, "rj")
+	return h
+}
+
+func TestArtistPlays(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.Handle(api.ArtistGetInfoMethod, artistInfo)
+
+	res := h.Slash("artistplays").Option("artist", "radiohead").Run()
+	bottest.Golden(t, "artist", res.String())
+}
+
+func TestTrackPlaysFromChoice(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.Handle(api.TrackGetInfoMethod, trackInfo)
+
+	res := h.Slash("trackplays").Option("track", "Radiohead\x1fReckoner").Run()
+	bottest.Golden(t, "track", res.String())
+
+	// the user option only needs the linked username
+	if n := len(h.LastFM.Calls()); n != 1 {
+		t.Errorf("made %d Last.fm calls, want 1 without searching or fetching the profile", n)
+	}
+}
+
+func TestTrackPlaysFromSearch(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.Handle(api.TrackSearchMethod, trackSearch)
+	h.LastFM.Handle(api.TrackGetInfoMethod, trackInfo)
+
+	res := h.Slash("trackplays").Option("track", "reckoner").Run()
+	bottest.Golden(t, "track", res.String())
+}
+
+func TestAlbumPlaysNotFound(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.Handle(api.AlbumSearchMethod, `<results><albummatches></albummatches></results>`)
+
+	res := h.Slash("albumplays").Option("album", "nothing like this").Run()
+	bottest.Golden(t, "album_not_found", res.String())
+}
+
+func TestAutocompleteTopArtists(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.Handle(api.UserGetTopArtistsMethod, topArtists)
+
+	res := h.Autocomplete("artistplays").Focus("artist", "").Run()
+	bottest.Golden(t, "autocomplete_top", res.String())
+}
+
+func TestAutocompleteSearch(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.Handle(api.TrackSearchMethod, trackSearch)
+
+	res := h.Autocomplete("trackplays").Focus("track", "reck").Run()
+	bottest.Golden(t, "autocomplete_search", res.String())
+}
+
+func TestAutocompleteNotRegistered(t *testing.T) {
+	h := bottest.New(t, plays.Module)
+
+	res := h.Autocomplete("artistplays").Focus("artist", "").Run()
+	bottest.Golden(t, "autocomplete_not_registered", res.String())
+}
@@ -0,0 +1,80 @@
+package prefix
+
+import (
+	"time"
+
+	"first.fm/internal/bot"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// Module registers the /prefix command, which picks the prefix members of a
+// server can run commands with by message. It is only served when message
+// prefixes are enabled.
+func Module(r *bot.Registry) error {
+	return r.RegisterTree(tree,
+		bot.WithCooldown(bot.CooldownGuild, 5*time.Second),
+		bot.WithMiddleware(bot.GuildOnly),
+	)
+}
+
+// manageServer guards the subcommands changing the prefix.
+var manageServer = []bot.Middleware{bot.RequirePermissions(discord.PermissionManageGuild)}
+
+var tree = bot.CommandTree{
+	SlashCommandCreate: discord.SlashCommandCreate{
+		Name:        "prefix",
+		Description: "run commands by message in this server",
+		Contexts:    []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+	},
+	SubCommands: []bot.SubCommand{
+		{
+			Name:        "show",
+			Description: "show the prefix of this server",
+			Handler:     show,
+		},
+		bot.NewSubCommand("set", "let members run commands by message with a prefix", set).
+			With(manageServer...),
+		{
+			Name:        "off",
+			Description: "stop running commands by message in this server",
+			Handler:     off,
+			Middleware:  manageServer,
+		},
+	},
+}
+
+type setOptions struct {
+	Prefix string `option:"prefix" description:"text that starts a command, such as . or !" required:"true" min:"1" max:"5"`
+}
+
+func show(ctx *bot.CommandContext) error {
+	prefix := ctx.MessagePrefix(*ctx.GuildID())
+	if prefix == "" {
+		return reply(ctx, ctx.T("prefix.none"))
+	}
+	return reply(ctx, ctx.T("prefix.current", prefix, prefix))
+}
+
+func set(ctx *bot.CommandContext, opts setOptions) error {
+	if !bot.ValidPrefix(opts.Prefix) {
+		return bot.ErrInvalidPrefix
+	}
+	if err := ctx.SetMessagePrefix(ctx.Ctx, *ctx.GuildID(), opts.Prefix); err != nil {
+		return err
+	}
+	return reply(ctx, ctx.T("prefix.set", opts.Prefix, opts.Prefix))
+}
+
+func off(ctx *bot.CommandContext) error {
+	if err := ctx.SetMessagePrefix(ctx.Ctx, *ctx.GuildID(), ""); err != nil {
+		return err
+	}
+	return reply(ctx, ctx.T("prefix.off"))
+}
+
+func reply(ctx *bot.CommandContext, content string) error {
+	return ctx.CreateMessage(discord.NewMessageCreateBuilder().
+		SetContent(content).
+		SetEphemeral(true).
+		Build())
+}
@@ -0,0 +1,48 @@
+package prefix_test
+
+import (
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/prefix"
+	"github.com/disgoorg/disgo/discord"
+)
+
+func TestPrefixSet(t *testing.T) {
+	h := bottest.New(t, prefix.Module)
+
+	res := h.Slash("prefix set").Option("prefix", ".").Permissions(discord.PermissionManageGuild).Run()
+	bottest.Golden(t, "set", res.String())
+
+	if got := h.Bot.MessagePrefix(h.Guild); got != "." {
+		t.Errorf("prefix = %q, want .", got)
+	}
+}
+
+func TestPrefixShow(t *testing.T) {
+	h := bottest.New(t, prefix.Module)
+
+	res := h.Slash("prefix show").Run()
+	bottest.Golden(t, "show_none", res.String())
+}
+
+func TestPrefixInvalid(t *testing.T) {
+	h := bottest.New(t, prefix.Module)
+
+	res := h.Slash("prefix set").Option("prefix", "a b").Permissions(discord.PermissionManageGuild).Run()
+	bottest.Golden(t, "invalid", res.String())
+}
+
+func TestPrefixNeedsManageServer(t *testing.T) {
+	h := bottest.New(t, prefix.Module)
+
+	res := h.Slash("prefix set").Option("prefix", ".").Run()
+	bottest.Golden(t, "missing_permissions", res.String())
+}
+
+func TestPrefixGuildOnly(t *testing.T) {
+	h := bottest.New(t, prefix.Module)
+
+	res := h.Slash("prefix show").InDM().Run()
+	bottest.Golden(t, "guild_only", res.String())
+}
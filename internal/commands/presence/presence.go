@@ -0,0 +1,45 @@
+package presence
+
+import (
+	"time"
+
+	"first.fm/internal/bot"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// Module registers the /presence command.
+func Module(r *bot.Registry) error {
+	return r.Register(data, bot.Handle(handle),
+		bot.WithCooldown(bot.CooldownUser, 10*time.Second),
+		bot.WithMiddleware(bot.RequireRegistration),
+	)
+}
+
+var data = discord.SlashCommandCreate{
+	Name:        "presence",
+	Description: "choose whether your scrobbles may show up in the bot's status",
+	Options:     bot.OptionsOf[options](),
+}
+
+type options struct {
+	Show bool `option:"show" description:"show what you're listening to in the bot's status" required:"true"`
+}
+
+func handle(ctx *bot.CommandContext, opts options) error {
+	key := "presence.hidden"
+	var err error
+	if opts.Show {
+		key = "presence.shown"
+		err = ctx.Queries.SetPresenceOptIn(ctx.Ctx, ctx.User().ID)
+	} else {
+		err = ctx.Queries.DeletePresenceOptIn(ctx.Ctx, ctx.User().ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.CreateMessage(discord.NewMessageCreateBuilder().
+		SetContent(ctx.T(key)).
+		SetEphemeral(true).
+		Build())
+}
@@ -0,0 +1,53 @@
+package presence_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/presence"
+)
+
+func optedIn(t *testing.T, h *bottest.Harness) bool {
+	t.Helper()
+	names, err := h.Queries.ListPresenceUsers(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return slices.Contains(names, "rj")
+}
+
+func TestPresenceShow(t *testing.T) {
+	h := bottest.New(t, presence.Module)
+	h.Link(h.User, "rj")
+
+	res := h.Slash("presence").Option("show", true).Run()
+	bottest.Golden(t, "show", res.String())
+
+	if !optedIn(t, h) {
+		t.Error("user didn't opt in")
+	}
+}
+
+func TestPresenceHide(t *testing.T) {
+	h := bottest.New(t, presence.Module)
+	h.Link(h.User, "rj")
+	if err := h.Queries.SetPresenceOptIn(context.Background(), h.User); err != nil {
+		t.Fatal(err)
+	}
+
+	res := h.Slash("presence").Option("show", false).Run()
+	bottest.Golden(t, "hide", res.String())
+
+	if optedIn(t, h) {
+		t.Error("user is still opted in")
+	}
+}
+
+func TestPresenceNotRegistered(t *testing.T) {
+	h := bottest.New(t, presence.Module)
+
+	res := h.Slash("presence").Option("show", true).Run()
+	bottest.Golden(t, "not_registered", res.String())
+}
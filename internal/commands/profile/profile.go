@@ -1,13 +1,25 @@
 package profile
 
 import (
+	"time"
+
 	"first.fm/internal/bot"
 	"first.fm/internal/emojis"
+	"first.fm/internal/lastfm"
 	"github.com/disgoorg/disgo/discord"
 )
 
-func init() {
-	bot.Register(data, handle)
+// Module registers the /profile command and its "Profile" context menu.
+func Module(r *bot.Registry) error {
+	opts := []bot.CommandOption{
+		bot.WithCooldown(bot.CooldownUser, 5*time.Second),
+		bot.WithConcurrency(10),
+		bot.WithMiddleware(bot.AutoDefer(false)),
+	}
+	if err := r.Register(data, bot.Handle(handle), opts...); err != nil {
+		return err
+	}
+	return r.Register(userData, bot.Handle(handle), opts...)
 }
 
 var data = discord.SlashCommandCreate{
@@ -17,42 +29,41 @@ var data = discord.SlashCommandCreate{
 		discord.ApplicationIntegrationTypeGuildInstall,
 		discord.ApplicationIntegrationTypeUserInstall,
 	},
-	Options: []discord.ApplicationCommandOption{
-		discord.ApplicationCommandOptionString{
-			Name:        "user",
-			Description: "user to get profile from",
-			Required:    false,
-		},
-	},
+	Options: bot.OptionsOf[options](),
 }
 
-func handle(ctx *bot.CommandContext) error {
-	err := ctx.DeferCreateMessage(false)
-	if err != nil {
-		return err
-	}
+type options struct {
+	User *lastfm.UserInfo `option:"user" description:"user to get profile from"`
+}
 
-	user, err := ctx.GetLastFMUser("")
-	if err != nil {
-		return err
-	}
+// userData is the context menu shown when right-clicking a member.
+var userData = discord.UserCommandCreate{
+	Name: "Profile",
+	IntegrationTypes: []discord.ApplicationIntegrationType{
+		discord.ApplicationIntegrationTypeGuildInstall,
+		discord.ApplicationIntegrationTypeUserInstall,
+	},
+}
+
+func handle(ctx *bot.CommandContext, opts options) error {
+	user := opts.User
 
 	component := []discord.LayoutComponent{
 		discord.NewContainer(
 			discord.NewSection(
 				discord.NewTextDisplayf("## [%s](%s)", user.Name, user.URL),
-				discord.NewTextDisplayf("Since <t:%d:D> %s", user.RegisteredAt.Time().Unix(), emojis.EmojiCalendar),
-				discord.NewTextDisplayf("**%d** total scrobbles %s", user.Playcount, emojis.EmojiPlay),
+				discord.NewTextDisplayf("%s %s", ctx.T("profile.since", user.RegisteredAt.Time().Unix()), emojis.EmojiCalendar),
+				discord.NewTextDisplayf("%s %s", ctx.T("profile.scrobbles", user.Playcount), emojis.EmojiPlay),
 			).WithAccessory(discord.NewThumbnail(user.Avatar.OriginalURL())),
 			discord.NewSmallSeparator(),
 			discord.NewTextDisplayf(
-				"%s **%d** albums\n%s **%d** artists\n%s **%d** unique tracks",
+				"%s %s\n%s %s\n%s %s",
 				emojis.EmojiAlbum,
-				user.ArtistCount,
+				ctx.T("profile.albums", user.AlbumCount),
 				emojis.EmojiMic2,
-				user.AlbumCount,
+				ctx.T("profile.artists", user.ArtistCount),
 				emojis.EmojiNote,
-				user.TrackCount,
+				ctx.T("profile.tracks", user.TrackCount),
 			),
 		).WithAccentColor(0x00ADD8),
 		discord.NewActionRow(
@@ -60,7 +71,7 @@ func handle(ctx *bot.CommandContext) error {
 		),
 	}
 
-	_, err = ctx.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+	_, err := ctx.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
 		SetIsComponentsV2(true).
 		SetComponents(component...).
 		Build())
@@ -0,0 +1,82 @@
+package profile_test
+
+import (
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/profile"
+	"first.fm/internal/lastfm/api"
+)
+
+const userInfo = `<user>
+  <name>rj</name>
+  <url>https://www.last.fm/user/rj</url>
+  <playcount>150316</playcount>
+  <artist_count>4211</artist_count>
+  <album_count>9002</album_count>
+  <track_count>30125</track_count>
+  <image size="small">https://lastfm.freetls.fastly.net/i/u/34s/avatar.png</image>
+  <image size="extralarge">https://lastfm.freetls.fastly.net/i/u/300x300/avatar.png</image>
+  <registered unixtime="1037793040">2002-11-20 11:50</registered>
+</user>`
+
+func TestProfile(t *testing.T) {
+	h := bottest.New(t, profile.Module)
+	h.Link(h.User, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+
+	res := h.Slash("profile").Run()
+	bottest.Golden(t, "profile", res.String())
+}
+
+func TestProfileByUsername(t *testing.T) {
+	h := bottest.New(t, profile.Module)
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+
+	res := h.Slash("profile").Option("user", "rj").Run()
+	bottest.Golden(t, "by_username", res.String())
+}
+
+func TestProfileByMemberName(t *testing.T) {
+	h := bottest.New(t, profile.Module)
+	h.Member(3001, "Alice")
+	h.Link(3001, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+
+	res := h.Slash("profile").Option("user", "alice").Run()
+	bottest.Golden(t, "by_username", res.String())
+
+	if n := h.MemberSearches(); n != 0 {
+		t.Errorf("searched Discord %d times for an indexed member", n)
+	}
+}
+
+func TestProfileByUnindexedMemberName(t *testing.T) {
+	h := bottest.New(t, profile.Module)
+	h.UnindexedMember(3001, "Alice")
+	h.Link(3001, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+
+	res := h.Slash("profile").Option("user", "alice").Run()
+	bottest.Golden(t, "by_username", res.String())
+
+	if n := h.MemberSearches(); n != 1 {
+		t.Errorf("searched Discord %d times, want 1 for a member missing from the index", n)
+	}
+}
+
+func TestProfileLocalized(t *testing.T) {
+	h := bottest.New(t, profile.Module)
+	h.Link(h.User, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+
+	res := h.Slash("profile").Locale("pt-BR").Run()
+	bottest.Golden(t, "localized", res.String())
+}
+
+func TestProfileUnknownUser(t *testing.T) {
+	h := bottest.New(t, profile.Module)
+
+	res := h.Slash("profile").Option("user", "nobody").Run()
+	bottest.Golden(t, "unknown_user", res.String())
+}
@@ -0,0 +1,58 @@
+package recent
+
+import (
+	"time"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/i18n"
+	"first.fm/internal/lastfm"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// limit is how many tracks are fetched, shown bot.ListPageSize per page.
+const limit = 100
+
+// Module registers the /recent command.
+func Module(r *bot.Registry) error {
+	return r.Register(data, bot.Handle(handle),
+		bot.WithCooldown(bot.CooldownUser, 5*time.Second),
+		bot.WithMiddleware(bot.AutoDefer(false)),
+	)
+}
+
+var data = discord.SlashCommandCreate{
+	Name:        "recent",
+	Description: "display someone's recent tracks",
+	IntegrationTypes: []discord.ApplicationIntegrationType{
+		discord.ApplicationIntegrationTypeGuildInstall,
+		discord.ApplicationIntegrationTypeUserInstall,
+	},
+	Options: bot.OptionsOf[options](),
+}
+
+type options struct {
+	User *lastfm.UserInfo `option:"user" description:"user to get recent tracks from"`
+}
+
+func handle(ctx *bot.CommandContext, opts options) error {
+	recent, err := ctx.LastFM.User.RecentTracks(ctx.Ctx, lastfm.RecentTracksParams{
+		User:  opts.User.Name,
+		Limit: limit,
+	})
+	if err != nil {
+		return i18n.NewError("recent.failed")
+	}
+	if len(recent.Tracks) == 0 {
+		return i18n.NewError("fm.no_scrobbles", opts.User.Name)
+	}
+
+	lines := make([]string, len(recent.Tracks))
+	for i, t := range recent.Tracks {
+		if t.NowPlaying {
+			lines[i] = ctx.T("recent.now_playing", t.Title, t.URL, t.Artist.Name)
+		} else {
+			lines[i] = ctx.T("recent.track", t.Title, t.URL, t.Artist.Name, t.ScrobbledAt.Unix())
+		}
+	}
+	return ctx.Paginate(bot.NewListPaginator(ctx.T("recent.header", opts.User.Name, opts.User.Playcount), lines))
+}
@@ -0,0 +1,45 @@
+package recent_test
+
+import (
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/recent"
+	"first.fm/internal/lastfm/api"
+)
+
+const userInfo = `<user><name>rj</name><url>https://www.last.fm/user/rj</url><playcount>1234</playcount></user>`
+
+const recentTracks = `<recenttracks user="rj" page="1" perPage="100" totalPages="1" total="2">
+  <track nowplaying="true">
+    <artist mbid="">Radiohead</artist>
+    <name>Reckoner</name>
+    <url>https://www.last.fm/music/Radiohead/_/Reckoner</url>
+  </track>
+  <track>
+    <artist mbid="">Portishead</artist>
+    <name>Roads</name>
+    <url>https://www.last.fm/music/Portishead/_/Roads</url>
+    <date uts="1700000000">14 Nov 2023, 22:13</date>
+  </track>
+</recenttracks>`
+
+func TestRecent(t *testing.T) {
+	h := bottest.New(t, recent.Module)
+	h.Link(h.User, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+	h.LastFM.Handle(api.UserGetRecentTracksMethod, recentTracks)
+
+	res := h.Slash("recent").Run()
+	bottest.Golden(t, "recent", res.String())
+}
+
+func TestRecentNoScrobbles(t *testing.T) {
+	h := bottest.New(t, recent.Module)
+	h.Link(h.User, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+	h.LastFM.Handle(api.UserGetRecentTracksMethod, `<recenttracks user="rj" page="1" perPage="100" totalPages="0" total="0"></recenttracks>`)
+
+	res := h.Slash("recent").Run()
+	bottest.Golden(t, "no_scrobbles", res.String())
+}
@@ -1,51 +1,76 @@
 package register
 
 import (
-	"errors"
-	"strings"
+	"time"
 
 	"first.fm/internal/bot"
+	"first.fm/internal/i18n"
+	"first.fm/internal/logger"
 	"first.fm/internal/persistence/sqlc"
 	"github.com/disgoorg/disgo/discord"
 )
 
-func init() {
-	bot.Register(data, handle)
+// Module registers the /register command.
+func Module(r *bot.Registry) error {
+	return r.Register(data, bot.Handle(handle), bot.WithCooldown(bot.CooldownUser, 10*time.Second))
 }
 
 var data = discord.SlashCommandCreate{
 	Name:        "register",
 	Description: "link your last.fm username",
-	Options: []discord.ApplicationCommandOption{
-		discord.ApplicationCommandOptionString{
-			Name:        "username",
-			Description: "your last.fm username",
-			Required:    true,
-		},
-	},
+	Options:     bot.OptionsOf[options](),
 }
 
-func handle(ctx *bot.CommandContext) error {
-	username := ctx.SlashCommandInteractionData().String("username")
+// options limits usernames to the 2 to 15 characters Last.fm allows.
+type options struct {
+	Username string `option:"username" description:"your last.fm username" required:"true" min:"2" max:"15"`
+}
+
+func handle(ctx *bot.CommandContext, opts options) error {
+	username := opts.Username
 
-	_, err := ctx.LastFM.User.Info(username)
+	_, err := ctx.LastFM.User.Info(ctx.Ctx, username)
 	if err != nil {
-		return errors.New("last.fm user not found")
+		return i18n.NewError("register.not_found")
+	}
+
+	err = link(ctx, username)
+	if sqlc.IsUniqueViolation(err) {
+		err = reclaim(ctx, username)
 	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.CreateMessage(discord.NewMessageCreateBuilder().
+		SetContent(ctx.T("register.success", username)).
+		Build())
+}
 
-	err = ctx.Queries.UpsertUser(ctx.Ctx, sqlc.UpsertUserParams{
+func link(ctx *bot.CommandContext, username string) error {
+	return ctx.Queries.UpsertUser(ctx.Ctx, sqlc.UpsertUserParams{
 		UserID:         ctx.User().ID,
 		LastfmUsername: username,
 	})
-	if err != nil {
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			return errors.New("another discord user already uses this username")
-		}
+}
 
+// reclaim links a username held by another Discord user when that account
+// was deleted, since nobody could unregister it anymore.
+func reclaim(ctx *bot.CommandContext, username string) error {
+	holder, err := ctx.Queries.GetUserByLastFM(ctx.Ctx, username)
+	if err != nil {
 		return err
 	}
+	deleted, err := ctx.AccountDeleted(holder.UserID)
+	if err != nil {
+		ctx.Log.Warnw("failed to look up username holder", logger.F{"user": holder.UserID, "err": err.Error()})
+	}
+	if !deleted {
+		return i18n.NewError("register.taken")
+	}
 
-	return ctx.CreateMessage(discord.NewMessageCreateBuilder().
-		SetContentf("successfully linked your account to **%s**", username).
-		Build())
+	if _, err := ctx.DeleteUserData(ctx.Ctx, holder.UserID); err != nil {
+		return err
+	}
+	return link(ctx, username)
 }
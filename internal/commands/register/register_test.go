@@ -0,0 +1,72 @@
+package register_test
+
+import (
+	"context"
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/register"
+	"first.fm/internal/lastfm/api"
+)
+
+const userInfo = `<user><name>rj</name><url>https://www.last.fm/user/rj</url></user>`
+
+func TestRegister(t *testing.T) {
+	h := bottest.New(t, register.Module)
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+
+	res := h.Slash("register").Option("username", "rj").Run()
+	bottest.Golden(t, "register", res.String())
+
+	user, err := h.Queries.GetUserByID(context.Background(), h.User)
+	if err != nil {
+		t.Fatalf("user wasn't linked: %v", err)
+	}
+	if user.LastfmUsername != "rj" {
+		t.Errorf("linked %q, want rj", user.LastfmUsername)
+	}
+}
+
+func TestRegisterUnknownUser(t *testing.T) {
+	h := bottest.New(t, register.Module)
+
+	res := h.Slash("register").Option("username", "nobody").Run()
+	bottest.Golden(t, "unknown_user", res.String())
+}
+
+func TestRegisterTaken(t *testing.T) {
+	h := bottest.New(t, register.Module)
+	h.Link(bottest.UserID+100, "rj")
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+
+	res := h.Slash("register").Option("username", "rj").Run()
+	bottest.Golden(t, "taken", res.String())
+}
+
+func TestRegisterReclaimsDeletedAccount(t *testing.T) {
+	h := bottest.New(t, register.Module)
+	holder := bottest.UserID + 100
+	h.Link(holder, "rj")
+	h.DeleteAccount(holder)
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+
+	res := h.Slash("register").Option("username", "rj").Run()
+	bottest.Golden(t, "reclaim", res.String())
+
+	user, err := h.Queries.GetUserByLastFM(context.Background(), "rj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.UserID != h.User {
+		t.Errorf("rj is linked to %s, want %s", user.UserID, h.User)
+	}
+}
+
+func TestRegisterCooldown(t *testing.T) {
+	h := bottest.New(t, register.Module)
+	h.LastFM.Handle(api.UserGetInfoMethod, userInfo)
+
+	h.Slash("register").Option("username", "rj").Run()
+	res := h.Slash("register").Option("username", "rj").Run()
+	bottest.Golden(t, "cooldown", res.String())
+}
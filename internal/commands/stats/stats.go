@@ -3,6 +3,7 @@ package stats
 import (
 	"fmt"
 	"runtime"
+	"strings"
 	"time"
 
 	"first.fm/internal/bot"
@@ -11,8 +12,9 @@ import (
 
 var startTime = time.Now()
 
-func init() {
-	bot.Register(data, handle)
+// Module registers the /stats command.
+func Module(r *bot.Registry) error {
+	return r.Register(data, handle)
 }
 
 var data = discord.SlashCommandCreate{
@@ -28,26 +30,36 @@ func handle(ctx *bot.CommandContext) error {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
 
-	statsText := fmt.Sprintf(
-		"uptime: %s\n"+
-			"goroutines: %d\n"+
-			"os threads: %d\n"+
-			"memory allocated: %s\n"+
-			"total allocated: %s\n"+
-			"system memory: %s\n"+
-			"gc runs: %d\n"+
-			"last gc pause: %.2fms\n"+
-			"go version: %s\n",
-		formatUptime(time.Since(startTime)),
-		runtime.NumGoroutine(),
-		runtime.NumCPU(),
-		formatBytes(m.Alloc),
-		formatBytes(m.TotalAlloc),
-		formatBytes(m.Sys),
-		m.NumGC,
-		float64(m.PauseNs[(m.NumGC+255)%256])/1e6,
-		runtime.Version(),
-	)
+	limits := ctx.Registry.LimiterStats()
+	running := 0
+	for _, n := range limits.Running {
+		running += n
+	}
+	// user cooldowns are kept in the database, shared with other processes
+	userCooldowns, err := ctx.Queries.CountCooldowns(ctx.Ctx, time.Now().UnixMilli())
+	if err != nil {
+		return err
+	}
+
+	lines := []string{
+		ctx.T("stats.uptime", formatUptime(time.Since(startTime))),
+		ctx.T("stats.goroutines", runtime.NumGoroutine()),
+		ctx.T("stats.cpus", runtime.NumCPU()),
+		ctx.T("stats.memory", formatBytes(m.Alloc)),
+		ctx.T("stats.total_memory", formatBytes(m.TotalAlloc)),
+		ctx.T("stats.system_memory", formatBytes(m.Sys)),
+		ctx.T("stats.gc_runs", m.NumGC),
+		ctx.T("stats.gc_pause", float64(m.PauseNs[(m.NumGC+255)%256])/1e6),
+		ctx.T("stats.go_version", runtime.Version()),
+		ctx.T("stats.cooldowns", limits.Cooldowns+int(userCooldowns)),
+		ctx.T("stats.throttled", limits.Throttled),
+		ctx.T("stats.busy", limits.Busy),
+		ctx.T("stats.running", running),
+	}
+	statsText := strings.Join(lines, "\n") + "\n"
+	if shards := ctx.ShardStats(); len(shards) > 0 {
+		statsText += "\n" + formatShards(ctx, shards)
+	}
 
 	component := discord.NewContainer(
 		discord.NewTextDisplay(statsText),
@@ -61,6 +73,17 @@ func handle(ctx *bot.CommandContext) error {
 	)
 }
 
+// formatShards lists the status, latency and guilds of each shard run by this
+// process.
+func formatShards(ctx *bot.CommandContext, shards []bot.ShardStat) string {
+	var b strings.Builder
+	b.WriteString(ctx.T("stats.shards", len(shards)) + "\n")
+	for _, s := range shards {
+		b.WriteString(ctx.T("stats.shard", s.ID, strings.ToLower(s.Status.String()), s.Latency.Milliseconds(), s.Guilds) + "\n")
+	}
+	return b.String()
+}
+
 func formatBytes(b uint64) string {
 	const unit = 1024
 	if b < unit {
@@ -0,0 +1,38 @@
+package stats_test
+
+import (
+	"regexp"
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/register"
+	"first.fm/internal/commands/stats"
+	"first.fm/internal/lastfm/api"
+)
+
+// runtimeStats matches the values describing the Go runtime, which change
+// between runs, up to the escaped newline ending their line.
+var runtimeStats = regexp.MustCompile(`((?:uptime|goroutines|cpus|memory allocated|total allocated|system memory|gc runs|last gc pause|go version): )[^\\]*`)
+
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	bottest.Golden(t, name, runtimeStats.ReplaceAllString(got, "${1}*"))
+}
+
+func TestStats(t *testing.T) {
+	h := bottest.New(t, stats.Module)
+
+	res := h.Slash("stats").Run()
+	golden(t, "stats", res.String())
+}
+
+func TestStatsCountsCooldowns(t *testing.T) {
+	h := bottest.New(t, stats.Module, register.Module)
+	h.LastFM.Handle(api.UserGetInfoMethod, `<user><name>rj</name></user>`)
+
+	h.Slash("register").Option("username", "rj").Run()
+	h.Slash("register").Option("username", "rj").Run()
+
+	res := h.Slash("stats").Run()
+	golden(t, "cooldowns", res.String())
+}
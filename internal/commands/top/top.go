@@ -0,0 +1,104 @@
+package top
+
+import (
+	"time"
+
+	"first.fm/internal/bot"
+	"first.fm/internal/i18n"
+	"first.fm/internal/lastfm"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// limit is how many entries are fetched, shown bot.ListPageSize per page.
+const limit = 100
+
+// Module registers the /top command, which lists someone's top artists,
+// albums and tracks.
+func Module(r *bot.Registry) error {
+	return r.RegisterTree(tree,
+		bot.WithCooldown(bot.CooldownUser, 5*time.Second),
+		bot.WithMiddleware(bot.AutoDefer(false)),
+	)
+}
+
+var tree = bot.CommandTree{
+	SlashCommandCreate: discord.SlashCommandCreate{
+		Name:        "top",
+		Description: "display someone's top lists",
+		IntegrationTypes: []discord.ApplicationIntegrationType{
+			discord.ApplicationIntegrationTypeGuildInstall,
+			discord.ApplicationIntegrationTypeUserInstall,
+		},
+	},
+	SubCommands: []bot.SubCommand{
+		bot.NewSubCommand("artists", "display someone's top artists", artists),
+		bot.NewSubCommand("albums", "display someone's top albums", albums),
+		bot.NewSubCommand("tracks", "display someone's top tracks", tracks),
+	},
+}
+
+type options struct {
+	Period lastfm.Period  `option:"period" description:"time period" default:"overall"`
+	User   bot.LastFMUser `option:"user" description:"user to get the top list from"`
+}
+
+func artists(ctx *bot.CommandContext, opts options) error {
+	top, err := ctx.LastFM.User.TopArtists(ctx.Ctx, lastfm.UserTopArtistsParams{
+		User:   opts.User.Name,
+		Period: opts.Period,
+		Limit:  limit,
+	})
+	if err != nil {
+		return bot.UserNotFound(err, opts.User.Name)
+	}
+
+	lines := make([]string, len(top.Artists))
+	for i, a := range top.Artists {
+		lines[i] = ctx.T("top.artist", a.Rank, a.Name, a.URL, a.Playcount)
+	}
+	return paginate(ctx, "top.artists", opts, lines)
+}
+
+func albums(ctx *bot.CommandContext, opts options) error {
+	top, err := ctx.LastFM.User.TopAlbums(ctx.Ctx, lastfm.UserTopAlbumsParams{
+		User:   opts.User.Name,
+		Period: opts.Period,
+		Limit:  limit,
+	})
+	if err != nil {
+		return bot.UserNotFound(err, opts.User.Name)
+	}
+
+	lines := make([]string, len(top.Albums))
+	for i, a := range top.Albums {
+		lines[i] = ctx.T("top.entry", a.Rank, a.Title, a.URL, a.Artist.Name, a.Playcount)
+	}
+	return paginate(ctx, "top.albums", opts, lines)
+}
+
+func tracks(ctx *bot.CommandContext, opts options) error {
+	top, err := ctx.LastFM.User.TopTracks(ctx.Ctx, lastfm.UserTopTracksParams{
+		User:   opts.User.Name,
+		Period: opts.Period,
+		Limit:  limit,
+	})
+	if err != nil {
+		return bot.UserNotFound(err, opts.User.Name)
+	}
+
+	lines := make([]string, len(top.Tracks))
+	for i, t := range top.Tracks {
+		lines[i] = ctx.T("top.entry", t.Rank, t.Title, t.URL, t.Artist.Name, t.Playcount)
+	}
+	return paginate(ctx, "top.tracks", opts, lines)
+}
+
+// paginate shows lines under the header with the given key, which is passed
+// the user and the period.
+func paginate(ctx *bot.CommandContext, header string, opts options, lines []string) error {
+	if len(lines) == 0 {
+		return i18n.NewError("top.empty", opts.User.Name)
+	}
+	period := ctx.T("periods." + string(opts.Period))
+	return ctx.Paginate(bot.NewListPaginator(ctx.T(header, opts.User.Name, period), lines))
+}
@@ -0,0 +1,91 @@
+package top_test
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/top"
+	"first.fm/internal/lastfm/api"
+)
+
+// topArtists answers with 12 artists, which is two pages.
+func topArtists(params url.Values) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<topartists user="rj" page="1" perPage="%s" totalPages="1" total="12">`, params.Get("limit"))
+	for i := 1; i <= 12; i++ {
+		fmt.Fprintf(&b, `<artist rank="%d"><name>Artist %d</name><playcount>%d</playcount><url>https://www.last.fm/music/Artist+%d</url></artist>`, i, i, 1000-i*10, i)
+	}
+	b.WriteString(`</topartists>`)
+	return b.String()
+}
+
+const topTracks = `<toptracks user="rj" page="1" perPage="100" totalPages="1" total="1">
+  <track rank="1">
+    <name>Reckoner</name>
+    <playcount>97</playcount>
+    <url>https://www.last.fm/music/Radiohead/_/Reckoner</url>
+    <artist><name>Radiohead</name></artist>
+  </track>
+</toptracks>`
+
+func newHarness(t *testing.T) *bottest.Harness {
+	h := bottest.New(t, top.Module)
+	h.Link(h.User, "rj")
+	return h
+}
+
+// button returns the raw custom ID of the button labelled label.
+func button(t *testing.T, res *bottest.Result, label string) string {
+	t.Helper()
+	for _, b := range res.Final().Find("button") {
+		if b.Text == label {
+			return b.CustomID
+		}
+	}
+	t.Fatalf("no %q button in\n%s", label, res)
+	return ""
+}
+
+func TestTopArtistsPages(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.HandleFunc(api.UserGetTopArtistsMethod, topArtists)
+
+	res := h.Slash("top artists").Option("period", "7day").Run()
+	bottest.Golden(t, "artists_page_1", res.String())
+
+	next := h.Click(button(t, res, "›")).Run()
+	bottest.Golden(t, "artists_page_2", next.String())
+
+	calls := h.LastFM.Calls()
+	if got := calls[len(calls)-1].Get("period"); got != "7day" {
+		t.Errorf("period = %q, want 7day", got)
+	}
+}
+
+func TestTopPagesOwnerOnly(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.HandleFunc(api.UserGetTopArtistsMethod, topArtists)
+
+	res := h.Slash("top artists").Run()
+	other := h.Click(button(t, res, "›")).As(bottest.UserID + 100).Run()
+	bottest.Golden(t, "other_user", other.String())
+}
+
+func TestTopTracks(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.Handle(api.UserGetTopTracksMethod, topTracks)
+
+	res := h.Slash("top tracks").Run()
+	bottest.Golden(t, "tracks", res.String())
+}
+
+func TestTopEmpty(t *testing.T) {
+	h := newHarness(t)
+	h.LastFM.Handle(api.UserGetTopAlbumsMethod, `<topalbums user="rj" page="1" perPage="100" totalPages="0" total="0"></topalbums>`)
+
+	res := h.Slash("top albums").Run()
+	bottest.Golden(t, "empty", res.String())
+}
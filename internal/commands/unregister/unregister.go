@@ -0,0 +1,72 @@
+package unregister
+
+import (
+	"first.fm/internal/bot"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// Module registers the /unregister command and its confirmation buttons.
+func Module(r *bot.Registry) error {
+	return r.Register(data, handle,
+		bot.WithMiddleware(bot.RequireRegistration),
+		bot.WithComponent("confirm", confirm),
+		bot.WithComponent("cancel", cancel),
+	)
+}
+
+var data = discord.SlashCommandCreate{
+	Name:        "unregister",
+	Description: "unlink your last.fm account and delete your data",
+	IntegrationTypes: []discord.ApplicationIntegrationType{
+		discord.ApplicationIntegrationTypeGuildInstall,
+		discord.ApplicationIntegrationTypeUserInstall,
+	},
+}
+
+func handle(ctx *bot.CommandContext) error {
+	user, err := ctx.Queries.GetUserByID(ctx.Ctx, ctx.User().ID)
+	if err != nil {
+		return err
+	}
+
+	confirmID, err := ctx.CustomID("confirm").Encode()
+	if err != nil {
+		return err
+	}
+	cancelID, err := ctx.CustomID("cancel").Encode()
+	if err != nil {
+		return err
+	}
+
+	return ctx.CreateMessage(discord.NewMessageCreateBuilder().
+		SetContent(ctx.T("unregister.confirm", user.LastfmUsername)).
+		AddActionRow(
+			discord.NewDangerButton(ctx.T("unregister.confirm_button"), confirmID),
+			discord.NewSecondaryButton(ctx.T("unregister.cancel_button"), cancelID),
+		).
+		SetEphemeral(true).
+		Build())
+}
+
+func confirm(ctx *bot.ComponentContext) error {
+	deleted, err := ctx.DeleteUserData(ctx.Ctx, ctx.User().ID)
+	if err != nil {
+		return err
+	}
+
+	key := "unregister.done"
+	if !deleted {
+		key = "unregister.not_registered"
+	}
+	return ctx.UpdateMessage(discord.NewMessageUpdateBuilder().
+		SetContent(ctx.T(key)).
+		ClearComponents().
+		Build())
+}
+
+func cancel(ctx *bot.ComponentContext) error {
+	return ctx.UpdateMessage(discord.NewMessageUpdateBuilder().
+		SetContent(ctx.T("unregister.cancelled")).
+		ClearComponents().
+		Build())
+}
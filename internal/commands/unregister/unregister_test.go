@@ -0,0 +1,70 @@
+package unregister_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"first.fm/internal/bot/bottest"
+	"first.fm/internal/commands/unregister"
+)
+
+// click presses the button labelled label in the final response of res.
+func click(t *testing.T, h *bottest.Harness, res *bottest.Result, label string) *bottest.Result {
+	t.Helper()
+	for _, b := range res.Final().Find("button") {
+		if b.Text == label {
+			return h.Click(b.CustomID).Run()
+		}
+	}
+	t.Fatalf("no %q button in\n%s", label, res)
+	return nil
+}
+
+func TestUnregisterConfirm(t *testing.T) {
+	h := bottest.New(t, unregister.Module)
+	h.Link(h.User, "rj")
+
+	res := h.Slash("unregister").Run()
+	bottest.Golden(t, "confirm_prompt", res.String())
+
+	res = click(t, h, res, "Unregister")
+	bottest.Golden(t, "confirmed", res.String())
+
+	if _, err := h.Queries.GetUserByID(context.Background(), h.User); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("user wasn't deleted: %v", err)
+	}
+}
+
+func TestUnregisterCancel(t *testing.T) {
+	h := bottest.New(t, unregister.Module)
+	h.Link(h.User, "rj")
+
+	res := click(t, h, h.Slash("unregister").Run(), "Cancel")
+	bottest.Golden(t, "cancelled", res.String())
+
+	if _, err := h.Queries.GetUserByID(context.Background(), h.User); err != nil {
+		t.Errorf("user was deleted: %v", err)
+	}
+}
+
+func TestUnregisterOtherUser(t *testing.T) {
+	h := bottest.New(t, unregister.Module)
+	h.Link(h.User, "rj")
+
+	buttons := h.Slash("unregister").Run().Final().Find("button")
+	if len(buttons) == 0 {
+		t.Fatal("no buttons in the confirmation prompt")
+	}
+
+	res := h.Click(buttons[0].CustomID).As(bottest.UserID + 100).Run()
+	bottest.Golden(t, "other_user", res.String())
+}
+
+func TestUnregisterNotRegistered(t *testing.T) {
+	h := bottest.New(t, unregister.Module)
+
+	res := h.Slash("unregister").Run()
+	bottest.Golden(t, "not_registered", res.String())
+}
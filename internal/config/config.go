@@ -0,0 +1,282 @@
+// Package config loads the bot settings from a TOML file and the
+// environment.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"first.fm/internal/logger"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// DefaultPath is the file Load reads when no path is given. It is optional.
+const DefaultPath = "config.toml"
+
+// PathEnv is the environment variable naming the config file.
+const PathEnv = "CONFIG"
+
+// Config holds every setting of the bot. Values come from Default, then the
+// config file, then the environment variables named by the env tags.
+type Config struct {
+	Discord  Discord  `toml:"discord"`
+	LastFM   LastFM   `toml:"lastfm"`
+	Log      Log      `toml:"log"`
+	Database Database `toml:"database"`
+	Cache    Cache    `toml:"cache"`
+	Commands Commands `toml:"commands"`
+	Presence Presence `toml:"presence"`
+	HTTP     HTTP     `toml:"http"`
+}
+
+type Discord struct {
+	Token string `toml:"token" env:"DISCORD_TOKEN"`
+	// Presence is the custom status shown under the bot's name. It is also
+	// shown while no presence template can be filled in.
+	Presence string `toml:"presence" env:"DISCORD_PRESENCE"`
+	// Owners may use owner-only commands.
+	Owners []snowflake.ID `toml:"owners" env:"OWNER_IDS"`
+	// DrainTimeout is how long shutdown waits for in-flight interactions.
+	DrainTimeout time.Duration `toml:"drain_timeout" env:"DRAIN_TIMEOUT"`
+	// Shards is the total number of shards. 0 uses the count Discord
+	// recommends, which requires a single process to run every shard.
+	Shards int `toml:"shards" env:"DISCORD_SHARDS"`
+	// ShardIDs are the shards this process runs, every shard when empty.
+	// Processes of one deployment share Shards and split the IDs.
+	ShardIDs []int `toml:"shard_ids" env:"DISCORD_SHARD_IDS"`
+}
+
+type LastFM struct {
+	APIKey  string        `toml:"api_key" env:"LASTFM_API_KEY"`
+	Timeout time.Duration `toml:"timeout" env:"LASTFM_TIMEOUT"`
+	// RequestsPerSecond and Burst limit the requests made to Last.fm.
+	RequestsPerSecond float64 `toml:"requests_per_second" env:"LASTFM_REQUESTS_PER_SECOND"`
+	Burst             int     `toml:"burst" env:"LASTFM_BURST"`
+}
+
+type Log struct {
+	// Level is one of debug, info, warn or error.
+	Level string `toml:"level" env:"LOG_LEVEL"`
+	// Format is text or json.
+	Format string `toml:"format" env:"LOG_FORMAT"`
+}
+
+type Database struct {
+	Path string `toml:"path" env:"DATABASE_PATH"`
+}
+
+// Cache holds the maximum number of entries of each cache. 0 is unbounded.
+type Cache struct {
+	LastFMUsers int `toml:"lastfm_users" env:"CACHE_LASTFM_USERS"`
+	Searches    int `toml:"searches" env:"CACHE_SEARCHES"`
+	Charts      int `toml:"charts" env:"CACHE_CHARTS"`
+	Cooldowns   int `toml:"cooldowns" env:"CACHE_COOLDOWNS"`
+	Paginators  int `toml:"paginators" env:"CACHE_PAGINATORS"`
+}
+
+type Commands struct {
+	// Mode is global, guilds or dev. It defaults to dev when DevGuild is set
+	// and global otherwise.
+	Mode     string         `toml:"mode" env:"COMMANDS_MODE"`
+	Guilds   []snowflake.ID `toml:"guilds" env:"COMMANDS_GUILDS"`
+	DevGuild snowflake.ID   `toml:"dev_guild" env:"GUILD_ID"`
+	// DryRun logs the command diff without pushing it to Discord.
+	DryRun bool `toml:"dry_run" env:"COMMANDS_DRY_RUN"`
+	// MessagePrefixes lets servers run the commands by message with a prefix
+	// of their choice, such as ".fm". It needs the message content intent.
+	MessagePrefixes bool `toml:"message_prefixes" env:"COMMANDS_MESSAGE_PREFIXES"`
+}
+
+// HTTP configures the server exposing /healthz, /readyz and /metrics.
+type HTTP struct {
+	// Addr is the address to listen on, such as ":8080". The server is
+	// disabled when it is empty.
+	Addr string `toml:"addr" env:"HTTP_ADDR"`
+}
+
+// Presence configures the rotating status. The bot keeps Discord.Presence
+// when there are no templates.
+type Presence struct {
+	// Templates are shown in turn. Placeholders such as {top_artist} are
+	// filled in from PresencePlaceholders and a template is skipped while one
+	// of its placeholders has no value.
+	Templates []string `toml:"templates" env:"PRESENCE_TEMPLATES"`
+	// Interval is how long each template is shown.
+	Interval time.Duration `toml:"interval" env:"PRESENCE_INTERVAL"`
+	// Sample is how many opted-in users are polled for their recent track on
+	// each refresh. Each poll is a Last.fm request.
+	Sample int `toml:"sample" env:"PRESENCE_SAMPLE"`
+}
+
+// PresencePlaceholders are the placeholders presence templates may use.
+var PresencePlaceholders = []string{
+	"users",      // registered users
+	"servers",    // servers the bot is in, across every shard
+	"top_artist", // most common artist in the polled users' latest tracks
+	"listener",   // Last.fm name of a polled user listening right now
+	"track",      // the track the listener is playing
+	"artist",     // the artist of that track
+}
+
+// MaxPresenceLength is the longest custom status Discord shows.
+const MaxPresenceLength = 128
+
+// presencePlaceholder matches the placeholders of a presence template.
+var presencePlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+// Default returns the settings used for everything the file and the
+// environment leave out.
+func Default() Config {
+	return Config{
+		Discord: Discord{
+			Presence:     "listen to crystal castles!",
+			DrainTimeout: 30 * time.Second,
+		},
+		LastFM: LastFM{
+			Timeout:           30 * time.Second,
+			RequestsPerSecond: 1,
+			Burst:             5,
+		},
+		Log: Log{
+			Level:  "info",
+			Format: "text",
+		},
+		Database: Database{
+			Path: "database.db",
+		},
+		Presence: Presence{
+			Interval: 5 * time.Minute,
+			Sample:   10,
+		},
+		Cache: Cache{
+			LastFMUsers: 1000,
+			Searches:    1000,
+			Charts:      100,
+			Cooldowns:   50000,
+			Paginators:  1000,
+		},
+	}
+}
+
+// Load reads the config file at path, applies the environment and validates
+// the result. An empty path reads PathEnv, then DefaultPath when it exists.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv(PathEnv)
+	}
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := decodeTOML(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("%s: %w", path, err)
+		}
+	case explicit || !errors.Is(err, os.ErrNotExist):
+		return cfg, err
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.Commands.Mode == "" {
+		cfg.Commands.Mode = "global"
+		if cfg.Commands.DevGuild != 0 {
+			cfg.Commands.Mode = "dev"
+		}
+	}
+	cfg.Commands.Mode = strings.ToLower(cfg.Commands.Mode)
+
+	return cfg, cfg.Validate()
+}
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(c.Discord.Token != "", "discord.token", "is required, set it in the config file or DISCORD_TOKEN")
+	check(c.Discord.DrainTimeout >= 0, "discord.drain_timeout", "must not be negative")
+	check(c.Discord.Shards >= 0, "discord.shards", "must not be negative, got %d", c.Discord.Shards)
+	if len(c.Discord.ShardIDs) > 0 {
+		check(c.Discord.Shards > 0, "discord.shards", "is required when discord.shard_ids is set")
+	}
+	for i, id := range c.Discord.ShardIDs {
+		key := fmt.Sprintf("discord.shard_ids[%d]", i)
+		check(id >= 0 && (c.Discord.Shards == 0 || id < c.Discord.Shards), key, "must be between 0 and discord.shards - 1, got %d", id)
+		check(!slices.Contains(c.Discord.ShardIDs[:i], id), key, "repeats shard %d", id)
+	}
+	check(c.LastFM.APIKey != "", "lastfm.api_key", "is required, set it in the config file or LASTFM_API_KEY")
+	check(c.LastFM.Timeout >= time.Second, "lastfm.timeout", "must be at least 1s, got %s", c.LastFM.Timeout)
+	check(c.LastFM.RequestsPerSecond > 0, "lastfm.requests_per_second", "must be positive, got %g", c.LastFM.RequestsPerSecond)
+	check(c.LastFM.Burst >= 1, "lastfm.burst", "must be at least 1, got %d", c.LastFM.Burst)
+
+	level, err := logger.ParseLevel(c.Log.Level)
+	check(err == nil && level != logger.LevelFatal, "log.level", "must be one of debug, info, warn or error, got %q", c.Log.Level)
+	check(slices.Contains([]string{"text", "json"}, c.Log.Format), "log.format", "must be text or json, got %q", c.Log.Format)
+	check(c.Database.Path != "", "database.path", "is required")
+
+	for key, size := range map[string]int{
+		"cache.lastfm_users": c.Cache.LastFMUsers,
+		"cache.searches":     c.Cache.Searches,
+		"cache.charts":       c.Cache.Charts,
+		"cache.cooldowns":    c.Cache.Cooldowns,
+		"cache.paginators":   c.Cache.Paginators,
+	} {
+		check(size >= 0, key, "must not be negative, got %d", size)
+	}
+
+	switch c.Commands.Mode {
+	case "global":
+	case "guilds":
+		check(len(c.Commands.Guilds) > 0, "commands.guilds", "needs at least one guild in guilds mode")
+	case "dev":
+		check(c.Commands.DevGuild != 0, "commands.dev_guild", "is required in dev mode")
+	default:
+		check(false, "commands.mode", "must be global, guilds or dev, got %q", c.Commands.Mode)
+	}
+
+	check(len(c.Discord.Presence) <= MaxPresenceLength, "discord.presence", "must be at most %d characters", MaxPresenceLength)
+	if len(c.Presence.Templates) > 0 {
+		check(c.Presence.Interval >= time.Minute, "presence.interval", "must be at least 1m, got %s", c.Presence.Interval)
+		check(c.Presence.Sample >= 1 && c.Presence.Sample <= 50, "presence.sample", "must be between 1 and 50, got %d", c.Presence.Sample)
+	}
+	for i, t := range c.Presence.Templates {
+		key := fmt.Sprintf("presence.templates[%d]", i)
+		check(strings.TrimSpace(t) != "", key, "is empty")
+		for _, m := range presencePlaceholder.FindAllStringSubmatch(t, -1) {
+			check(slices.Contains(PresencePlaceholders, m[1]), key, "unknown placeholder {%s}, use one of %s", m[1], strings.Join(PresencePlaceholders, ", "))
+		}
+	}
+
+	if c.HTTP.Addr != "" {
+		_, port, err := net.SplitHostPort(c.HTTP.Addr)
+		check(err == nil && port != "", "http.addr", "must be host:port or :port, got %q", c.HTTP.Addr)
+	}
+
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errors.Join(errs...)
+}
+
+// Apply configures l with the log level and format.
+func (c Log) Apply(l *logger.Logger) {
+	if level, err := logger.ParseLevel(c.Level); err == nil {
+		l.SetLevel(level)
+	}
+	l.SetJSON(c.Format == "json")
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+func decode(t *testing.T, doc string) Config {
+	t.Helper()
+	cfg := Default()
+	if err := decodeTOML([]byte(doc), &cfg); err != nil {
+		t.Fatalf("decodeTOML: %v", err)
+	}
+	return cfg
+}
+
+func TestDecodeExample(t *testing.T) {
+	data, err := os.ReadFile("../../config.example.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Default()
+	if err := decodeTOML(data, &cfg); err != nil {
+		t.Fatalf("config.example.toml: %v", err)
+	}
+	if cfg.Presence.Interval != 5*time.Minute || cfg.LastFM.RequestsPerSecond != 1 {
+		t.Errorf("example doesn't match the defaults: %+v", cfg)
+	}
+}
+
+func TestDecodeStrings(t *testing.T) {
+	cfg := decode(t, `
+[discord]
+presence = "a \"quoted\" # status" # a comment
+token = 'C:\literal'
+`)
+	if got, want := cfg.Discord.Presence, `a "quoted" # status`; got != want {
+		t.Errorf("presence = %q, want %q", got, want)
+	}
+	if got, want := cfg.Discord.Token, `C:\literal`; got != want {
+		t.Errorf("token = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeArrays(t *testing.T) {
+	cfg := decode(t, `
+[discord]
+owners = [
+  "123456789012345678", # a comment
+  234567890123456789,
+]
+shard_ids = [0, 1]
+
+[presence]
+templates = [
+  "{users} users, {servers} servers",
+  '{listener} is playing "{track}"',
+]
+`)
+	wantOwners := []snowflake.ID{123456789012345678, 234567890123456789}
+	if !slices.Equal(cfg.Discord.Owners, wantOwners) {
+		t.Errorf("owners = %v, want %v", cfg.Discord.Owners, wantOwners)
+	}
+	if !slices.Equal(cfg.Discord.ShardIDs, []int{0, 1}) {
+		t.Errorf("shard_ids = %v, want [0 1]", cfg.Discord.ShardIDs)
+	}
+	wantTemplates := []string{"{users} users, {servers} servers", `{listener} is playing "{track}"`}
+	if !slices.Equal(cfg.Presence.Templates, wantTemplates) {
+		t.Errorf("templates = %q, want %q", cfg.Presence.Templates, wantTemplates)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, tt := range []struct {
+		doc  string
+		want string
+	}{
+		{"[discrod]\ntoken = \"x\"", "unknown table [discrod]"},
+		{"[discord]\ntokn = \"x\"", "unknown key discord.tokn"},
+		{"token = \"x\"", "unknown key token"},
+		{"[lastfm]\nburst = \"5\"", "lastfm.burst: expected an integer, got a string"},
+		{"[lastfm]\ntimeout = 30", "lastfm.timeout: expected a duration string, got an integer"},
+		{"[lastfm]\ntimeout = \"soon\"", `lastfm.timeout: invalid duration "soon"`},
+		{"[discord]\nowners = \"1\"", "discord.owners: expected an array, got a string"},
+		{"[discord]\nowners = [true]", "discord.owners: item 1: expected an id, got a boolean"},
+		{"[discord]\ntoken = \"x", "toml:"},
+	} {
+		cfg := Default()
+		err := decodeTOML([]byte(tt.doc), &cfg)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("decodeTOML(%q) = %v, want %q", tt.doc, err, tt.want)
+		}
+	}
+}
+
+func TestEnvLists(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		owners    string
+		templates string
+
+		wantOwners    []snowflake.ID
+		wantTemplates []string
+	}{
+		{
+			name:          "comma separated ids and a single template",
+			owners:        "1, 2,3",
+			templates:     "{users} users, {servers} servers",
+			wantOwners:    []snowflake.ID{1, 2, 3},
+			wantTemplates: []string{"{users} users, {servers} servers"},
+		},
+		{
+			name:          "arrays",
+			owners:        `[1, "2"]`,
+			templates:     `["{users} users, {servers} servers", "{top_artist} is on repeat"]`,
+			wantOwners:    []snowflake.ID{1, 2},
+			wantTemplates: []string{"{users} users, {servers} servers", "{top_artist} is on repeat"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("OWNER_IDS", tt.owners)
+			t.Setenv("PRESENCE_TEMPLATES", tt.templates)
+
+			cfg := Default()
+			if err := applyEnv(&cfg); err != nil {
+				t.Fatalf("applyEnv: %v", err)
+			}
+			if !slices.Equal(cfg.Discord.Owners, tt.wantOwners) {
+				t.Errorf("owners = %v, want %v", cfg.Discord.Owners, tt.wantOwners)
+			}
+			if !slices.Equal(cfg.Presence.Templates, tt.wantTemplates) {
+				t.Errorf("templates = %q, want %q", cfg.Presence.Templates, tt.wantTemplates)
+			}
+		})
+	}
+}
+
+func TestEnvInvalidArray(t *testing.T) {
+	t.Setenv("PRESENCE_TEMPLATES", `["unterminated]`)
+	cfg := Default()
+	if err := applyEnv(&cfg); err == nil || !strings.HasPrefix(err.Error(), "PRESENCE_TEMPLATES: invalid array") {
+		t.Errorf("applyEnv = %v, want an invalid array error", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	doc := `
+[discord]
+token = "file token"
+
+[lastfm]
+api_key = "key"
+timeout = "10s"
+`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DISCORD_TOKEN", "env token")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Discord.Token != "env token" {
+		t.Errorf("token = %q, want the env variable to win", cfg.Discord.Token)
+	}
+	if cfg.LastFM.Timeout != 10*time.Second {
+		t.Errorf("timeout = %s, want 10s", cfg.LastFM.Timeout)
+	}
+	if cfg.Commands.Mode != "global" {
+		t.Errorf("mode = %q, want global", cfg.Commands.Mode)
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// applyEnv overrides the settings whose env tag names a set, non-empty
+// environment variable. Lists are TOML arrays such as ["a", "b"]. Lists of
+// IDs and numbers may also be comma separated, while any other value is a
+// list of one, so text like presence templates can contain commas.
+func applyEnv(cfg *Config) error {
+	return applyEnvTo(reflect.ValueOf(cfg).Elem())
+}
+
+func applyEnvTo(v reflect.Value) error {
+	for i := range v.NumField() {
+		field, sf := v.Field(i), v.Type().Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnvTo(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := sf.Tag.Get("env")
+		raw := strings.TrimSpace(os.Getenv(key))
+		if key == "" || raw == "" {
+			continue
+		}
+
+		if err := setEnv(field, raw); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func setEnv(field reflect.Value, raw string) error {
+	if field.Kind() != reflect.Slice {
+		return setString(field, raw)
+	}
+
+	if strings.HasPrefix(raw, "[") {
+		var doc struct{ Value any }
+		if _, err := toml.Decode("value = "+raw, &doc); err != nil {
+			return fmt.Errorf("invalid array: %w", err)
+		}
+		return setTOML(field, doc.Value)
+	}
+
+	items := []string{raw}
+	if commaSeparated(field.Type().Elem()) {
+		items = items[:0]
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := setString(slice.Index(i), item); err != nil {
+			return err
+		}
+	}
+	field.Set(slice)
+	return nil
+}
+
+// commaSeparated reports whether lists of t may be written without brackets
+// as comma separated values, which is the case unless a value is text that
+// may contain a comma.
+func commaSeparated(t reflect.Type) bool {
+	return t.Kind() != reflect.String
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+var (
+	durationType  = reflect.TypeFor[time.Duration]()
+	snowflakeType = reflect.TypeFor[snowflake.ID]()
+)
+
+// decodeTOML decodes a TOML document into dst. Tables and keys are matched to
+// the fields with the same toml tag, durations are strings such as "30s" and
+// IDs may be integers or strings. Unknown tables and keys are errors so typos
+// don't go unnoticed.
+func decodeTOML(data []byte, dst any) error {
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return err
+	}
+	return setTable(reflect.ValueOf(dst).Elem(), doc, "")
+}
+
+// setTable sets the fields of v from a decoded table. prefix is the dotted
+// name of the table, used in errors.
+func setTable(v reflect.Value, table map[string]any, prefix string) error {
+	for _, key := range slices.Sorted(maps.Keys(table)) {
+		name := prefix + key
+		value := table[key]
+		field, ok := fieldByTag(v, key)
+
+		sub, isTable := value.(map[string]any)
+		switch {
+		case isTable && (!ok || field.Kind() != reflect.Struct):
+			return fmt.Errorf("unknown table [%s]", name)
+		case isTable:
+			if err := setTable(field, sub, name+"."); err != nil {
+				return err
+			}
+		case !ok || field.Kind() == reflect.Struct:
+			return fmt.Errorf("unknown key %s", name)
+		default:
+			if err := setTOML(field, value); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// fieldByTag returns the field of v whose toml tag is name.
+func fieldByTag(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := range v.NumField() {
+		if v.Type().Field(i).Tag.Get("toml") == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setTOML sets field from a value decoded by the TOML library.
+func setTOML(field reflect.Value, value any) error {
+	if field.Kind() == reflect.Slice {
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("expected an array, got %s", tomlType(value))
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setTOML(slice.Index(i), item); err != nil {
+				return fmt.Errorf("item %d: %w", i+1, err)
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		if field.Kind() != reflect.String && field.Type() != durationType && field.Type() != snowflakeType {
+			return fmt.Errorf("expected %s, got a string", kindName(field))
+		}
+		return setString(field, v)
+	case int64:
+		switch {
+		case field.Type() == snowflakeType:
+			if v < 0 {
+				return fmt.Errorf("invalid id %d", v)
+			}
+			field.SetUint(uint64(v))
+		case field.CanInt() && field.Type() != durationType:
+			if field.OverflowInt(v) {
+				return fmt.Errorf("%d is out of range", v)
+			}
+			field.SetInt(v)
+		case field.CanFloat():
+			field.SetFloat(float64(v))
+		default:
+			return fmt.Errorf("expected %s, got an integer", kindName(field))
+		}
+	case float64:
+		if !field.CanFloat() {
+			return fmt.Errorf("expected %s, got a number", kindName(field))
+		}
+		field.SetFloat(v)
+	case bool:
+		if field.Kind() != reflect.Bool {
+			return fmt.Errorf("expected %s, got a boolean", kindName(field))
+		}
+		field.SetBool(v)
+	default:
+		return fmt.Errorf("expected %s, got %s", kindName(field), tomlType(value))
+	}
+	return nil
+}
+
+// setString sets a scalar field from its text, as found in a config file
+// string or in an environment variable.
+func setString(field reflect.Value, s string) error {
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", s)
+		}
+		field.SetInt(int64(d))
+	case field.Type() == snowflakeType:
+		id, err := snowflake.Parse(s)
+		if err != nil {
+			return fmt.Errorf("invalid id %q", s)
+		}
+		field.Set(reflect.ValueOf(id))
+	case field.Kind() == reflect.String:
+		field.SetString(s)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		field.SetBool(b)
+	case field.CanInt():
+		i, err := strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 10, 64)
+		if err != nil || field.OverflowInt(i) {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		field.SetInt(i)
+	case field.CanFloat():
+		f, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// kindName describes the value a field expects, for errors.
+func kindName(field reflect.Value) string {
+	switch {
+	case field.Type() == durationType:
+		return "a duration string"
+	case field.Type() == snowflakeType:
+		return "an id"
+	case field.Kind() == reflect.String:
+		return "a string"
+	case field.Kind() == reflect.Bool:
+		return "a boolean"
+	case field.CanInt():
+		return "an integer"
+	case field.CanFloat():
+		return "a number"
+	}
+	return field.Type().String()
+}
+
+// tomlType describes a decoded TOML value, for errors.
+func tomlType(value any) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case int64:
+		return "an integer"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []any:
+		return "an array"
+	case map[string]any:
+		return "a table"
+	case time.Time:
+		return "a date"
+	}
+	return fmt.Sprintf("%T", value)
+}
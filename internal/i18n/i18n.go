@@ -0,0 +1,202 @@
+// Package i18n holds the message catalogs of the bot and negotiates which
+// locale to answer an interaction in.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"first.fm/internal/logger"
+	"github.com/disgoorg/disgo/discord"
+)
+
+// Default is the locale every key must exist in. It is used when neither the
+// user nor the guild locale is supported.
+const Default = discord.LocaleEnglishUS
+
+//go:embed locales/*.json
+var files embed.FS
+
+// catalogs maps a locale to its messages, keyed by message ID.
+var catalogs = mustLoad()
+
+func mustLoad() map[discord.Locale]map[string]string {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	catalogs := make(map[discord.Locale]map[string]string, len(entries))
+	for _, e := range entries {
+		raw, err := files.ReadFile(path.Join("locales", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var messages map[string]string
+		if err = json.Unmarshal(raw, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", e.Name(), err))
+		}
+		catalogs[discord.Locale(strings.TrimSuffix(e.Name(), ".json"))] = messages
+	}
+	if _, ok := catalogs[Default]; !ok {
+		panic("i18n: missing catalog for the default locale")
+	}
+	return catalogs
+}
+
+// Locales returns the supported locales, the default one first.
+func Locales() []discord.Locale {
+	locales := make([]discord.Locale, 0, len(catalogs))
+	for l := range catalogs {
+		if l != Default {
+			locales = append(locales, l)
+		}
+	}
+	slices.Sort(locales)
+	return append([]discord.Locale{Default}, locales...)
+}
+
+// Negotiate picks the locale to reply in: the user's locale, then the guild's
+// preferred locale, each matched exactly or by language, then Default.
+func Negotiate(user discord.Locale, guild *discord.Locale) discord.Locale {
+	candidates := []discord.Locale{user}
+	if guild != nil {
+		candidates = append(candidates, *guild)
+	}
+
+	for _, c := range candidates {
+		if _, ok := catalogs[c]; ok {
+			return c
+		}
+	}
+	for _, c := range candidates {
+		lang, _, _ := strings.Cut(string(c), "-")
+		for l := range catalogs {
+			if other, _, _ := strings.Cut(string(l), "-"); lang != "" && other == lang {
+				return l
+			}
+		}
+	}
+	return Default
+}
+
+// warned keeps missing keys from being logged on every lookup.
+var warned sync.Map
+
+// T returns the message for key in locale formatted with args, falling back to
+// the default locale and then to the key itself.
+func T(locale discord.Locale, key string, args ...any) string {
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		if msg, ok = catalogs[Default][key]; !ok {
+			if _, logged := warned.LoadOrStore(key, struct{}{}); !logged {
+				logger.Warnw("missing localization key", logger.F{"key": key})
+			}
+			msg = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Lookup returns the message for key in locale without any fallback.
+func Lookup(locale discord.Locale, key string) (string, bool) {
+	msg, ok := catalogs[locale][key]
+	return msg, ok
+}
+
+// Localizations returns the message for key in every supported locale except
+// the default one, as expected by Discord's localization fields. It returns
+// nil when no locale translates key.
+func Localizations(key string) map[discord.Locale]string {
+	var m map[discord.Locale]string
+	for l, messages := range catalogs {
+		if l == Default {
+			continue
+		}
+		if msg, ok := messages[key]; ok {
+			if m == nil {
+				m = map[discord.Locale]string{}
+			}
+			m[l] = msg
+		}
+	}
+	return m
+}
+
+// Missing returns the keys each locale lacks. The default locale must have
+// every required key and the other locales every key of the default one.
+func Missing(required ...string) map[discord.Locale][]string {
+	all := slices.Clone(required)
+	for key := range catalogs[Default] {
+		all = append(all, key)
+	}
+	slices.Sort(all)
+	all = slices.Compact(all)
+
+	missing := map[discord.Locale][]string{}
+	for l, messages := range catalogs {
+		keys := all
+		if l == Default {
+			keys = required
+		}
+		for _, key := range keys {
+			if _, ok := messages[key]; !ok {
+				missing[l] = append(missing[l], key)
+			}
+		}
+	}
+	return missing
+}
+
+// Error is an error whose message comes from the catalog, so it can be shown
+// to users in their locale.
+type Error struct {
+	Key  string
+	Args []any
+}
+
+// NewError returns an error with the message of key formatted with args.
+func NewError(key string, args ...any) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+// Error returns the message in the default locale.
+func (e *Error) Error() string {
+	return T(Default, e.Key, e.Args...)
+}
+
+// Localize returns the message in locale.
+func (e *Error) Localize(locale discord.Locale) string {
+	return T(locale, e.Key, e.Args...)
+}
+
+// Is reports whether target is an Error with the same key, so an error with
+// arguments matches the sentinel created for its key.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Key == e.Key
+}
+
+// Localizer is implemented by errors that can describe themselves in a locale.
+type Localizer interface {
+	Localize(locale discord.Locale) string
+}
+
+// Localize returns the message of err in locale when err is or wraps a
+// Localizer, and err.Error() otherwise.
+func Localize(locale discord.Locale, err error) string {
+	var l Localizer
+	if errors.As(err, &l) {
+		return l.Localize(locale)
+	}
+	return err.Error()
+}
@@ -1,6 +1,10 @@
 package api
 
-import "first.fm/internal/lastfm"
+import (
+	"context"
+
+	"first.fm/internal/lastfm"
+)
 
 type Album struct {
 	api *API
@@ -12,61 +16,61 @@ func NewAlbum(api *API) *Album {
 }
 
 // Info returns the information of an album by artist and album name.
-func (a Album) Info(params lastfm.AlbumInfoParams) (*lastfm.AlbumInfo, error) {
+func (a Album) Info(ctx context.Context, params lastfm.AlbumInfoParams) (*lastfm.AlbumInfo, error) {
 	var res lastfm.AlbumInfo
-	return &res, a.api.Get(&res, AlbumGetInfoMethod, params)
+	return &res, a.api.Get(ctx, &res, AlbumGetInfoMethod, params)
 }
 
 // InfoByMBID returns the information of an album by MBID.
-func (a Album) InfoByMBID(params lastfm.AlbumInfoMBIDParams) (*lastfm.AlbumInfo, error) {
+func (a Album) InfoByMBID(ctx context.Context, params lastfm.AlbumInfoMBIDParams) (*lastfm.AlbumInfo, error) {
 	var res lastfm.AlbumInfo
-	return &res, a.api.Get(&res, AlbumGetInfoMethod, params)
+	return &res, a.api.Get(ctx, &res, AlbumGetInfoMethod, params)
 }
 
 // UserInfo returns the information of an album for user by artist and album
 // name.
-func (a Album) UserInfo(params lastfm.AlbumUserInfoParams) (*lastfm.AlbumUserInfo, error) {
+func (a Album) UserInfo(ctx context.Context, params lastfm.AlbumUserInfoParams) (*lastfm.AlbumUserInfo, error) {
 	var res lastfm.AlbumUserInfo
-	return &res, a.api.Get(&res, AlbumGetInfoMethod, params)
+	return &res, a.api.Get(ctx, &res, AlbumGetInfoMethod, params)
 }
 
 // UserInfoByMBID returns the information of an album for user by MBID.
 func (a Album) UserInfoByMBID(
-	params lastfm.AlbumUserInfoMBIDParams) (*lastfm.AlbumUserInfo, error) {
+	ctx context.Context, params lastfm.AlbumUserInfoMBIDParams) (*lastfm.AlbumUserInfo, error) {
 
 	var res lastfm.AlbumUserInfo
-	return &res, a.api.Get(&res, AlbumGetInfoMethod, params)
+	return &res, a.api.Get(ctx, &res, AlbumGetInfoMethod, params)
 }
 
 // UserTags returns the tags of an album for user by artist and album name.
-func (a Album) UserTags(params lastfm.AlbumTagsParams) (*lastfm.AlbumTags, error) {
+func (a Album) UserTags(ctx context.Context, params lastfm.AlbumTagsParams) (*lastfm.AlbumTags, error) {
 	var res lastfm.AlbumTags
-	return &res, a.api.Get(&res, AlbumGetTagsMethod, params)
+	return &res, a.api.Get(ctx, &res, AlbumGetTagsMethod, params)
 }
 
 // UserTagsByMBID returns the tags of an album for user by MBID.
-func (a Album) UserTagsByMBID(params lastfm.AlbumTagsMBIDParams) (*lastfm.AlbumTags, error) {
+func (a Album) UserTagsByMBID(ctx context.Context, params lastfm.AlbumTagsMBIDParams) (*lastfm.AlbumTags, error) {
 	var res lastfm.AlbumTags
-	return &res, a.api.Get(&res, AlbumGetTagsMethod, params)
+	return &res, a.api.Get(ctx, &res, AlbumGetTagsMethod, params)
 }
 
 // TopTags returns the top tags of an album by artist and album name.
-func (a Album) TopTags(params lastfm.AlbumTopTagsParams) (*lastfm.AlbumTopTags, error) {
+func (a Album) TopTags(ctx context.Context, params lastfm.AlbumTopTagsParams) (*lastfm.AlbumTopTags, error) {
 	var res lastfm.AlbumTopTags
-	return &res, a.api.Get(&res, AlbumGetTopTagsMethod, params)
+	return &res, a.api.Get(ctx, &res, AlbumGetTopTagsMethod, params)
 }
 
 // TopTagsByMBID returns the top tags of an album by MBID.
 //
 // Deprecated: Fetching top tags by MBID doesn't seem to work. Use TopTags
 // instead.
-func (a Album) TopTagsByMBID(params lastfm.AlbumTopTagsMBIDParams) (*lastfm.AlbumTopTags, error) {
+func (a Album) TopTagsByMBID(ctx context.Context, params lastfm.AlbumTopTagsMBIDParams) (*lastfm.AlbumTopTags, error) {
 	var res lastfm.AlbumTopTags
-	return &res, a.api.Get(&res, AlbumGetTopTagsMethod, params)
+	return &res, a.api.Get(ctx, &res, AlbumGetTopTagsMethod, params)
 }
 
 // Search returns the results of an album search.
-func (a Album) Search(params lastfm.AlbumSearchParams) (*lastfm.AlbumSearchResult, error) {
+func (a Album) Search(ctx context.Context, params lastfm.AlbumSearchParams) (*lastfm.AlbumSearchResult, error) {
 	var res lastfm.AlbumSearchResult
-	return &res, a.api.Get(&res, AlbumSearchMethod, params)
+	return &res, a.api.Get(ctx, &res, AlbumSearchMethod, params)
 }
@@ -64,6 +64,12 @@ func NewWithTimeout(apiKey string, timeout int) *API {
 func (a *API) SetUserAgent(userAgent string) { a.UserAgent = userAgent }
 func (a *API) SetRetries(retries uint)       { a.Retries = retries }
 
+// SetRateLimit replaces the client side rate limit of 1 request per second
+// with bursts of 5.
+func (a *API) SetRateLimit(limit rate.Limit, burst int) {
+	a.rateLimiter = rate.NewLimiter(limit, burst)
+}
+
 func (a API) CheckCredentials(level RequestLevel) error {
 	if level == RequestLevelAPIKey && a.APIKey == "" {
 		return NewLastFMError(ErrAPIKeyMissing, APIKeyMissingMessage)
@@ -74,15 +80,26 @@ func (a API) CheckCredentials(level RequestLevel) error {
 	return nil
 }
 
-func (a API) Get(dest any, method APIMethod, params any) error {
-	return a.Request(dest, http.MethodGet, method, params)
+func (a API) Get(ctx context.Context, dest any, method APIMethod, params any) error {
+	return a.Request(ctx, dest, http.MethodGet, method, params)
+}
+
+func (a API) Post(ctx context.Context, dest any, method APIMethod, params any) error {
+	return a.Request(ctx, dest, http.MethodPost, method, params)
 }
 
-func (a API) Post(dest any, method APIMethod, params any) error {
-	return a.Request(dest, http.MethodPost, method, params)
+// Request calls method. ctx bounds the wait for the rate limiter and every
+// attempt of the request.
+func (a API) Request(ctx context.Context, dest any, httpMethod string, method APIMethod, params any) error {
+	requestsTotal.Inc(string(method))
+	err := a.request(ctx, dest, httpMethod, method, params)
+	if err != nil {
+		requestErrors.Inc(string(method))
+	}
+	return err
 }
 
-func (a API) Request(dest any, httpMethod string, method APIMethod, params any) error {
+func (a API) request(ctx context.Context, dest any, httpMethod string, method APIMethod, params any) error {
 	if err := a.CheckCredentials(RequestLevelAPIKey); err != nil {
 		return err
 	}
@@ -97,26 +114,28 @@ func (a API) Request(dest any, httpMethod string, method APIMethod, params any)
 
 	switch httpMethod {
 	case http.MethodGet:
-		return a.GetURL(dest, BuildAPIURL(p))
+		return a.GetURL(ctx, dest, BuildAPIURL(p))
 	case http.MethodPost:
-		return a.PostBody(dest, Endpoint, p.Encode())
+		return a.PostBody(ctx, dest, Endpoint, p.Encode())
 	default:
 		return errors.New("unsupported http method")
 	}
 }
 
-func (a API) GetURL(dest any, url string) error {
-	return a.tryRequest(dest, http.MethodGet, url, "")
+func (a API) GetURL(ctx context.Context, dest any, url string) error {
+	return a.tryRequest(ctx, dest, http.MethodGet, url, "")
 }
 
-func (a API) PostBody(dest any, url, body string) error {
-	return a.tryRequest(dest, http.MethodPost, url, body)
+func (a API) PostBody(ctx context.Context, dest any, url, body string) error {
+	return a.tryRequest(ctx, dest, http.MethodPost, url, body)
 }
 
-func (a API) tryRequest(dest any, method, url, body string) error {
-	if err := a.rateLimiter.Wait(context.Background()); err != nil {
+func (a API) tryRequest(ctx context.Context, dest any, method, url, body string) error {
+	start := time.Now()
+	if err := a.rateLimiter.Wait(ctx); err != nil {
 		return err
 	}
+	rateLimitWait.Observe(time.Since(start).Seconds())
 
 	var (
 		res   *http.Response
@@ -129,11 +148,11 @@ func (a API) tryRequest(dest any, method, url, body string) error {
 		var req *http.Request
 		switch method {
 		case http.MethodGet:
-			req, err = a.createGetRequest(url)
+			req, err = a.createGetRequest(ctx, url)
 		case http.MethodPost:
-			req, err = a.createPostRequest(url, body)
+			req, err = a.createPostRequest(ctx, url, body)
 		default:
-			req, err = a.createRequest(method, url, body)
+			req, err = a.createRequest(ctx, method, url, body)
 		}
 		if err != nil {
 			return err
@@ -181,12 +200,12 @@ func (a API) tryRequest(dest any, method, url, body string) error {
 	return nil
 }
 
-func (a API) createGetRequest(url string) (*http.Request, error) {
-	return a.createRequest(http.MethodGet, url, "")
+func (a API) createGetRequest(ctx context.Context, url string) (*http.Request, error) {
+	return a.createRequest(ctx, http.MethodGet, url, "")
 }
 
-func (a API) createPostRequest(url, body string) (*http.Request, error) {
-	req, err := a.createRequest(http.MethodPost, url, body)
+func (a API) createPostRequest(ctx context.Context, url, body string) (*http.Request, error) {
+	req, err := a.createRequest(ctx, http.MethodPost, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -194,12 +213,12 @@ func (a API) createPostRequest(url, body string) (*http.Request, error) {
 	return req, nil
 }
 
-func (a API) createRequest(method, url, body string) (*http.Request, error) {
+func (a API) createRequest(ctx context.Context, method, url, body string) (*http.Request, error) {
 	var r io.Reader
 	if body != "" {
 		r = strings.NewReader(body)
 	}
-	req, err := http.NewRequest(method, url, r)
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,10 @@
 package api
 
-import "first.fm/internal/lastfm"
+import (
+	"context"
+
+	"first.fm/internal/lastfm"
+)
 
 type Artist struct {
 	api *API
@@ -12,108 +16,108 @@ func NewArtist(api *API) *Artist {
 }
 
 // Correction returns the artist name corrections of an artist.
-func (a Artist) Correction(artist string) (*lastfm.ArtistCorrection, error) {
+func (a Artist) Correction(ctx context.Context, artist string) (*lastfm.ArtistCorrection, error) {
 	var res lastfm.ArtistCorrection
 	p := lastfm.ArtistCorrectionParams{Artist: artist}
-	return &res, a.api.Get(&res, ArtistGetCorrectionMethod, p)
+	return &res, a.api.Get(ctx, &res, ArtistGetCorrectionMethod, p)
 }
 
 // Info returns the information of an artist by artist name.
-func (a Artist) Info(params lastfm.ArtistInfoParams) (*lastfm.ArtistInfo, error) {
+func (a Artist) Info(ctx context.Context, params lastfm.ArtistInfoParams) (*lastfm.ArtistInfo, error) {
 	var res lastfm.ArtistInfo
-	return &res, a.api.Get(&res, ArtistGetInfoMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetInfoMethod, params)
 }
 
 // InfoByMBID returns the information of an artist by MBID.
-func (a Artist) InfoByMBID(params lastfm.ArtistInfoMBIDParams) (*lastfm.ArtistInfo, error) {
+func (a Artist) InfoByMBID(ctx context.Context, params lastfm.ArtistInfoMBIDParams) (*lastfm.ArtistInfo, error) {
 	var res lastfm.ArtistInfo
-	return &res, a.api.Get(&res, ArtistGetInfoMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetInfoMethod, params)
 }
 
 // UserInfo returns the information of an artist for user by artist name.
-func (a Artist) UserInfo(params lastfm.ArtistUserInfoParams) (*lastfm.ArtistUserInfo, error) {
+func (a Artist) UserInfo(ctx context.Context, params lastfm.ArtistUserInfoParams) (*lastfm.ArtistUserInfo, error) {
 	var res lastfm.ArtistUserInfo
-	return &res, a.api.Get(&res, ArtistGetInfoMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetInfoMethod, params)
 }
 
 // UserInfoByMBID returns the information of an artist for user by MBID.
 func (a Artist) UserInfoByMBID(
-	params lastfm.ArtistUserInfoMBIDParams) (*lastfm.ArtistUserInfo, error) {
+	ctx context.Context, params lastfm.ArtistUserInfoMBIDParams) (*lastfm.ArtistUserInfo, error) {
 
 	var res lastfm.ArtistUserInfo
-	return &res, a.api.Get(&res, ArtistGetInfoMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetInfoMethod, params)
 }
 
 // Similar returns the similar artists of an artist by artist name.
-func (a Artist) Similar(params lastfm.ArtistSimilarParams) (*lastfm.SimilarArtists, error) {
+func (a Artist) Similar(ctx context.Context, params lastfm.ArtistSimilarParams) (*lastfm.SimilarArtists, error) {
 	var res lastfm.SimilarArtists
-	return &res, a.api.Get(&res, ArtistGetSimilarMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetSimilarMethod, params)
 }
 
 // SimilarByMBID returns the similar artists of an artist by MBID.
 func (a Artist) SimilarByMBID(
-	params lastfm.ArtistSimilarMBIDParams) (*lastfm.SimilarArtists, error) {
+	ctx context.Context, params lastfm.ArtistSimilarMBIDParams) (*lastfm.SimilarArtists, error) {
 
 	var res lastfm.SimilarArtists
-	return &res, a.api.Get(&res, ArtistGetSimilarMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetSimilarMethod, params)
 }
 
 // UserTags returns the tags of an artist for user by artist name.
-func (a Artist) UserTags(params lastfm.ArtistTagsParams) (*lastfm.ArtistTags, error) {
+func (a Artist) UserTags(ctx context.Context, params lastfm.ArtistTagsParams) (*lastfm.ArtistTags, error) {
 	var res lastfm.ArtistTags
-	return &res, a.api.Get(&res, ArtistGetTagsMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetTagsMethod, params)
 }
 
 // UserTagsByMBID returns the tags of an artist for user by MBID.
-func (a Artist) UserTagsByMBID(params lastfm.ArtistTagsMBIDParams) (*lastfm.ArtistTags, error) {
+func (a Artist) UserTagsByMBID(ctx context.Context, params lastfm.ArtistTagsMBIDParams) (*lastfm.ArtistTags, error) {
 	var res lastfm.ArtistTags
-	return &res, a.api.Get(&res, ArtistGetTagsMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetTagsMethod, params)
 }
 
 // TopAlbums returns the top albums of an artist by artist name.
-func (a Artist) TopAlbums(params lastfm.ArtistTopAlbumsParams) (*lastfm.ArtistTopAlbums, error) {
+func (a Artist) TopAlbums(ctx context.Context, params lastfm.ArtistTopAlbumsParams) (*lastfm.ArtistTopAlbums, error) {
 	var res lastfm.ArtistTopAlbums
-	return &res, a.api.Get(&res, ArtistGetTopAlbumsMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetTopAlbumsMethod, params)
 }
 
 // TopAlbumsByMBID returns the top albums of an artist by MBID.
 func (a Artist) TopAlbumsByMBID(
-	params lastfm.ArtistTopAlbumsMBIDParams) (*lastfm.ArtistTopAlbums, error) {
+	ctx context.Context, params lastfm.ArtistTopAlbumsMBIDParams) (*lastfm.ArtistTopAlbums, error) {
 
 	var res lastfm.ArtistTopAlbums
-	return &res, a.api.Get(&res, ArtistGetTopAlbumsMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetTopAlbumsMethod, params)
 }
 
 // TopTracks returns the top tracks of an artist by artist name.
-func (a Artist) TopTags(params lastfm.ArtistTopTagsParams) (*lastfm.ArtistTopTags, error) {
+func (a Artist) TopTags(ctx context.Context, params lastfm.ArtistTopTagsParams) (*lastfm.ArtistTopTags, error) {
 	var res lastfm.ArtistTopTags
-	return &res, a.api.Get(&res, ArtistGetTopTagsMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetTopTagsMethod, params)
 }
 
 // TopTagsByMBID returns the top tracks of an artist by MBID.
 func (a Artist) TopTagsByMBID(
-	params lastfm.ArtistTopTagsMBIDParams) (*lastfm.ArtistTopTags, error) {
+	ctx context.Context, params lastfm.ArtistTopTagsMBIDParams) (*lastfm.ArtistTopTags, error) {
 
 	var res lastfm.ArtistTopTags
-	return &res, a.api.Get(&res, ArtistGetTopTagsMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetTopTagsMethod, params)
 }
 
 // TopTracks returns the top tracks of an artist by artist name.
-func (a Artist) TopTracks(params lastfm.ArtistTopTracksParams) (*lastfm.ArtistTopTracks, error) {
+func (a Artist) TopTracks(ctx context.Context, params lastfm.ArtistTopTracksParams) (*lastfm.ArtistTopTracks, error) {
 	var res lastfm.ArtistTopTracks
-	return &res, a.api.Get(&res, ArtistGetTopTracksMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetTopTracksMethod, params)
 }
 
 // TopTracksByMBID returns the top tracks of an artist by MBID.
 func (a Artist) TopTracksByMBID(
-	params lastfm.ArtistTopTracksMBIDParams) (*lastfm.ArtistTopTracks, error) {
+	ctx context.Context, params lastfm.ArtistTopTracksMBIDParams) (*lastfm.ArtistTopTracks, error) {
 
 	var res lastfm.ArtistTopTracks
-	return &res, a.api.Get(&res, ArtistGetTopTracksMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistGetTopTracksMethod, params)
 }
 
 // Search returns the results of an album search.
-func (a Artist) Search(params lastfm.ArtistSearchParams) (*lastfm.ArtistSearchResult, error) {
+func (a Artist) Search(ctx context.Context, params lastfm.ArtistSearchParams) (*lastfm.ArtistSearchResult, error) {
 	var res lastfm.ArtistSearchResult
-	return &res, a.api.Get(&res, ArtistSearchMethod, params)
+	return &res, a.api.Get(ctx, &res, ArtistSearchMethod, params)
 }
@@ -1,47 +1,193 @@
 package api
 
-import "first.fm/internal/lastfm"
+import (
+	"context"
+	"iter"
+	"strings"
+	"sync"
+	"time"
+
+	"first.fm/internal/cache"
+	"first.fm/internal/lastfm"
+)
 
 type Chart struct {
-	api *API
+	api          *API
+	ArtistsCache *cache.Cache[lastfm.ChartTopArtistsParams, *lastfm.ChartTopArtists]
+	TagsCache    *cache.Cache[lastfm.ChartTopTagsParams, *lastfm.ChartTopTags]
+	TracksCache  *cache.Cache[lastfm.ChartTopTracksParams, *lastfm.ChartTopTracks]
+
+	// ranks keeps the last seen rank of every chart entry so the next fetch
+	// can report rank movement, whichever page the entry moved to.
+	ranks chartRanks
+}
+
+// chartRanks holds the last seen rank of every entry of each chart method,
+// across all pages and page sizes.
+type chartRanks struct {
+	mu      sync.Mutex
+	methods map[APIMethod]*methodRanks
+}
+
+type methodRanks struct {
+	ranks map[string]int
+	// depth is the deepest rank fetched so far. Entries ranked within it that
+	// weren't seen are new to the chart.
+	depth int
+}
+
+// update returns the movement of the entry with the given key and rank since
+// it was last seen in the chart of method, and records the new rank.
+func (c *chartRanks) update(method APIMethod, key string, rank int) lastfm.RankMovement {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.methods == nil {
+		c.methods = make(map[APIMethod]*methodRanks)
+	}
+	m, ok := c.methods[method]
+	if !ok {
+		m = &methodRanks{ranks: make(map[string]int)}
+		c.methods[method] = m
+	}
+
+	previous := m.ranks[key]
+	m.ranks[key] = rank
+	return lastfm.RankMovement{Previous: previous, Known: previous != 0 || rank <= m.depth}
+}
+
+// fetched marks the ranks up to depth as seen for method, once every entry
+// of a page was updated.
+func (c *chartRanks) fetched(method APIMethod, depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if m, ok := c.methods[method]; ok {
+		m.depth = max(m.depth, depth)
+	}
 }
 
 // NewChart creates and returns a new Chart API route.
 func NewChart(api *API) *Chart {
-	return &Chart{api: api}
+	return &Chart{
+		api:          api,
+		ArtistsCache: cache.New[lastfm.ChartTopArtistsParams, *lastfm.ChartTopArtists](30*time.Minute, 100),
+		TagsCache:    cache.New[lastfm.ChartTopTagsParams, *lastfm.ChartTopTags](30*time.Minute, 100),
+		TracksCache:  cache.New[lastfm.ChartTopTracksParams, *lastfm.ChartTopTracks](30*time.Minute, 100),
+	}
 }
 
-// TopArtistsLimit returns the top artists of the chart.
-func (c Chart) TopArtistsLimit(params *lastfm.ChartTopArtistsParams) (*lastfm.ChartTopArtists, error) {
+// TopArtists returns the top artists of the chart with caching.
+func (c *Chart) TopArtists(ctx context.Context, params lastfm.ChartTopArtistsParams) (*lastfm.ChartTopArtists, error) {
+	if cached, ok := c.ArtistsCache.Get(params); ok {
+		return cached, nil
+	}
+
 	var res lastfm.ChartTopArtists
-	return &res, c.api.Get(&res, ChartGetTopArtistsMethod, params)
+	if err := c.api.Get(ctx, &res, ChartGetTopArtistsMethod, params); err != nil {
+		return nil, err
+	}
+
+	depth := 0
+	for i := range res.Artists {
+		a := &res.Artists[i]
+		a.Rank = lastfm.ChartRank(a.Rank, res.Page, res.PerPage, i)
+		a.Movement = c.ranks.update(ChartGetTopArtistsMethod, chartKey(a.Name), a.Rank)
+		depth = max(depth, a.Rank)
+	}
+	c.ranks.fetched(ChartGetTopArtistsMethod, depth)
+
+	c.ArtistsCache.Set(params, &res)
+	return &res, nil
 }
 
-// TopArtists returns all the top artists of the chart. Same as
-// TopArtistsLimit(nil).
-func (c Chart) TopArtists() (*lastfm.ChartTopArtists, error) {
-	return c.TopArtistsLimit(nil)
+// TopArtistsPages iterates over the pages of the top artists chart, starting
+// at params.Page. Iteration stops after the last page or the first error.
+func (c *Chart) TopArtistsPages(ctx context.Context, params lastfm.ChartTopArtistsParams) iter.Seq2[*lastfm.ChartTopArtists, error] {
+	return pages(params.Page, func(page uint) (*lastfm.ChartTopArtists, int, error) {
+		params.Page = page
+		res, err := c.TopArtists(ctx, params)
+		if err != nil {
+			return nil, 0, err
+		}
+		return res, res.TotalPages, nil
+	})
 }
 
-// TopTagsLimit returns the top tags of the chart.
-func (c Chart) TopTagsLimit(params *lastfm.ChartTopTagsParams) (*lastfm.ChartTopTags, error) {
+// TopTags returns the top tags of the chart with caching.
+func (c *Chart) TopTags(ctx context.Context, params lastfm.ChartTopTagsParams) (*lastfm.ChartTopTags, error) {
+	if cached, ok := c.TagsCache.Get(params); ok {
+		return cached, nil
+	}
+
 	var res lastfm.ChartTopTags
-	return &res, c.api.Get(&res, ChartGetTopTagsMethod, params)
+	if err := c.api.Get(ctx, &res, ChartGetTopTagsMethod, params); err != nil {
+		return nil, err
+	}
+
+	depth := 0
+	for i := range res.Tags {
+		t := &res.Tags[i]
+		t.Rank = lastfm.ChartRank(t.Rank, res.Page, res.PerPage, i)
+		t.Movement = c.ranks.update(ChartGetTopTagsMethod, chartKey(t.Name), t.Rank)
+		depth = max(depth, t.Rank)
+	}
+	c.ranks.fetched(ChartGetTopTagsMethod, depth)
+
+	c.TagsCache.Set(params, &res)
+	return &res, nil
 }
 
-// TopTags returns the top tags of the chart. Same as TopTagsLimit(nil).
-func (c Chart) TopTags() (*lastfm.ChartTopTags, error) {
-	return c.TopTagsLimit(nil)
+// TopTagsPages iterates over the pages of the top tags chart, starting at
+// params.Page. Iteration stops after the last page or the first error.
+func (c *Chart) TopTagsPages(ctx context.Context, params lastfm.ChartTopTagsParams) iter.Seq2[*lastfm.ChartTopTags, error] {
+	return pages(params.Page, func(page uint) (*lastfm.ChartTopTags, int, error) {
+		params.Page = page
+		res, err := c.TopTags(ctx, params)
+		if err != nil {
+			return nil, 0, err
+		}
+		return res, res.TotalPages, nil
+	})
 }
 
-// TopTracksLimit returns the top tracks of the chart.
-func (c Chart) TopTracksLimit(params *lastfm.ChartTopTracksParams) (*lastfm.ChartTopTracks, error) {
+// TopTracks returns the top tracks of the chart with caching.
+func (c *Chart) TopTracks(ctx context.Context, params lastfm.ChartTopTracksParams) (*lastfm.ChartTopTracks, error) {
+	if cached, ok := c.TracksCache.Get(params); ok {
+		return cached, nil
+	}
+
 	var res lastfm.ChartTopTracks
-	return &res, c.api.Get(&res, ChartGetTopTracksMethod, params)
+	if err := c.api.Get(ctx, &res, ChartGetTopTracksMethod, params); err != nil {
+		return nil, err
+	}
+
+	depth := 0
+	for i := range res.Tracks {
+		t := &res.Tracks[i]
+		t.Rank = lastfm.ChartRank(t.Rank, res.Page, res.PerPage, i)
+		t.Movement = c.ranks.update(ChartGetTopTracksMethod, chartKey(t.Artist.Name, t.Title), t.Rank)
+		depth = max(depth, t.Rank)
+	}
+	c.ranks.fetched(ChartGetTopTracksMethod, depth)
+
+	c.TracksCache.Set(params, &res)
+	return &res, nil
+}
+
+// TopTracksPages iterates over the pages of the top tracks chart, starting at
+// params.Page. Iteration stops after the last page or the first error.
+func (c *Chart) TopTracksPages(ctx context.Context, params lastfm.ChartTopTracksParams) iter.Seq2[*lastfm.ChartTopTracks, error] {
+	return pages(params.Page, func(page uint) (*lastfm.ChartTopTracks, int, error) {
+		params.Page = page
+		res, err := c.TopTracks(ctx, params)
+		if err != nil {
+			return nil, 0, err
+		}
+		return res, res.TotalPages, nil
+	})
 }
 
-// TopTracks returns all the top tracks of the chart. Same as
-// TopTracksLimit(nil).
-func (c Chart) TopTracks() (*lastfm.ChartTopTracks, error) {
-	return c.TopTracksLimit(nil)
+func chartKey(parts ...string) string {
+	return strings.ToLower(strings.Join(parts, "\x00"))
 }
@@ -5,6 +5,7 @@ type Client struct {
 	Album  *Album
 	Artist *Artist
 	Chart  *Chart
+	Search *Search
 	Track  *Track
 	User   *User
 }
@@ -13,13 +14,30 @@ func NewClient(apiKey string) *Client {
 	return newClient(New(apiKey))
 }
 
+// NewClientFromAPI returns a client making its requests through a, e.g. one
+// with a custom HTTPClient.
+func NewClientFromAPI(a *API) *Client {
+	return newClient(a)
+}
+
 func newClient(a *API) *Client {
+	album, artist, track := NewAlbum(a), NewArtist(a), NewTrack(a)
 	return &Client{
 		API:    a,
-		Album:  NewAlbum(a),
-		Artist: NewArtist(a),
+		Album:  album,
+		Artist: artist,
 		Chart:  NewChart(a),
-		Track:  NewTrack(a),
+		Search: NewSearch(album, artist, track),
+		Track:  track,
 		User:   NewUser(a),
 	}
 }
+
+// Close stops the cleanup goroutines of the route caches.
+func (c *Client) Close() {
+	c.User.InfoCache.Close()
+	c.Search.ResultCache.Close()
+	c.Chart.ArtistsCache.Close()
+	c.Chart.TagsCache.Close()
+	c.Chart.TracksCache.Close()
+}
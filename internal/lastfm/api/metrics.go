@@ -0,0 +1,12 @@
+package api
+
+import "first.fm/internal/metrics"
+
+var (
+	requestsTotal = metrics.NewCounter("firstfm_lastfm_requests_total",
+		"Last.fm API requests by method, not counting cached responses.", "method")
+	requestErrors = metrics.NewCounter("firstfm_lastfm_request_errors_total",
+		"Last.fm API requests that failed, by method.", "method")
+	rateLimitWait = metrics.NewHistogram("firstfm_lastfm_rate_limit_wait_seconds",
+		"Time requests waited for the client side rate limiter.", metrics.DefaultBuckets)
+)
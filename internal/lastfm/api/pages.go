@@ -0,0 +1,21 @@
+package api
+
+import "iter"
+
+// pages iterates over a paginated route starting at page start (1 when 0).
+// fetch returns the page and the total number of pages reported by Last.fm.
+// Iteration stops after the last page, on the first error, or when the caller
+// stops ranging.
+func pages[T any](start uint, fetch func(page uint) (T, int, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if start == 0 {
+			start = 1
+		}
+		for page := start; ; page++ {
+			res, total, err := fetch(page)
+			if !yield(res, err) || err != nil || int(page) >= total {
+				return
+			}
+		}
+	}
+}
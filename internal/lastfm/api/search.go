@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"first.fm/internal/cache"
+	"first.fm/internal/lastfm"
+)
+
+type Search struct {
+	album       *Album
+	artist      *Artist
+	track       *Track
+	ResultCache *cache.Cache[string, *lastfm.SearchResults]
+}
+
+// NewSearch creates and returns a new Search API route that queries the
+// album, artist and track routes together.
+func NewSearch(album *Album, artist *Artist, track *Track) *Search {
+	return &Search{
+		album:       album,
+		artist:      artist,
+		track:       track,
+		ResultCache: cache.New[string, *lastfm.SearchResults](5*time.Minute, 1000),
+	}
+}
+
+// All searches artists, albums and tracks concurrently and returns the hits
+// merged and ranked by relevance. Results are cached, as autocomplete sends
+// the same query repeatedly while the user types. An error is only returned
+// when every search failed.
+func (s *Search) All(ctx context.Context, params lastfm.SearchParams) (*lastfm.SearchResults, error) {
+	key := searchCacheKey(params)
+	if cached, ok := s.ResultCache.Get(key); ok {
+		return cached, nil
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		hits []lastfm.SearchHit
+		errs []error
+		runs int
+	)
+
+	run := func(kind lastfm.SearchKind, fn func() ([]lastfm.SearchHit, error)) {
+		if !params.HasKind(kind) {
+			return
+		}
+		runs++
+		wg.Go(func() {
+			res, err := fn()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			hits = append(hits, res...)
+		})
+	}
+
+	run(lastfm.SearchKindArtist, func() ([]lastfm.SearchHit, error) {
+		res, err := s.artist.Search(ctx, lastfm.ArtistSearchParams{Artist: params.Query, Limit: params.Limit})
+		if err != nil {
+			return nil, err
+		}
+		return lastfm.ArtistHits(res), nil
+	})
+	run(lastfm.SearchKindAlbum, func() ([]lastfm.SearchHit, error) {
+		res, err := s.album.Search(ctx, lastfm.AlbumSearchParams{Album: params.Query, Limit: params.Limit})
+		if err != nil {
+			return nil, err
+		}
+		return lastfm.AlbumHits(res), nil
+	})
+	run(lastfm.SearchKindTrack, func() ([]lastfm.SearchHit, error) {
+		res, err := s.track.Search(ctx, lastfm.TrackSearchParams{Track: params.Query, Limit: params.Limit})
+		if err != nil {
+			return nil, err
+		}
+		return lastfm.TrackHits(res), nil
+	})
+
+	wg.Wait()
+
+	if runs > 0 && len(errs) == runs {
+		return nil, errors.Join(errs...)
+	}
+
+	// goroutines finish in any order; group by kind before ranking so ties
+	// keep the order each route returned
+	hits = groupHitsByKind(hits)
+
+	res := &lastfm.SearchResults{
+		Query: params.Query,
+		Hits:  lastfm.RankSearchHits(params.Query, hits),
+	}
+	if len(errs) == 0 {
+		s.ResultCache.Set(key, res)
+	}
+	return res, nil
+}
+
+// Artists searches only artists. Same as All with Kinds set to artists.
+func (s *Search) Artists(ctx context.Context, query string, limit uint) ([]lastfm.SearchHit, error) {
+	res, err := s.All(ctx, lastfm.SearchParams{Query: query, Kinds: []lastfm.SearchKind{lastfm.SearchKindArtist}, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return res.Hits, nil
+}
+
+// Albums searches only albums. Same as All with Kinds set to albums.
+func (s *Search) Albums(ctx context.Context, query string, limit uint) ([]lastfm.SearchHit, error) {
+	res, err := s.All(ctx, lastfm.SearchParams{Query: query, Kinds: []lastfm.SearchKind{lastfm.SearchKindAlbum}, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return res.Hits, nil
+}
+
+// Tracks searches only tracks. Same as All with Kinds set to tracks.
+func (s *Search) Tracks(ctx context.Context, query string, limit uint) ([]lastfm.SearchHit, error) {
+	res, err := s.All(ctx, lastfm.SearchParams{Query: query, Kinds: []lastfm.SearchKind{lastfm.SearchKindTrack}, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	return res.Hits, nil
+}
+
+func groupHitsByKind(hits []lastfm.SearchHit) []lastfm.SearchHit {
+	grouped := make([]lastfm.SearchHit, 0, len(hits))
+	for _, kind := range []lastfm.SearchKind{lastfm.SearchKindArtist, lastfm.SearchKindAlbum, lastfm.SearchKindTrack} {
+		for _, h := range hits {
+			if h.Kind == kind {
+				grouped = append(grouped, h)
+			}
+		}
+	}
+	return grouped
+}
+
+func searchCacheKey(params lastfm.SearchParams) string {
+	kinds := make([]string, 0, len(params.Kinds))
+	for _, k := range params.Kinds {
+		kinds = append(kinds, string(k))
+	}
+	return strings.ToLower(strings.TrimSpace(params.Query)) + "|" + strings.Join(kinds, ",") + "|" + strconv.FormatUint(uint64(params.Limit), 10)
+}
@@ -1,6 +1,10 @@
 package api
 
-import "first.fm/internal/lastfm"
+import (
+	"context"
+
+	"first.fm/internal/lastfm"
+)
 
 type Track struct {
 	api *API
@@ -12,77 +16,77 @@ func NewTrack(api *API) *Track {
 }
 
 // Correction returns the track and artist name corrections of a track.
-func (t Track) Correction(artist, track string) (*lastfm.TrackCorrection, error) {
+func (t Track) Correction(ctx context.Context, artist, track string) (*lastfm.TrackCorrection, error) {
 	var res lastfm.TrackCorrection
 	p := lastfm.TrackCorrectionParams{Artist: artist, Track: track}
-	return &res, t.api.Get(&res, TrackGetCorrectionMethod, p)
+	return &res, t.api.Get(ctx, &res, TrackGetCorrectionMethod, p)
 }
 
 // Info returns the information of a track by artist and track name.
-func (t Track) Info(params lastfm.TrackInfoParams) (*lastfm.TrackInfo, error) {
+func (t Track) Info(ctx context.Context, params lastfm.TrackInfoParams) (*lastfm.TrackInfo, error) {
 	var res lastfm.TrackInfo
-	return &res, t.api.Get(&res, TrackGetInfoMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetInfoMethod, params)
 }
 
 // InfoByMBID returns the information of a track by MBID.
-func (t Track) InfoByMBID(params lastfm.TrackInfoMBIDParams) (*lastfm.TrackInfo, error) {
+func (t Track) InfoByMBID(ctx context.Context, params lastfm.TrackInfoMBIDParams) (*lastfm.TrackInfo, error) {
 	var res lastfm.TrackInfo
-	return &res, t.api.Get(&res, TrackGetInfoMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetInfoMethod, params)
 }
 
 // UserInfo returns the information of a track for user by artist and track
 // name.
-func (t Track) UserInfo(params lastfm.TrackUserInfoParams) (*lastfm.TrackUserInfo, error) {
+func (t Track) UserInfo(ctx context.Context, params lastfm.TrackUserInfoParams) (*lastfm.TrackUserInfo, error) {
 	var res lastfm.TrackUserInfo
-	return &res, t.api.Get(&res, TrackGetInfoMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetInfoMethod, params)
 }
 
 // UserInfoByMBID returns the information of a track for user by MBID.
 func (t Track) UserInfoByMBID(
-	params lastfm.TrackUserInfoMBIDParams) (*lastfm.TrackUserInfo, error) {
+	ctx context.Context, params lastfm.TrackUserInfoMBIDParams) (*lastfm.TrackUserInfo, error) {
 
 	var res lastfm.TrackUserInfo
-	return &res, t.api.Get(&res, TrackGetInfoMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetInfoMethod, params)
 }
 
 // Similar returns the similar tracks of a track by artist and track name.
-func (t Track) Similar(params lastfm.TrackSimilarParams) (*lastfm.SimilarTracks, error) {
+func (t Track) Similar(ctx context.Context, params lastfm.TrackSimilarParams) (*lastfm.SimilarTracks, error) {
 	var res lastfm.SimilarTracks
-	return &res, t.api.Get(&res, TrackGetSimilarMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetSimilarMethod, params)
 }
 
 // SimilarByMBID returns the similar tracks of a track by MBID.
-func (t Track) SimilarByMBID(params lastfm.TrackSimilarMBIDParams) (*lastfm.SimilarTracks, error) {
+func (t Track) SimilarByMBID(ctx context.Context, params lastfm.TrackSimilarMBIDParams) (*lastfm.SimilarTracks, error) {
 	var res lastfm.SimilarTracks
-	return &res, t.api.Get(&res, TrackGetSimilarMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetSimilarMethod, params)
 }
 
 // Tags returns the tags of a track by artist and track name.
-func (t Track) Tags(params lastfm.TrackTagsParams) (*lastfm.TrackTags, error) {
+func (t Track) Tags(ctx context.Context, params lastfm.TrackTagsParams) (*lastfm.TrackTags, error) {
 	var res lastfm.TrackTags
-	return &res, t.api.Get(&res, TrackGetTagsMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetTagsMethod, params)
 }
 
 // TagsByMBID returns the tags of a track by MBID.
-func (t Track) TagsByMBID(params lastfm.TrackTagsMBIDParams) (*lastfm.TrackTags, error) {
+func (t Track) TagsByMBID(ctx context.Context, params lastfm.TrackTagsMBIDParams) (*lastfm.TrackTags, error) {
 	var res lastfm.TrackTags
-	return &res, t.api.Get(&res, TrackGetTagsMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetTagsMethod, params)
 }
 
 // TopTags returns the top tags of a track by artist and track name.
-func (t Track) TopTags(params lastfm.TrackTopTagsParams) (*lastfm.TrackTopTags, error) {
+func (t Track) TopTags(ctx context.Context, params lastfm.TrackTopTagsParams) (*lastfm.TrackTopTags, error) {
 	var res lastfm.TrackTopTags
-	return &res, t.api.Get(&res, TrackGetTopTagsMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetTopTagsMethod, params)
 }
 
 // TopTagsByMBID returns the top tags of a track by MBID.
-func (t Track) TopTagsByMBID(params lastfm.TrackTopTagsMBIDParams) (*lastfm.TrackTopTags, error) {
+func (t Track) TopTagsByMBID(ctx context.Context, params lastfm.TrackTopTagsMBIDParams) (*lastfm.TrackTopTags, error) {
 	var res lastfm.TrackTopTags
-	return &res, t.api.Get(&res, TrackGetTopTagsMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackGetTopTagsMethod, params)
 }
 
 // Search searches for tracks by track name, and optionally artist name.
-func (t Track) Search(params lastfm.TrackSearchParams) (*lastfm.TrackSearchResult, error) {
+func (t Track) Search(ctx context.Context, params lastfm.TrackSearchParams) (*lastfm.TrackSearchResult, error) {
 	var res lastfm.TrackSearchResult
-	return &res, t.api.Get(&res, TrackSearchMethod, params)
+	return &res, t.api.Get(ctx, &res, TrackSearchMethod, params)
 }
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"time"
 
 	"first.fm/internal/cache"
@@ -26,20 +27,20 @@ func NewUser(api *API) *User {
 }
 
 // Friends returns the friends of a user.
-func (u *User) Friends(params lastfm.FriendsParams) (*lastfm.Friends, error) {
+func (u *User) Friends(ctx context.Context, params lastfm.FriendsParams) (*lastfm.Friends, error) {
 	var res lastfm.Friends
-	return &res, u.api.Get(&res, UserGetFriendsMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetFriendsMethod, params)
 }
 
 // Info returns the information of a user with caching.
-func (u *User) Info(user string) (*lastfm.UserInfo, error) {
+func (u *User) Info(ctx context.Context, user string) (*lastfm.UserInfo, error) {
 	if cached, ok := u.InfoCache.Get(user); ok {
 		return cached, nil
 	}
 
 	var res lastfm.UserInfo
 	p := lastfm.UserInfoParams{User: user}
-	err := u.api.Get(&res, UserGetInfoMethod, p)
+	err := u.api.Get(ctx, &res, UserGetInfoMethod, p)
 	if err != nil {
 		return nil, err
 	}
@@ -49,96 +50,96 @@ func (u *User) Info(user string) (*lastfm.UserInfo, error) {
 }
 
 // LovedTracks returns the loved tracks of a user.
-func (u *User) LovedTracks(params lastfm.LovedTracksParams) (*lastfm.LovedTracks, error) {
+func (u *User) LovedTracks(ctx context.Context, params lastfm.LovedTracksParams) (*lastfm.LovedTracks, error) {
 	var res lastfm.LovedTracks
-	return &res, u.api.Get(&res, UserGetLovedTracksMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetLovedTracksMethod, params)
 }
 
 // RecentTrack returns the most recent track of a user. This is a convenience
 // method that calls RecentTracks with limit=1.
-func (u *User) RecentTrack(user string) (*lastfm.RecentTrack, error) {
+func (u *User) RecentTrack(ctx context.Context, user string) (*lastfm.RecentTrack, error) {
 	var res lastfm.RecentTrack
 	p := lastfm.RecentTracksParams{User: user, Limit: 1}
-	return &res, u.api.Get(&res, UserGetRecentTracksMethod, p)
+	return &res, u.api.Get(ctx, &res, UserGetRecentTracksMethod, p)
 }
 
 // RecentTracks returns the recent tracks of a user.
-func (u *User) RecentTracks(params lastfm.RecentTracksParams) (*lastfm.RecentTracks, error) {
+func (u *User) RecentTracks(ctx context.Context, params lastfm.RecentTracksParams) (*lastfm.RecentTracks, error) {
 	var res lastfm.RecentTracks
-	return &res, u.api.Get(&res, UserGetRecentTracksMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetRecentTracksMethod, params)
 }
 
 // RecentTrackExtended returns the most recent track of a user with extended
 // information. This is a convenience method that calls RecentTracksExtended
 // with limit=1.
-func (u *User) RecentTrackExtended(user string) (*lastfm.RecentTrackExtended, error) {
+func (u *User) RecentTrackExtended(ctx context.Context, user string) (*lastfm.RecentTrackExtended, error) {
 	var res lastfm.RecentTrackExtended
 	p := lastfm.RecentTracksParams{User: user, Limit: 1}
 	exp := recentTracksExtendedParams{RecentTracksParams: p, Extended: true}
-	return &res, u.api.Get(&res, UserGetRecentTracksMethod, exp)
+	return &res, u.api.Get(ctx, &res, UserGetRecentTracksMethod, exp)
 }
 
 // RecentTracksExtended returns the recent tracks of a user with extended
 // information.
 func (u *User) RecentTracksExtended(
-	params lastfm.RecentTracksParams) (*lastfm.RecentTracksExtended, error) {
+	ctx context.Context, params lastfm.RecentTracksParams) (*lastfm.RecentTracksExtended, error) {
 
 	var res lastfm.RecentTracksExtended
 	exp := recentTracksExtendedParams{RecentTracksParams: params, Extended: true}
-	return &res, u.api.Get(&res, UserGetRecentTracksMethod, exp)
+	return &res, u.api.Get(ctx, &res, UserGetRecentTracksMethod, exp)
 }
 
 // TopAlbums returns the top albums of a user.
-func (u *User) TopAlbums(params lastfm.UserTopAlbumsParams) (*lastfm.UserTopAlbums, error) {
+func (u *User) TopAlbums(ctx context.Context, params lastfm.UserTopAlbumsParams) (*lastfm.UserTopAlbums, error) {
 	var res lastfm.UserTopAlbums
-	return &res, u.api.Get(&res, UserGetTopAlbumsMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetTopAlbumsMethod, params)
 }
 
 // TopArtists returns the top artists of a user.
-func (u *User) TopArtists(params lastfm.UserTopArtistsParams) (*lastfm.UserTopArtists, error) {
+func (u *User) TopArtists(ctx context.Context, params lastfm.UserTopArtistsParams) (*lastfm.UserTopArtists, error) {
 	var res lastfm.UserTopArtists
-	return &res, u.api.Get(&res, UserGetTopArtistsMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetTopArtistsMethod, params)
 }
 
 // TopTags returns the top tags of a user.
-func (u *User) TopTags(params lastfm.UserTopTagsParams) (*lastfm.UserTopTags, error) {
+func (u *User) TopTags(ctx context.Context, params lastfm.UserTopTagsParams) (*lastfm.UserTopTags, error) {
 	var res lastfm.UserTopTags
-	return &res, u.api.Get(&res, UserGetTopTagsMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetTopTagsMethod, params)
 }
 
 // TopTracks returns the top tracks of a user.
-func (u *User) TopTracks(params lastfm.UserTopTracksParams) (*lastfm.UserTopTracks, error) {
+func (u *User) TopTracks(ctx context.Context, params lastfm.UserTopTracksParams) (*lastfm.UserTopTracks, error) {
 	var res lastfm.UserTopTracks
-	return &res, u.api.Get(&res, UserGetTopTracksMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetTopTracksMethod, params)
 }
 
 // WeeklyAlbumChart returns the weekly album chart of a user.
 func (u *User) WeeklyAlbumChart(
-	params lastfm.WeeklyAlbumChartParams) (*lastfm.WeeklyAlbumChart, error) {
+	ctx context.Context, params lastfm.WeeklyAlbumChartParams) (*lastfm.WeeklyAlbumChart, error) {
 
 	var res lastfm.WeeklyAlbumChart
-	return &res, u.api.Get(&res, UserGetWeeklyAlbumChartMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetWeeklyAlbumChartMethod, params)
 }
 
 // WeeklyArtistChart returns the weekly artist chart of a user.
 func (u *User) WeeklyArtistChart(
-	params lastfm.WeeklyArtistChartParams) (*lastfm.WeeklyArtistChart, error) {
+	ctx context.Context, params lastfm.WeeklyArtistChartParams) (*lastfm.WeeklyArtistChart, error) {
 
 	var res lastfm.WeeklyArtistChart
-	return &res, u.api.Get(&res, UserGetWeeklyArtistChartMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetWeeklyArtistChartMethod, params)
 }
 
 // WeeklyChartList returns the weekly chart list of a user.
-func (u *User) WeeklyChartList(user string) (*lastfm.WeeklyChartList, error) {
+func (u *User) WeeklyChartList(ctx context.Context, user string) (*lastfm.WeeklyChartList, error) {
 	var res lastfm.WeeklyChartList
 	p := lastfm.WeeklyChartListParams{User: user}
-	return &res, u.api.Get(&res, UserGetWeeklyChartListMethod, p)
+	return &res, u.api.Get(ctx, &res, UserGetWeeklyChartListMethod, p)
 }
 
 // WeeklyTrackChart returns the weekly track chart of a user.
 func (u *User) WeeklyTrackChart(
-	params lastfm.WeeklyTrackChartParams) (*lastfm.WeeklyTrackChart, error) {
+	ctx context.Context, params lastfm.WeeklyTrackChartParams) (*lastfm.WeeklyTrackChart, error) {
 
 	var res lastfm.WeeklyTrackChart
-	return &res, u.api.Get(&res, UserGetWeeklyTrackChartMethod, params)
+	return &res, u.api.Get(ctx, &res, UserGetWeeklyTrackChartMethod, params)
 }
@@ -7,19 +7,23 @@ type ChartTopArtistsParams struct {
 }
 
 type ChartTopArtists struct {
-	Page       int `xml:"page,attr"`
-	PerPage    int `xml:"perPage,attr"`
-	TotalPages int `xml:"totalPages,attr"`
-	Total      int `xml:"total,attr"`
-	Artists    []struct {
-		Name       string  `xml:"name"`
-		Playcount  int     `xml:"playcount"`
-		Listeners  int     `xml:"listeners"`
-		URL        string  `xml:"url"`
-		MBID       string  `xml:"mbid"`
-		Streamable IntBool `xml:"streamable"`
-		Image      Image   `xml:"image"`
-	} `xml:"artist"`
+	Page       int           `xml:"page,attr"`
+	PerPage    int           `xml:"perPage,attr"`
+	TotalPages int           `xml:"totalPages,attr"`
+	Total      int           `xml:"total,attr"`
+	Artists    []ChartArtist `xml:"artist"`
+}
+
+type ChartArtist struct {
+	Name       string       `xml:"name"`
+	Rank       int          `xml:"rank,attr"`
+	Playcount  int          `xml:"playcount"`
+	Listeners  int          `xml:"listeners"`
+	URL        string       `xml:"url"`
+	MBID       string       `xml:"mbid"`
+	Streamable IntBool      `xml:"streamable"`
+	Image      Image        `xml:"image"`
+	Movement   RankMovement `xml:"-"`
 }
 
 // https://www.last.fm/api/show/chart.getTopTags
@@ -29,18 +33,22 @@ type ChartTopTagsParams struct {
 }
 
 type ChartTopTags struct {
-	Page       int `xml:"page,attr"`
-	PerPage    int `xml:"perPage,attr"`
-	TotalPages int `xml:"totalPages,attr"`
-	Total      int `xml:"total,attr"`
-	Tags       []struct {
-		Name       string  `xml:"name"`
-		URL        string  `xml:"url"`
-		Reach      int     `xml:"reach"`
-		Count      int     `xml:"taggings"`
-		Streamable IntBool `xml:"streamable"`
-		Wiki       string  `xml:"wiki"`
-	} `xml:"tag"`
+	Page       int        `xml:"page,attr"`
+	PerPage    int        `xml:"perPage,attr"`
+	TotalPages int        `xml:"totalPages,attr"`
+	Total      int        `xml:"total,attr"`
+	Tags       []ChartTag `xml:"tag"`
+}
+
+type ChartTag struct {
+	Name       string       `xml:"name"`
+	Rank       int          `xml:"rank,attr"`
+	URL        string       `xml:"url"`
+	Reach      int          `xml:"reach"`
+	Count      int          `xml:"taggings"`
+	Streamable IntBool      `xml:"streamable"`
+	Wiki       string       `xml:"wiki"`
+	Movement   RankMovement `xml:"-"`
 }
 
 // https://www.last.fm/api/show/chart.getTopTracks
@@ -50,26 +58,67 @@ type ChartTopTracksParams struct {
 }
 
 type ChartTopTracks struct {
-	Page       int `xml:"page,attr"`
-	PerPage    int `xml:"perPage,attr"`
-	TotalPages int `xml:"totalPages,attr"`
-	Total      int `xml:"total,attr"`
-	Tracks     []struct {
-		Title      string   `xml:"name"`
-		Duration   Duration `xml:"duration"`
-		Playcount  int      `xml:"playcount"`
-		Listeners  int      `xml:"listeners"`
-		URL        string   `xml:"url"`
-		MBID       string   `xml:"mbid"`
-		Streamable struct {
-			Preview   IntBool `xml:",chardata"`
-			Fulltrack IntBool `xml:"fulltrack,attr"`
-		} `xml:"streamable"`
-		Artist struct {
-			Name string `xml:"name"`
-			URL  string `xml:"url"`
-			MBID string `xml:"mbid"`
-		} `xml:"artist"`
-		Image Image `xml:"image"`
-	} `xml:"track"`
+	Page       int          `xml:"page,attr"`
+	PerPage    int          `xml:"perPage,attr"`
+	TotalPages int          `xml:"totalPages,attr"`
+	Total      int          `xml:"total,attr"`
+	Tracks     []ChartTrack `xml:"track"`
+}
+
+type ChartTrack struct {
+	Title      string   `xml:"name"`
+	Rank       int      `xml:"rank,attr"`
+	Duration   Duration `xml:"duration"`
+	Playcount  int      `xml:"playcount"`
+	Listeners  int      `xml:"listeners"`
+	URL        string   `xml:"url"`
+	MBID       string   `xml:"mbid"`
+	Streamable struct {
+		Preview   IntBool `xml:",chardata"`
+		Fulltrack IntBool `xml:"fulltrack,attr"`
+	} `xml:"streamable"`
+	Artist struct {
+		Name string `xml:"name"`
+		URL  string `xml:"url"`
+		MBID string `xml:"mbid"`
+	} `xml:"artist"`
+	Image    Image        `xml:"image"`
+	Movement RankMovement `xml:"-"`
+}
+
+// ChartRank returns the rank of the item at index i of the given page. The
+// chart routes don't always send a rank attribute, so it is derived from the
+// position when missing.
+func ChartRank(rank, page, perPage, i int) int {
+	if rank > 0 {
+		return rank
+	}
+	if page < 1 {
+		page = 1
+	}
+	return (page-1)*perPage + i + 1
+}
+
+// RankMovement describes how a chart entry moved since the previous fetch.
+// It is not part of the API response; the Chart route fills it in.
+type RankMovement struct {
+	// Previous is the rank the entry had on the previous fetch, or 0 when the
+	// entry wasn't charted.
+	Previous int
+	// Known is false when there was no previous fetch to compare with.
+	Known bool
+}
+
+// IsNew reports whether the entry entered the chart since the previous fetch.
+func (m RankMovement) IsNew() bool {
+	return m.Known && m.Previous == 0
+}
+
+// Delta returns how many places the entry climbed since the previous fetch
+// given its current rank. Negative values mean it dropped.
+func (m RankMovement) Delta(rank int) int {
+	if !m.Known || m.Previous == 0 {
+		return 0
+	}
+	return m.Previous - rank
 }
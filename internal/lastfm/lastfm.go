@@ -5,6 +5,7 @@ package lastfm
 import (
 	"encoding/xml"
 	"regexp"
+	"strings"
 )
 
 const (
@@ -180,6 +181,29 @@ const (
 	PeriodYear    Period = "12month"
 )
 
+// Periods lists every period from the shortest to overall.
+var Periods = []Period{PeriodWeek, PeriodMonth, Period3Months, Period6Months, PeriodYear, PeriodOverall}
+
+// ParsePeriod parses a period from its API value or a common alias such as
+// "week", "month", "year" or "all".
+func ParsePeriod(s string) (Period, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "overall", "all", "alltime", "all time":
+		return PeriodOverall, true
+	case "7day", "7days", "week", "w":
+		return PeriodWeek, true
+	case "1month", "month", "m":
+		return PeriodMonth, true
+	case "3month", "3months", "quarter", "q":
+		return Period3Months, true
+	case "6month", "6months", "half", "h":
+		return Period6Months, true
+	case "12month", "12months", "year", "y":
+		return PeriodYear, true
+	}
+	return "", false
+}
+
 type TagType string
 
 const (
@@ -0,0 +1,226 @@
+package lastfm
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SearchKind is the kind of entity a SearchHit refers to.
+type SearchKind string
+
+const (
+	SearchKindArtist SearchKind = "artist"
+	SearchKindAlbum  SearchKind = "album"
+	SearchKindTrack  SearchKind = "track"
+)
+
+// SearchParams are the parameters of a unified search across artists, albums
+// and tracks.
+type SearchParams struct {
+	Query string
+	// Kinds limits the search to the given kinds. Empty means all of them.
+	Kinds []SearchKind
+	// Limit is the number of results requested per kind.
+	Limit uint
+}
+
+// HasKind reports whether the given kind should be searched.
+func (p SearchParams) HasKind(kind SearchKind) bool {
+	if len(p.Kinds) == 0 {
+		return true
+	}
+	for _, k := range p.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchHit is a single ranked result of a unified search.
+type SearchHit struct {
+	Kind SearchKind
+	// Name is the artist name, album title or track title.
+	Name string
+	// Artist is the artist of an album or track. Empty for artists.
+	Artist    string
+	Listeners int
+	URL       string
+	MBID      string
+	Image     Image
+	Score     float64
+}
+
+// maxChoiceLength is the maximum length Discord accepts for autocomplete
+// choice names and values.
+const maxChoiceLength = 100
+
+// searchValueSeparator joins the artist and name of album and track values.
+// It is the ASCII unit separator, which Last.fm names can't contain, unlike
+// " - " which is part of many artist names.
+const searchValueSeparator = "\x1f"
+
+// ErrSearchValueTooLong is returned by SearchHit.Value when the hit doesn't
+// fit an autocomplete choice value.
+var ErrSearchValueTooLong = errors.New("search value is longer than 100 characters")
+
+// Label returns a human readable representation of the hit, truncated so it
+// fits an autocomplete choice name.
+func (h SearchHit) Label() string {
+	label := h.Name
+	if h.Artist != "" {
+		label = h.Artist + " - " + h.Name
+	}
+	return truncate(label, maxChoiceLength)
+}
+
+// Value returns the value an autocomplete choice should submit for the hit.
+// Albums and tracks carry their artist so ParseSearchValue can split them
+// again. Values are never truncated: hits too long for a choice value fail
+// with ErrSearchValueTooLong and should be left out.
+func (h SearchHit) Value() (string, error) {
+	value := h.Name
+	if h.Artist != "" {
+		value = h.Artist + searchValueSeparator + h.Name
+	}
+	if utf8.RuneCountInString(value) > maxChoiceLength {
+		return "", ErrSearchValueTooLong
+	}
+	return value, nil
+}
+
+// ParseSearchValue splits a value produced by SearchHit.Value into artist and
+// name. Values typed by the user instead of picked from the choices have no
+// artist part, in which case artist is empty.
+func ParseSearchValue(value string) (artist, name string) {
+	if before, after, ok := strings.Cut(value, searchValueSeparator); ok {
+		return strings.TrimSpace(before), strings.TrimSpace(after)
+	}
+	return "", strings.TrimSpace(value)
+}
+
+// SearchResults is the merged result of a unified search.
+type SearchResults struct {
+	Query string
+	Hits  []SearchHit
+}
+
+// Filter returns the hits of the given kind, preserving their order.
+func (r SearchResults) Filter(kind SearchKind) []SearchHit {
+	var hits []SearchHit
+	for _, h := range r.Hits {
+		if h.Kind == kind {
+			hits = append(hits, h)
+		}
+	}
+	return hits
+}
+
+// ArtistHits converts an artist search result into search hits.
+func ArtistHits(res *ArtistSearchResult) []SearchHit {
+	hits := make([]SearchHit, 0, len(res.Artists))
+	for _, a := range res.Artists {
+		hits = append(hits, SearchHit{
+			Kind:      SearchKindArtist,
+			Name:      a.Name,
+			Listeners: a.Listeners,
+			URL:       a.URL,
+			MBID:      a.MBID,
+			Image:     a.Image,
+		})
+	}
+	return hits
+}
+
+// AlbumHits converts an album search result into search hits.
+func AlbumHits(res *AlbumSearchResult) []SearchHit {
+	hits := make([]SearchHit, 0, len(res.Albums))
+	for _, a := range res.Albums {
+		hits = append(hits, SearchHit{
+			Kind:   SearchKindAlbum,
+			Name:   a.Title,
+			Artist: a.Artist,
+			URL:    a.URL,
+			MBID:   a.MBID,
+			Image:  a.Image,
+		})
+	}
+	return hits
+}
+
+// TrackHits converts a track search result into search hits.
+func TrackHits(res *TrackSearchResult) []SearchHit {
+	hits := make([]SearchHit, 0, len(res.Tracks))
+	for _, t := range res.Tracks {
+		hits = append(hits, SearchHit{
+			Kind:      SearchKindTrack,
+			Name:      t.Title,
+			Artist:    t.Artist,
+			Listeners: t.Listeners,
+			URL:       t.URL,
+			MBID:      t.MBID,
+			Image:     t.Image,
+		})
+	}
+	return hits
+}
+
+// RankSearchHits scores every hit against the query and sorts them by score,
+// highest first. Exact matches rank above prefix matches, which rank above
+// substring matches; listeners break ties within those groups. Album search
+// results carry no listener count, so they only rank by match quality and
+// their original position.
+func RankSearchHits(query string, hits []SearchHit) []SearchHit {
+	q := normalizeSearch(query)
+	positions := map[SearchKind]int{}
+	for i := range hits {
+		hits[i].Score = scoreHit(q, hits[i], positions[hits[i].Kind])
+		positions[hits[i].Kind]++
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	return hits
+}
+
+func scoreHit(query string, hit SearchHit, position int) float64 {
+	name := normalizeSearch(hit.Name)
+	full := normalizeSearch(hit.Artist + " " + hit.Name)
+
+	var score float64
+	switch {
+	case query == "":
+	case name == query || full == query:
+		score += 100
+	case strings.HasPrefix(name, query) || strings.HasPrefix(full, query):
+		score += 60
+	case strings.Contains(name, query) || strings.Contains(full, query):
+		score += 30
+	}
+
+	// log10 keeps popularity from overriding match quality: a track with 10M
+	// listeners gains 7 points over one with a single listener.
+	if hit.Listeners > 0 {
+		score += math.Log10(float64(hit.Listeners))
+	}
+
+	// preserve the order Last.fm returned within otherwise equal hits
+	score -= float64(position) * 0.001
+	return score
+}
+
+func normalizeSearch(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
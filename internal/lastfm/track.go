@@ -372,12 +372,13 @@ type TrackSearchResult struct {
 	Tracks       []struct {
 		Title  string `xml:"name"`
 		Artist string `xml:"artist"`
-		// All values returned from the Last.fm API are "FIXME". API issue?
-		Streamable string `xml:"streamable"`
-		Listeners  int    `xml:"listeners"`
-		URL        string `xml:"url"`
-		MBID       string `xml:"mbid"`
-		Image      Image  `xml:"image"`
+		// All values returned from the Last.fm API are "FIXME", which
+		// LenientIntBool decodes as false.
+		Streamable LenientIntBool `xml:"streamable"`
+		Listeners  int            `xml:"listeners"`
+		URL        string         `xml:"url"`
+		MBID       string         `xml:"mbid"`
+		Image      Image          `xml:"image"`
 	} `xml:"trackmatches>track"`
 }
 
@@ -49,6 +49,8 @@ func EncodeToValues(v any) (url.Values, error) {
 			} else {
 				str = strconv.FormatInt(val.Int(), 10)
 			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			str = strconv.FormatUint(val.Uint(), 10)
 		case reflect.Bool:
 			if intFormat {
 				if val.Bool() {
@@ -99,6 +101,25 @@ func (b *IntBool) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return nil
 }
 
+// LenientIntBool is an IntBool that decodes anything but 1 as false. It is
+// only meant for fields Last.fm is known to fill with placeholders, such as
+// the "FIXME" streamable value of track search results.
+type LenientIntBool bool
+
+func (b LenientIntBool) Bool() bool {
+	return bool(b)
+}
+
+// UnmarshalXML implements the xml.Unmarshaler interface for LenientIntBool.
+func (b *LenientIntBool) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	*b = strings.TrimSpace(s) == "1"
+	return nil
+}
+
 // DateTime wraps time.Time and represents a Last.fm DateTime.
 type DateTime time.Time
 
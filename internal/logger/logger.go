@@ -246,7 +246,11 @@ func (l *Logger) Warnw(msg string, f F, a ...any)  { l.Log(LevelWarn, fmt.Sprint
 func (l *Logger) Errorw(msg string, f F, a ...any) { l.Log(LevelError, fmt.Sprintf(msg, a...), f) }
 func (l *Logger) Fatalw(msg string, f F, a ...any) { l.Log(LevelFatal, fmt.Sprintf(msg, a...), f) }
 
+// Default returns the logger used by the package level functions.
+func Default() *Logger { return std }
+
 // Std shortcuts
+
 func SetOutput(w io.Writer)    { std.SetOutput(w) }
 func SetLevel(l Level)         { std.SetLevel(l) }
 func EnableTimestamps(on bool) { std.EnableTimestamps(on) }
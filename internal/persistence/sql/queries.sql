@@ -20,3 +20,169 @@ WHERE user_id = :user_id;
 -- name: GetAllUsers :many
 SELECT user_id, lastfm_username, created_at
 FROM users;
+
+-- name: CountUsers :one
+SELECT COUNT(*)
+FROM users;
+
+-- name: SetPresenceOptIn :exec
+INSERT INTO presence_opt_ins (user_id)
+VALUES (:user_id)
+ON CONFLICT(user_id) DO NOTHING;
+
+-- name: DeletePresenceOptIn :exec
+DELETE FROM presence_opt_ins
+WHERE user_id = :user_id;
+
+-- name: ListPresenceUsers :many
+SELECT u.lastfm_username
+FROM presence_opt_ins p
+JOIN users u ON u.user_id = p.user_id
+ORDER BY RANDOM()
+LIMIT :limit;
+
+-- name: AddGuildMember :exec
+INSERT INTO guild_members (guild_id, user_id, username, global_name, nick)
+VALUES (:guild_id, :user_id, :username, :global_name, :nick)
+ON CONFLICT(guild_id, user_id) DO UPDATE SET
+    username = excluded.username,
+    global_name = excluded.global_name,
+    nick = excluded.nick;
+
+-- name: AddGuildMembers :exec
+INSERT INTO guild_members (guild_id, user_id, username, global_name, nick)
+SELECT :guild_id,
+       json_extract(value, '$.id'),
+       json_extract(value, '$.username'),
+       json_extract(value, '$.global_name'),
+       json_extract(value, '$.nick')
+FROM json_each(CAST(:members AS TEXT))
+WHERE true
+ON CONFLICT(guild_id, user_id) DO UPDATE SET
+    username = excluded.username,
+    global_name = excluded.global_name,
+    nick = excluded.nick;
+
+-- name: FindGuildMember :one
+SELECT user_id
+FROM guild_members
+WHERE guild_id = :guild_id
+  AND :name IN (username, global_name, nick)
+LIMIT 1;
+
+-- name: RemoveGuildMember :exec
+DELETE FROM guild_members
+WHERE guild_id = :guild_id AND user_id = :user_id;
+
+-- name: PruneGuildMembers :exec
+DELETE FROM guild_members
+WHERE guild_id = :guild_id
+  AND user_id NOT IN (SELECT value FROM json_each(CAST(:user_ids AS TEXT)));
+
+-- name: DeleteGuildMembers :exec
+DELETE FROM guild_members
+WHERE guild_id = :guild_id;
+
+-- name: DeleteUserGuildMembers :exec
+DELETE FROM guild_members
+WHERE user_id = :user_id;
+
+-- name: CountGuilds :one
+SELECT COUNT(DISTINCT guild_id)
+FROM guild_members;
+
+-- name: ListGuildPlaycounts :many
+SELECT u.user_id, u.lastfm_username, p.playcount, p.updated_at
+FROM guild_members m
+JOIN users u ON u.user_id = m.user_id
+LEFT JOIN lastfm_playcounts p ON p.lastfm_username = u.lastfm_username
+WHERE m.guild_id = :guild_id
+ORDER BY p.updated_at IS NOT NULL, p.updated_at;
+
+-- name: SetPlaycount :exec
+INSERT INTO lastfm_playcounts (lastfm_username, playcount, updated_at)
+VALUES (:lastfm_username, :playcount, :updated_at)
+ON CONFLICT(lastfm_username) DO UPDATE SET
+    playcount = excluded.playcount,
+    updated_at = excluded.updated_at;
+
+-- name: DeletePlaycount :exec
+DELETE FROM lastfm_playcounts
+WHERE lastfm_username = :lastfm_username;
+
+-- name: ListGuildPrefixes :many
+SELECT guild_id, prefix
+FROM guild_prefixes;
+
+-- name: SetGuildPrefix :exec
+INSERT INTO guild_prefixes (guild_id, prefix)
+VALUES (:guild_id, :prefix)
+ON CONFLICT(guild_id) DO UPDATE SET
+    prefix = excluded.prefix,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteGuildPrefix :exec
+DELETE FROM guild_prefixes
+WHERE guild_id = :guild_id;
+
+-- name: DisableCommand :exec
+INSERT INTO guild_disabled_commands (guild_id, command)
+VALUES (:guild_id, :command)
+ON CONFLICT(guild_id, command) DO NOTHING;
+
+-- name: EnableCommand :exec
+DELETE FROM guild_disabled_commands
+WHERE guild_id = :guild_id AND command = :command;
+
+-- name: IsCommandDisabled :one
+SELECT COUNT(*)
+FROM guild_disabled_commands
+WHERE guild_id = :guild_id AND command = :command;
+
+-- name: ListDisabledCommands :many
+SELECT command
+FROM guild_disabled_commands
+WHERE guild_id = :guild_id
+ORDER BY command;
+
+-- name: DeleteGuildDisabledCommands :exec
+DELETE FROM guild_disabled_commands
+WHERE guild_id = :guild_id;
+
+-- name: TakeCooldown :execrows
+INSERT INTO command_cooldowns (key, expires_at)
+VALUES (:key, :expires_at)
+ON CONFLICT(key) DO UPDATE SET
+    expires_at = excluded.expires_at
+WHERE command_cooldowns.expires_at <= :now;
+
+-- name: GetCooldown :one
+SELECT expires_at
+FROM command_cooldowns
+WHERE key = :key;
+
+-- name: CountCooldowns :one
+SELECT COUNT(*)
+FROM command_cooldowns
+WHERE expires_at > :now;
+
+-- name: DeleteExpiredCooldowns :exec
+DELETE FROM command_cooldowns
+WHERE expires_at <= :now;
+
+-- name: AcquireSlot :execrows
+INSERT INTO command_slots (path, holder, expires_at)
+SELECT :path, :holder, :expires_at
+WHERE (
+    SELECT COUNT(*)
+    FROM command_slots
+    WHERE path = :path AND expires_at > :now
+) < :slots;
+
+-- name: ReleaseSlot :exec
+DELETE FROM command_slots
+WHERE path = :path AND holder = :holder;
+
+-- name: DeleteExpiredSlots :exec
+DELETE FROM command_slots
+WHERE expires_at <= :now;
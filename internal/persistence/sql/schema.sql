@@ -6,3 +6,57 @@ CREATE TABLE IF NOT EXISTS users (
 
 CREATE UNIQUE INDEX IF NOT EXISTS idx_users_lastfm_username
 ON users(lastfm_username);
+
+-- playcounts of linked Last.fm accounts, refreshed by the leaderboard
+CREATE TABLE IF NOT EXISTS lastfm_playcounts (
+    lastfm_username TEXT PRIMARY KEY,
+    playcount       INTEGER NOT NULL,
+    updated_at      DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS presence_opt_ins (
+    user_id    TEXT PRIMARY KEY,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- names are stored lowercased so members can be found by name
+CREATE TABLE IF NOT EXISTS guild_members (
+    guild_id    TEXT NOT NULL,
+    user_id     TEXT NOT NULL,
+    username    TEXT NOT NULL DEFAULT '',
+    global_name TEXT NOT NULL DEFAULT '',
+    nick        TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (guild_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_guild_members_user_id
+ON guild_members(user_id);
+
+CREATE TABLE IF NOT EXISTS guild_prefixes (
+    guild_id   TEXT PRIMARY KEY,
+    prefix     TEXT NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS guild_disabled_commands (
+    guild_id TEXT NOT NULL,
+    command  TEXT NOT NULL,
+    PRIMARY KEY (guild_id, command)
+);
+
+-- user cooldowns and concurrency slots, shared by every process using the
+-- database; times are unix milliseconds
+CREATE TABLE IF NOT EXISTS command_cooldowns (
+    key        TEXT PRIMARY KEY,
+    expires_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_command_cooldowns_expires_at
+ON command_cooldowns(expires_at);
+
+CREATE TABLE IF NOT EXISTS command_slots (
+    path       TEXT NOT NULL,
+    holder     TEXT NOT NULL,
+    expires_at INTEGER NOT NULL,
+    PRIMARY KEY (path, holder)
+);
@@ -0,0 +1,10 @@
+// Package sql holds the schema and queries sqlc generates the sqlc package
+// from.
+package sql
+
+import _ "embed"
+
+// Schema creates the tables and indexes when they don't exist yet.
+//
+//go:embed schema.sql
+var Schema string
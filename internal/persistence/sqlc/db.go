@@ -24,18 +24,108 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.acquireSlotStmt, err = db.PrepareContext(ctx, acquireSlot); err != nil {
+		return nil, fmt.Errorf("error preparing query AcquireSlot: %w", err)
+	}
+	if q.addGuildMemberStmt, err = db.PrepareContext(ctx, addGuildMember); err != nil {
+		return nil, fmt.Errorf("error preparing query AddGuildMember: %w", err)
+	}
+	if q.addGuildMembersStmt, err = db.PrepareContext(ctx, addGuildMembers); err != nil {
+		return nil, fmt.Errorf("error preparing query AddGuildMembers: %w", err)
+	}
+	if q.countCooldownsStmt, err = db.PrepareContext(ctx, countCooldowns); err != nil {
+		return nil, fmt.Errorf("error preparing query CountCooldowns: %w", err)
+	}
+	if q.countGuildsStmt, err = db.PrepareContext(ctx, countGuilds); err != nil {
+		return nil, fmt.Errorf("error preparing query CountGuilds: %w", err)
+	}
+	if q.countUsersStmt, err = db.PrepareContext(ctx, countUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUsers: %w", err)
+	}
+	if q.deleteExpiredCooldownsStmt, err = db.PrepareContext(ctx, deleteExpiredCooldowns); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredCooldowns: %w", err)
+	}
+	if q.deleteExpiredSlotsStmt, err = db.PrepareContext(ctx, deleteExpiredSlots); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredSlots: %w", err)
+	}
+	if q.deleteGuildDisabledCommandsStmt, err = db.PrepareContext(ctx, deleteGuildDisabledCommands); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteGuildDisabledCommands: %w", err)
+	}
+	if q.deleteGuildMembersStmt, err = db.PrepareContext(ctx, deleteGuildMembers); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteGuildMembers: %w", err)
+	}
+	if q.deleteGuildPrefixStmt, err = db.PrepareContext(ctx, deleteGuildPrefix); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteGuildPrefix: %w", err)
+	}
+	if q.deletePlaycountStmt, err = db.PrepareContext(ctx, deletePlaycount); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePlaycount: %w", err)
+	}
+	if q.deletePresenceOptInStmt, err = db.PrepareContext(ctx, deletePresenceOptIn); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePresenceOptIn: %w", err)
+	}
 	if q.deleteUserStmt, err = db.PrepareContext(ctx, deleteUser); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteUser: %w", err)
 	}
+	if q.deleteUserGuildMembersStmt, err = db.PrepareContext(ctx, deleteUserGuildMembers); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserGuildMembers: %w", err)
+	}
+	if q.disableCommandStmt, err = db.PrepareContext(ctx, disableCommand); err != nil {
+		return nil, fmt.Errorf("error preparing query DisableCommand: %w", err)
+	}
+	if q.enableCommandStmt, err = db.PrepareContext(ctx, enableCommand); err != nil {
+		return nil, fmt.Errorf("error preparing query EnableCommand: %w", err)
+	}
+	if q.findGuildMemberStmt, err = db.PrepareContext(ctx, findGuildMember); err != nil {
+		return nil, fmt.Errorf("error preparing query FindGuildMember: %w", err)
+	}
 	if q.getAllUsersStmt, err = db.PrepareContext(ctx, getAllUsers); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAllUsers: %w", err)
 	}
+	if q.getCooldownStmt, err = db.PrepareContext(ctx, getCooldown); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCooldown: %w", err)
+	}
 	if q.getUserByIDStmt, err = db.PrepareContext(ctx, getUserByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserByID: %w", err)
 	}
 	if q.getUserByLastFMStmt, err = db.PrepareContext(ctx, getUserByLastFM); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUserByLastFM: %w", err)
 	}
+	if q.isCommandDisabledStmt, err = db.PrepareContext(ctx, isCommandDisabled); err != nil {
+		return nil, fmt.Errorf("error preparing query IsCommandDisabled: %w", err)
+	}
+	if q.listDisabledCommandsStmt, err = db.PrepareContext(ctx, listDisabledCommands); err != nil {
+		return nil, fmt.Errorf("error preparing query ListDisabledCommands: %w", err)
+	}
+	if q.listGuildPlaycountsStmt, err = db.PrepareContext(ctx, listGuildPlaycounts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListGuildPlaycounts: %w", err)
+	}
+	if q.listGuildPrefixesStmt, err = db.PrepareContext(ctx, listGuildPrefixes); err != nil {
+		return nil, fmt.Errorf("error preparing query ListGuildPrefixes: %w", err)
+	}
+	if q.listPresenceUsersStmt, err = db.PrepareContext(ctx, listPresenceUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query ListPresenceUsers: %w", err)
+	}
+	if q.pruneGuildMembersStmt, err = db.PrepareContext(ctx, pruneGuildMembers); err != nil {
+		return nil, fmt.Errorf("error preparing query PruneGuildMembers: %w", err)
+	}
+	if q.releaseSlotStmt, err = db.PrepareContext(ctx, releaseSlot); err != nil {
+		return nil, fmt.Errorf("error preparing query ReleaseSlot: %w", err)
+	}
+	if q.removeGuildMemberStmt, err = db.PrepareContext(ctx, removeGuildMember); err != nil {
+		return nil, fmt.Errorf("error preparing query RemoveGuildMember: %w", err)
+	}
+	if q.setGuildPrefixStmt, err = db.PrepareContext(ctx, setGuildPrefix); err != nil {
+		return nil, fmt.Errorf("error preparing query SetGuildPrefix: %w", err)
+	}
+	if q.setPlaycountStmt, err = db.PrepareContext(ctx, setPlaycount); err != nil {
+		return nil, fmt.Errorf("error preparing query SetPlaycount: %w", err)
+	}
+	if q.setPresenceOptInStmt, err = db.PrepareContext(ctx, setPresenceOptIn); err != nil {
+		return nil, fmt.Errorf("error preparing query SetPresenceOptIn: %w", err)
+	}
+	if q.takeCooldownStmt, err = db.PrepareContext(ctx, takeCooldown); err != nil {
+		return nil, fmt.Errorf("error preparing query TakeCooldown: %w", err)
+	}
 	if q.upsertUserStmt, err = db.PrepareContext(ctx, upsertUser); err != nil {
 		return nil, fmt.Errorf("error preparing query UpsertUser: %w", err)
 	}
@@ -44,16 +134,106 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 
 func (q *Queries) Close() error {
 	var err error
+	if q.acquireSlotStmt != nil {
+		if cerr := q.acquireSlotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing acquireSlotStmt: %w", cerr)
+		}
+	}
+	if q.addGuildMemberStmt != nil {
+		if cerr := q.addGuildMemberStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addGuildMemberStmt: %w", cerr)
+		}
+	}
+	if q.addGuildMembersStmt != nil {
+		if cerr := q.addGuildMembersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addGuildMembersStmt: %w", cerr)
+		}
+	}
+	if q.countCooldownsStmt != nil {
+		if cerr := q.countCooldownsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countCooldownsStmt: %w", cerr)
+		}
+	}
+	if q.countGuildsStmt != nil {
+		if cerr := q.countGuildsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countGuildsStmt: %w", cerr)
+		}
+	}
+	if q.countUsersStmt != nil {
+		if cerr := q.countUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUsersStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredCooldownsStmt != nil {
+		if cerr := q.deleteExpiredCooldownsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredCooldownsStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredSlotsStmt != nil {
+		if cerr := q.deleteExpiredSlotsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredSlotsStmt: %w", cerr)
+		}
+	}
+	if q.deleteGuildDisabledCommandsStmt != nil {
+		if cerr := q.deleteGuildDisabledCommandsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteGuildDisabledCommandsStmt: %w", cerr)
+		}
+	}
+	if q.deleteGuildMembersStmt != nil {
+		if cerr := q.deleteGuildMembersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteGuildMembersStmt: %w", cerr)
+		}
+	}
+	if q.deleteGuildPrefixStmt != nil {
+		if cerr := q.deleteGuildPrefixStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteGuildPrefixStmt: %w", cerr)
+		}
+	}
+	if q.deletePlaycountStmt != nil {
+		if cerr := q.deletePlaycountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePlaycountStmt: %w", cerr)
+		}
+	}
+	if q.deletePresenceOptInStmt != nil {
+		if cerr := q.deletePresenceOptInStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePresenceOptInStmt: %w", cerr)
+		}
+	}
 	if q.deleteUserStmt != nil {
 		if cerr := q.deleteUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteUserStmt: %w", cerr)
 		}
 	}
+	if q.deleteUserGuildMembersStmt != nil {
+		if cerr := q.deleteUserGuildMembersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserGuildMembersStmt: %w", cerr)
+		}
+	}
+	if q.disableCommandStmt != nil {
+		if cerr := q.disableCommandStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing disableCommandStmt: %w", cerr)
+		}
+	}
+	if q.enableCommandStmt != nil {
+		if cerr := q.enableCommandStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing enableCommandStmt: %w", cerr)
+		}
+	}
+	if q.findGuildMemberStmt != nil {
+		if cerr := q.findGuildMemberStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing findGuildMemberStmt: %w", cerr)
+		}
+	}
 	if q.getAllUsersStmt != nil {
 		if cerr := q.getAllUsersStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAllUsersStmt: %w", cerr)
 		}
 	}
+	if q.getCooldownStmt != nil {
+		if cerr := q.getCooldownStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCooldownStmt: %w", cerr)
+		}
+	}
 	if q.getUserByIDStmt != nil {
 		if cerr := q.getUserByIDStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getUserByIDStmt: %w", cerr)
@@ -64,6 +244,66 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getUserByLastFMStmt: %w", cerr)
 		}
 	}
+	if q.isCommandDisabledStmt != nil {
+		if cerr := q.isCommandDisabledStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isCommandDisabledStmt: %w", cerr)
+		}
+	}
+	if q.listDisabledCommandsStmt != nil {
+		if cerr := q.listDisabledCommandsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listDisabledCommandsStmt: %w", cerr)
+		}
+	}
+	if q.listGuildPlaycountsStmt != nil {
+		if cerr := q.listGuildPlaycountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listGuildPlaycountsStmt: %w", cerr)
+		}
+	}
+	if q.listGuildPrefixesStmt != nil {
+		if cerr := q.listGuildPrefixesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listGuildPrefixesStmt: %w", cerr)
+		}
+	}
+	if q.listPresenceUsersStmt != nil {
+		if cerr := q.listPresenceUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listPresenceUsersStmt: %w", cerr)
+		}
+	}
+	if q.pruneGuildMembersStmt != nil {
+		if cerr := q.pruneGuildMembersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing pruneGuildMembersStmt: %w", cerr)
+		}
+	}
+	if q.releaseSlotStmt != nil {
+		if cerr := q.releaseSlotStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing releaseSlotStmt: %w", cerr)
+		}
+	}
+	if q.removeGuildMemberStmt != nil {
+		if cerr := q.removeGuildMemberStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing removeGuildMemberStmt: %w", cerr)
+		}
+	}
+	if q.setGuildPrefixStmt != nil {
+		if cerr := q.setGuildPrefixStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setGuildPrefixStmt: %w", cerr)
+		}
+	}
+	if q.setPlaycountStmt != nil {
+		if cerr := q.setPlaycountStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setPlaycountStmt: %w", cerr)
+		}
+	}
+	if q.setPresenceOptInStmt != nil {
+		if cerr := q.setPresenceOptInStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setPresenceOptInStmt: %w", cerr)
+		}
+	}
+	if q.takeCooldownStmt != nil {
+		if cerr := q.takeCooldownStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing takeCooldownStmt: %w", cerr)
+		}
+	}
 	if q.upsertUserStmt != nil {
 		if cerr := q.upsertUserStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing upsertUserStmt: %w", cerr)
@@ -106,23 +346,83 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                  DBTX
-	tx                  *sql.Tx
-	deleteUserStmt      *sql.Stmt
-	getAllUsersStmt     *sql.Stmt
-	getUserByIDStmt     *sql.Stmt
-	getUserByLastFMStmt *sql.Stmt
-	upsertUserStmt      *sql.Stmt
+	db                              DBTX
+	tx                              *sql.Tx
+	acquireSlotStmt                 *sql.Stmt
+	addGuildMemberStmt              *sql.Stmt
+	addGuildMembersStmt             *sql.Stmt
+	countCooldownsStmt              *sql.Stmt
+	countGuildsStmt                 *sql.Stmt
+	countUsersStmt                  *sql.Stmt
+	deleteExpiredCooldownsStmt      *sql.Stmt
+	deleteExpiredSlotsStmt          *sql.Stmt
+	deleteGuildDisabledCommandsStmt *sql.Stmt
+	deleteGuildMembersStmt          *sql.Stmt
+	deleteGuildPrefixStmt           *sql.Stmt
+	deletePlaycountStmt             *sql.Stmt
+	deletePresenceOptInStmt         *sql.Stmt
+	deleteUserStmt                  *sql.Stmt
+	deleteUserGuildMembersStmt      *sql.Stmt
+	disableCommandStmt              *sql.Stmt
+	enableCommandStmt               *sql.Stmt
+	findGuildMemberStmt             *sql.Stmt
+	getAllUsersStmt                 *sql.Stmt
+	getCooldownStmt                 *sql.Stmt
+	getUserByIDStmt                 *sql.Stmt
+	getUserByLastFMStmt             *sql.Stmt
+	isCommandDisabledStmt           *sql.Stmt
+	listDisabledCommandsStmt        *sql.Stmt
+	listGuildPlaycountsStmt         *sql.Stmt
+	listGuildPrefixesStmt           *sql.Stmt
+	listPresenceUsersStmt           *sql.Stmt
+	pruneGuildMembersStmt           *sql.Stmt
+	releaseSlotStmt                 *sql.Stmt
+	removeGuildMemberStmt           *sql.Stmt
+	setGuildPrefixStmt              *sql.Stmt
+	setPlaycountStmt                *sql.Stmt
+	setPresenceOptInStmt            *sql.Stmt
+	takeCooldownStmt                *sql.Stmt
+	upsertUserStmt                  *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                  tx,
-		tx:                  tx,
-		deleteUserStmt:      q.deleteUserStmt,
-		getAllUsersStmt:     q.getAllUsersStmt,
-		getUserByIDStmt:     q.getUserByIDStmt,
-		getUserByLastFMStmt: q.getUserByLastFMStmt,
-		upsertUserStmt:      q.upsertUserStmt,
+		db:                              tx,
+		tx:                              tx,
+		acquireSlotStmt:                 q.acquireSlotStmt,
+		addGuildMemberStmt:              q.addGuildMemberStmt,
+		addGuildMembersStmt:             q.addGuildMembersStmt,
+		countCooldownsStmt:              q.countCooldownsStmt,
+		countGuildsStmt:                 q.countGuildsStmt,
+		countUsersStmt:                  q.countUsersStmt,
+		deleteExpiredCooldownsStmt:      q.deleteExpiredCooldownsStmt,
+		deleteExpiredSlotsStmt:          q.deleteExpiredSlotsStmt,
+		deleteGuildDisabledCommandsStmt: q.deleteGuildDisabledCommandsStmt,
+		deleteGuildMembersStmt:          q.deleteGuildMembersStmt,
+		deleteGuildPrefixStmt:           q.deleteGuildPrefixStmt,
+		deletePlaycountStmt:             q.deletePlaycountStmt,
+		deletePresenceOptInStmt:         q.deletePresenceOptInStmt,
+		deleteUserStmt:                  q.deleteUserStmt,
+		deleteUserGuildMembersStmt:      q.deleteUserGuildMembersStmt,
+		disableCommandStmt:              q.disableCommandStmt,
+		enableCommandStmt:               q.enableCommandStmt,
+		findGuildMemberStmt:             q.findGuildMemberStmt,
+		getAllUsersStmt:                 q.getAllUsersStmt,
+		getCooldownStmt:                 q.getCooldownStmt,
+		getUserByIDStmt:                 q.getUserByIDStmt,
+		getUserByLastFMStmt:             q.getUserByLastFMStmt,
+		isCommandDisabledStmt:           q.isCommandDisabledStmt,
+		listDisabledCommandsStmt:        q.listDisabledCommandsStmt,
+		listGuildPlaycountsStmt:         q.listGuildPlaycountsStmt,
+		listGuildPrefixesStmt:           q.listGuildPrefixesStmt,
+		listPresenceUsersStmt:           q.listPresenceUsersStmt,
+		pruneGuildMembersStmt:           q.pruneGuildMembersStmt,
+		releaseSlotStmt:                 q.releaseSlotStmt,
+		removeGuildMemberStmt:           q.removeGuildMemberStmt,
+		setGuildPrefixStmt:              q.setGuildPrefixStmt,
+		setPlaycountStmt:                q.setPlaycountStmt,
+		setPresenceOptInStmt:            q.setPresenceOptInStmt,
+		takeCooldownStmt:                q.takeCooldownStmt,
+		upsertUserStmt:                  q.upsertUserStmt,
 	}
 }
@@ -7,10 +7,204 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"first.fm/internal/persistence/shared"
 )
 
+const acquireSlot = `-- name: AcquireSlot :execrows
+INSERT INTO command_slots (path, holder, expires_at)
+SELECT ?1, ?2, ?3
+WHERE (
+    SELECT COUNT(*)
+    FROM command_slots
+    WHERE path = ?1 AND expires_at > ?4
+) < ?5
+`
+
+type AcquireSlotParams struct {
+	Path      string
+	Holder    string
+	ExpiresAt int64
+	Now       int64
+	Slots     int64
+}
+
+func (q *Queries) AcquireSlot(ctx context.Context, arg AcquireSlotParams) (int64, error) {
+	result, err := q.exec(ctx, q.acquireSlotStmt, acquireSlot,
+		arg.Path,
+		arg.Holder,
+		arg.ExpiresAt,
+		arg.Now,
+		arg.Slots,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const addGuildMember = `-- name: AddGuildMember :exec
+INSERT INTO guild_members (guild_id, user_id, username, global_name, nick)
+VALUES (?1, ?2, ?3, ?4, ?5)
+ON CONFLICT(guild_id, user_id) DO UPDATE SET
+    username = excluded.username,
+    global_name = excluded.global_name,
+    nick = excluded.nick
+`
+
+type AddGuildMemberParams struct {
+	GuildID    shared.ID
+	UserID     shared.ID
+	Username   string
+	GlobalName string
+	Nick       string
+}
+
+func (q *Queries) AddGuildMember(ctx context.Context, arg AddGuildMemberParams) error {
+	_, err := q.exec(ctx, q.addGuildMemberStmt, addGuildMember,
+		arg.GuildID,
+		arg.UserID,
+		arg.Username,
+		arg.GlobalName,
+		arg.Nick,
+	)
+	return err
+}
+
+const addGuildMembers = `-- name: AddGuildMembers :exec
+INSERT INTO guild_members (guild_id, user_id, username, global_name, nick)
+SELECT ?1,
+       json_extract(value, '$.id'),
+       json_extract(value, '$.username'),
+       json_extract(value, '$.global_name'),
+       json_extract(value, '$.nick')
+FROM json_each(CAST(?2 AS TEXT))
+WHERE true
+ON CONFLICT(guild_id, user_id) DO UPDATE SET
+    username = excluded.username,
+    global_name = excluded.global_name,
+    nick = excluded.nick
+`
+
+type AddGuildMembersParams struct {
+	GuildID shared.ID
+	Members string
+}
+
+func (q *Queries) AddGuildMembers(ctx context.Context, arg AddGuildMembersParams) error {
+	_, err := q.exec(ctx, q.addGuildMembersStmt, addGuildMembers, arg.GuildID, arg.Members)
+	return err
+}
+
+const countCooldowns = `-- name: CountCooldowns :one
+SELECT COUNT(*)
+FROM command_cooldowns
+WHERE expires_at > ?1
+`
+
+func (q *Queries) CountCooldowns(ctx context.Context, now int64) (int64, error) {
+	row := q.queryRow(ctx, q.countCooldownsStmt, countCooldowns, now)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countGuilds = `-- name: CountGuilds :one
+SELECT COUNT(DISTINCT guild_id)
+FROM guild_members
+`
+
+func (q *Queries) CountGuilds(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.countGuildsStmt, countGuilds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*)
+FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.countUsersStmt, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteExpiredCooldowns = `-- name: DeleteExpiredCooldowns :exec
+DELETE FROM command_cooldowns
+WHERE expires_at <= ?1
+`
+
+func (q *Queries) DeleteExpiredCooldowns(ctx context.Context, now int64) error {
+	_, err := q.exec(ctx, q.deleteExpiredCooldownsStmt, deleteExpiredCooldowns, now)
+	return err
+}
+
+const deleteExpiredSlots = `-- name: DeleteExpiredSlots :exec
+DELETE FROM command_slots
+WHERE expires_at <= ?1
+`
+
+func (q *Queries) DeleteExpiredSlots(ctx context.Context, now int64) error {
+	_, err := q.exec(ctx, q.deleteExpiredSlotsStmt, deleteExpiredSlots, now)
+	return err
+}
+
+const deleteGuildDisabledCommands = `-- name: DeleteGuildDisabledCommands :exec
+DELETE FROM guild_disabled_commands
+WHERE guild_id = ?1
+`
+
+func (q *Queries) DeleteGuildDisabledCommands(ctx context.Context, guildID shared.ID) error {
+	_, err := q.exec(ctx, q.deleteGuildDisabledCommandsStmt, deleteGuildDisabledCommands, guildID)
+	return err
+}
+
+const deleteGuildMembers = `-- name: DeleteGuildMembers :exec
+DELETE FROM guild_members
+WHERE guild_id = ?1
+`
+
+func (q *Queries) DeleteGuildMembers(ctx context.Context, guildID shared.ID) error {
+	_, err := q.exec(ctx, q.deleteGuildMembersStmt, deleteGuildMembers, guildID)
+	return err
+}
+
+const deleteGuildPrefix = `-- name: DeleteGuildPrefix :exec
+DELETE FROM guild_prefixes
+WHERE guild_id = ?1
+`
+
+func (q *Queries) DeleteGuildPrefix(ctx context.Context, guildID shared.ID) error {
+	_, err := q.exec(ctx, q.deleteGuildPrefixStmt, deleteGuildPrefix, guildID)
+	return err
+}
+
+const deletePlaycount = `-- name: DeletePlaycount :exec
+DELETE FROM lastfm_playcounts
+WHERE lastfm_username = ?1
+`
+
+func (q *Queries) DeletePlaycount(ctx context.Context, lastfmUsername string) error {
+	_, err := q.exec(ctx, q.deletePlaycountStmt, deletePlaycount, lastfmUsername)
+	return err
+}
+
+const deletePresenceOptIn = `-- name: DeletePresenceOptIn :exec
+DELETE FROM presence_opt_ins
+WHERE user_id = ?1
+`
+
+func (q *Queries) DeletePresenceOptIn(ctx context.Context, userID shared.ID) error {
+	_, err := q.exec(ctx, q.deletePresenceOptInStmt, deletePresenceOptIn, userID)
+	return err
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM users
 WHERE user_id = ?1
@@ -21,6 +215,67 @@ func (q *Queries) DeleteUser(ctx context.Context, userID shared.ID) error {
 	return err
 }
 
+const deleteUserGuildMembers = `-- name: DeleteUserGuildMembers :exec
+DELETE FROM guild_members
+WHERE user_id = ?1
+`
+
+func (q *Queries) DeleteUserGuildMembers(ctx context.Context, userID shared.ID) error {
+	_, err := q.exec(ctx, q.deleteUserGuildMembersStmt, deleteUserGuildMembers, userID)
+	return err
+}
+
+const disableCommand = `-- name: DisableCommand :exec
+INSERT INTO guild_disabled_commands (guild_id, command)
+VALUES (?1, ?2)
+ON CONFLICT(guild_id, command) DO NOTHING
+`
+
+type DisableCommandParams struct {
+	GuildID shared.ID
+	Command string
+}
+
+func (q *Queries) DisableCommand(ctx context.Context, arg DisableCommandParams) error {
+	_, err := q.exec(ctx, q.disableCommandStmt, disableCommand, arg.GuildID, arg.Command)
+	return err
+}
+
+const enableCommand = `-- name: EnableCommand :exec
+DELETE FROM guild_disabled_commands
+WHERE guild_id = ?1 AND command = ?2
+`
+
+type EnableCommandParams struct {
+	GuildID shared.ID
+	Command string
+}
+
+func (q *Queries) EnableCommand(ctx context.Context, arg EnableCommandParams) error {
+	_, err := q.exec(ctx, q.enableCommandStmt, enableCommand, arg.GuildID, arg.Command)
+	return err
+}
+
+const findGuildMember = `-- name: FindGuildMember :one
+SELECT user_id
+FROM guild_members
+WHERE guild_id = ?1
+  AND ?2 IN (username, global_name, nick)
+LIMIT 1
+`
+
+type FindGuildMemberParams struct {
+	GuildID shared.ID
+	Name    string
+}
+
+func (q *Queries) FindGuildMember(ctx context.Context, arg FindGuildMemberParams) (shared.ID, error) {
+	row := q.queryRow(ctx, q.findGuildMemberStmt, findGuildMember, arg.GuildID, arg.Name)
+	var user_id shared.ID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
 const getAllUsers = `-- name: GetAllUsers :many
 SELECT user_id, lastfm_username, created_at
 FROM users
@@ -49,6 +304,19 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const getCooldown = `-- name: GetCooldown :one
+SELECT expires_at
+FROM command_cooldowns
+WHERE key = ?1
+`
+
+func (q *Queries) GetCooldown(ctx context.Context, key string) (int64, error) {
+	row := q.queryRow(ctx, q.getCooldownStmt, getCooldown, key)
+	var expires_at int64
+	err := row.Scan(&expires_at)
+	return expires_at, err
+}
+
 const getUserByID = `-- name: GetUserByID :one
 SELECT user_id, lastfm_username, created_at
 FROM users
@@ -75,6 +343,278 @@ func (q *Queries) GetUserByLastFM(ctx context.Context, lastfmUsername string) (U
 	return i, err
 }
 
+const isCommandDisabled = `-- name: IsCommandDisabled :one
+SELECT COUNT(*)
+FROM guild_disabled_commands
+WHERE guild_id = ?1 AND command = ?2
+`
+
+type IsCommandDisabledParams struct {
+	GuildID shared.ID
+	Command string
+}
+
+func (q *Queries) IsCommandDisabled(ctx context.Context, arg IsCommandDisabledParams) (int64, error) {
+	row := q.queryRow(ctx, q.isCommandDisabledStmt, isCommandDisabled, arg.GuildID, arg.Command)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const listDisabledCommands = `-- name: ListDisabledCommands :many
+SELECT command
+FROM guild_disabled_commands
+WHERE guild_id = ?1
+ORDER BY command
+`
+
+func (q *Queries) ListDisabledCommands(ctx context.Context, guildID shared.ID) ([]string, error) {
+	rows, err := q.query(ctx, q.listDisabledCommandsStmt, listDisabledCommands, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var command string
+		if err := rows.Scan(&command); err != nil {
+			return nil, err
+		}
+		items = append(items, command)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGuildPlaycounts = `-- name: ListGuildPlaycounts :many
+SELECT u.user_id, u.lastfm_username, p.playcount, p.updated_at
+FROM guild_members m
+JOIN users u ON u.user_id = m.user_id
+LEFT JOIN lastfm_playcounts p ON p.lastfm_username = u.lastfm_username
+WHERE m.guild_id = ?1
+ORDER BY p.updated_at IS NOT NULL, p.updated_at
+`
+
+type ListGuildPlaycountsRow struct {
+	UserID         shared.ID
+	LastfmUsername string
+	Playcount      sql.NullInt64
+	UpdatedAt      sql.NullTime
+}
+
+func (q *Queries) ListGuildPlaycounts(ctx context.Context, guildID shared.ID) ([]ListGuildPlaycountsRow, error) {
+	rows, err := q.query(ctx, q.listGuildPlaycountsStmt, listGuildPlaycounts, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGuildPlaycountsRow
+	for rows.Next() {
+		var i ListGuildPlaycountsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.LastfmUsername,
+			&i.Playcount,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGuildPrefixes = `-- name: ListGuildPrefixes :many
+SELECT guild_id, prefix
+FROM guild_prefixes
+`
+
+type ListGuildPrefixesRow struct {
+	GuildID shared.ID
+	Prefix  string
+}
+
+func (q *Queries) ListGuildPrefixes(ctx context.Context) ([]ListGuildPrefixesRow, error) {
+	rows, err := q.query(ctx, q.listGuildPrefixesStmt, listGuildPrefixes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGuildPrefixesRow
+	for rows.Next() {
+		var i ListGuildPrefixesRow
+		if err := rows.Scan(&i.GuildID, &i.Prefix); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPresenceUsers = `-- name: ListPresenceUsers :many
+SELECT u.lastfm_username
+FROM presence_opt_ins p
+JOIN users u ON u.user_id = p.user_id
+ORDER BY RANDOM()
+LIMIT ?1
+`
+
+func (q *Queries) ListPresenceUsers(ctx context.Context, limit int64) ([]string, error) {
+	rows, err := q.query(ctx, q.listPresenceUsersStmt, listPresenceUsers, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var lastfm_username string
+		if err := rows.Scan(&lastfm_username); err != nil {
+			return nil, err
+		}
+		items = append(items, lastfm_username)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pruneGuildMembers = `-- name: PruneGuildMembers :exec
+DELETE FROM guild_members
+WHERE guild_id = ?1
+  AND user_id NOT IN (SELECT value FROM json_each(CAST(?2 AS TEXT)))
+`
+
+type PruneGuildMembersParams struct {
+	GuildID shared.ID
+	UserIds string
+}
+
+func (q *Queries) PruneGuildMembers(ctx context.Context, arg PruneGuildMembersParams) error {
+	_, err := q.exec(ctx, q.pruneGuildMembersStmt, pruneGuildMembers, arg.GuildID, arg.UserIds)
+	return err
+}
+
+const releaseSlot = `-- name: ReleaseSlot :exec
+DELETE FROM command_slots
+WHERE path = ?1 AND holder = ?2
+`
+
+type ReleaseSlotParams struct {
+	Path   string
+	Holder string
+}
+
+func (q *Queries) ReleaseSlot(ctx context.Context, arg ReleaseSlotParams) error {
+	_, err := q.exec(ctx, q.releaseSlotStmt, releaseSlot, arg.Path, arg.Holder)
+	return err
+}
+
+const removeGuildMember = `-- name: RemoveGuildMember :exec
+DELETE FROM guild_members
+WHERE guild_id = ?1 AND user_id = ?2
+`
+
+type RemoveGuildMemberParams struct {
+	GuildID shared.ID
+	UserID  shared.ID
+}
+
+func (q *Queries) RemoveGuildMember(ctx context.Context, arg RemoveGuildMemberParams) error {
+	_, err := q.exec(ctx, q.removeGuildMemberStmt, removeGuildMember, arg.GuildID, arg.UserID)
+	return err
+}
+
+const setGuildPrefix = `-- name: SetGuildPrefix :exec
+INSERT INTO guild_prefixes (guild_id, prefix)
+VALUES (?1, ?2)
+ON CONFLICT(guild_id) DO UPDATE SET
+    prefix = excluded.prefix,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+type SetGuildPrefixParams struct {
+	GuildID shared.ID
+	Prefix  string
+}
+
+func (q *Queries) SetGuildPrefix(ctx context.Context, arg SetGuildPrefixParams) error {
+	_, err := q.exec(ctx, q.setGuildPrefixStmt, setGuildPrefix, arg.GuildID, arg.Prefix)
+	return err
+}
+
+const setPlaycount = `-- name: SetPlaycount :exec
+INSERT INTO lastfm_playcounts (lastfm_username, playcount, updated_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT(lastfm_username) DO UPDATE SET
+    playcount = excluded.playcount,
+    updated_at = excluded.updated_at
+`
+
+type SetPlaycountParams struct {
+	LastfmUsername string
+	Playcount      int64
+	UpdatedAt      time.Time
+}
+
+func (q *Queries) SetPlaycount(ctx context.Context, arg SetPlaycountParams) error {
+	_, err := q.exec(ctx, q.setPlaycountStmt, setPlaycount, arg.LastfmUsername, arg.Playcount, arg.UpdatedAt)
+	return err
+}
+
+const setPresenceOptIn = `-- name: SetPresenceOptIn :exec
+INSERT INTO presence_opt_ins (user_id)
+VALUES (?1)
+ON CONFLICT(user_id) DO NOTHING
+`
+
+func (q *Queries) SetPresenceOptIn(ctx context.Context, userID shared.ID) error {
+	_, err := q.exec(ctx, q.setPresenceOptInStmt, setPresenceOptIn, userID)
+	return err
+}
+
+const takeCooldown = `-- name: TakeCooldown :execrows
+INSERT INTO command_cooldowns (key, expires_at)
+VALUES (?1, ?2)
+ON CONFLICT(key) DO UPDATE SET
+    expires_at = excluded.expires_at
+WHERE command_cooldowns.expires_at <= ?3
+`
+
+type TakeCooldownParams struct {
+	Key       string
+	ExpiresAt int64
+	Now       int64
+}
+
+func (q *Queries) TakeCooldown(ctx context.Context, arg TakeCooldownParams) (int64, error) {
+	result, err := q.exec(ctx, q.takeCooldownStmt, takeCooldown, arg.Key, arg.ExpiresAt, arg.Now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const upsertUser = `-- name: UpsertUser :exec
 INSERT INTO users (user_id, lastfm_username)
 VALUES (?1, ?2)
@@ -0,0 +1,171 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+
+	"first.fm/internal/persistence/shared"
+)
+
+func startTest(t *testing.T) *Queries {
+	t.Helper()
+	queries, db, err := Start(context.Background(), "file:"+t.Name()+"?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		queries.Close()
+		db.Close()
+	})
+	return queries
+}
+
+func indexedMembers(t *testing.T, q *Queries, guildID shared.ID) []shared.ID {
+	t.Helper()
+	rows, err := q.db.QueryContext(context.Background(), "SELECT user_id FROM guild_members WHERE guild_id = ? ORDER BY user_id", guildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var ids []shared.ID
+	for rows.Next() {
+		var id shared.ID
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestAddAndPruneGuildMembers(t *testing.T) {
+	ctx := context.Background()
+	q := startTest(t)
+
+	err := q.AddGuildMembers(ctx, AddGuildMembersParams{
+		GuildID: 1,
+		Members: `[{"id":"10","username":"alice","global_name":"","nick":"al"},{"id":"11","username":"bob","global_name":"bobby","nick":""},{"id":"12","username":"carol","global_name":"","nick":""}]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// members of other guilds are left alone by pruning
+	if err := q.AddGuildMember(ctx, AddGuildMemberParams{GuildID: 2, UserID: 10, Username: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// indexing again updates names instead of failing on the primary key
+	err = q.AddGuildMembers(ctx, AddGuildMembersParams{
+		GuildID: 1,
+		Members: `[{"id":"11","username":"bob","global_name":"robert","nick":""}]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexedMembers(t, q, 1), []shared.ID{10, 11, 12}; !slices.Equal(got, want) {
+		t.Fatalf("indexed %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name string
+		want shared.ID
+	}{
+		{"alice", 10},
+		{"al", 10},
+		{"robert", 11},
+		{"carol", 12},
+	}
+	for _, tt := range tests {
+		id, err := q.FindGuildMember(ctx, FindGuildMemberParams{GuildID: 1, Name: tt.name})
+		if err != nil || id != tt.want {
+			t.Errorf("FindGuildMember(%q) = %d, %v, want %d", tt.name, id, err, tt.want)
+		}
+	}
+	if _, err := q.FindGuildMember(ctx, FindGuildMemberParams{GuildID: 1, Name: "bobby"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("FindGuildMember found the replaced name, err = %v", err)
+	}
+
+	if err := q.PruneGuildMembers(ctx, PruneGuildMembersParams{GuildID: 1, UserIds: `["10","12"]`}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := indexedMembers(t, q, 1), []shared.ID{10, 12}; !slices.Equal(got, want) {
+		t.Errorf("after pruning indexed %v, want %v", got, want)
+	}
+	if got, want := indexedMembers(t, q, 2), []shared.ID{10}; !slices.Equal(got, want) {
+		t.Errorf("pruning touched another guild: %v, want %v", got, want)
+	}
+}
+
+func TestTakeCooldown(t *testing.T) {
+	ctx := context.Background()
+	q := startTest(t)
+
+	take := func(now int64) int64 {
+		t.Helper()
+		taken, err := q.TakeCooldown(ctx, TakeCooldownParams{Key: "/fm:user:1", ExpiresAt: now + 3000, Now: now})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return taken
+	}
+
+	if take(1000) != 1 {
+		t.Fatal("first use was throttled")
+	}
+	if take(2000) != 0 {
+		t.Fatal("use within the cooldown wasn't throttled")
+	}
+	if until, err := q.GetCooldown(ctx, "/fm:user:1"); err != nil || until != 4000 {
+		t.Errorf("cooldown ends at %d, %v, want 4000 from the first use", until, err)
+	}
+	if take(4000) != 1 {
+		t.Fatal("use after the cooldown was throttled")
+	}
+	if n, err := q.CountCooldowns(ctx, 5000); err != nil || n != 1 {
+		t.Errorf("CountCooldowns = %d, %v, want 1", n, err)
+	}
+
+	if err := q.DeleteExpiredCooldowns(ctx, 7000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.GetCooldown(ctx, "/fm:user:1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expired cooldown wasn't deleted, err = %v", err)
+	}
+}
+
+func TestAcquireSlot(t *testing.T) {
+	ctx := context.Background()
+	q := startTest(t)
+
+	acquire := func(holder string, now int64) bool {
+		t.Helper()
+		n, err := q.AcquireSlot(ctx, AcquireSlotParams{Path: "/leaderboard", Holder: holder, ExpiresAt: now + 1000, Now: now, Slots: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n == 1
+	}
+
+	if !acquire("a", 0) || !acquire("b", 0) {
+		t.Fatal("free slots weren't acquired")
+	}
+	if acquire("c", 0) {
+		t.Fatal("acquired more slots than allowed")
+	}
+
+	if err := q.ReleaseSlot(ctx, ReleaseSlotParams{Path: "/leaderboard", Holder: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if !acquire("c", 0) {
+		t.Fatal("released slot wasn't acquired")
+	}
+
+	// slots of a process that died expire
+	if !acquire("d", 1000) {
+		t.Fatal("expired slots still count")
+	}
+}
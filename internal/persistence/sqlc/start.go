@@ -3,16 +3,13 @@ package sqlc
 import (
 	"context"
 	"database/sql"
-	"embed"
 	"fmt"
 	"time"
 
+	schema "first.fm/internal/persistence/sql"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-var schemaLocation = "../sql/schema.sql"
-var schema embed.FS
-
 func Start(ctx context.Context, path string) (*Queries, *sql.DB, error) {
 	sqlDB, err := sql.Open("sqlite3", path)
 	if err != nil {
@@ -22,9 +19,7 @@ func Start(ctx context.Context, path string) (*Queries, *sql.DB, error) {
 	sqlDB.SetMaxOpenConns(1)
 	sqlDB.SetConnMaxLifetime(time.Minute)
 
-	schema, _ := schema.ReadFile(schemaLocation)
-
-	if _, err := sqlDB.ExecContext(ctx, string(schema)); err != nil {
+	if _, err := sqlDB.ExecContext(ctx, schema.Schema); err != nil {
 		sqlDB.Close()
 		return nil, nil, fmt.Errorf("failed to create schema: %w", err)
 	}
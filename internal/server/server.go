@@ -0,0 +1,114 @@
+// Package server serves the health, readiness and metrics endpoints used by
+// operators and orchestrators.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"first.fm/internal/logger"
+	"first.fm/internal/metrics"
+)
+
+// checkTimeout bounds how long a readiness check may take.
+const checkTimeout = 2 * time.Second
+
+// Check reports why a dependency isn't ready, or nil when it is.
+type Check func(ctx context.Context) error
+
+// Server serves:
+//
+//   - /healthz: 200 while the process is running.
+//   - /readyz: 200 when every check passes, 503 listing the failures otherwise.
+//   - /metrics: metrics.Default in the Prometheus text format.
+type Server struct {
+	http   *http.Server
+	checks map[string]Check
+}
+
+// New returns a server listening on addr once Run is called. checks are run
+// by /readyz, by name.
+func New(addr string, checks map[string]Check) *Server {
+	s := &Server{checks: checks}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.healthz)
+	mux.HandleFunc("GET /readyz", s.readyz)
+	mux.HandleFunc("GET /metrics", s.metrics)
+
+	s.http = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Run serves until ctx is done, then shuts the server down.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+	logger.Infow("serving http", logger.F{"addr": ln.Addr().String()})
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.http.Shutdown(shutdownCtx)
+	}()
+
+	if err := s.http.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) healthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	names := make([]string, 0, len(s.checks))
+	for name := range s.checks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var (
+		b     strings.Builder
+		ready = true
+	)
+	for _, name := range names {
+		if err := s.checks[name](ctx); err != nil {
+			ready = false
+			fmt.Fprintf(&b, "%s: %v\n", name, err)
+			continue
+		}
+		fmt.Fprintf(&b, "%s: ok\n", name)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	fmt.Fprint(w, b.String())
+}
+
+func (s *Server) metrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := metrics.Default.Write(w); err != nil {
+		logger.Warnw("failed to write metrics", logger.F{"err": err.Error()})
+	}
+}